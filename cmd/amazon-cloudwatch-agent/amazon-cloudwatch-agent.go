@@ -544,6 +544,11 @@ func main() {
 				processorFilters,
 			)
 			return
+		case "checkpoints":
+			if err := internal.RunCheckpoints(args[1:], os.Stdin, os.Stdout); err != nil {
+				log.Fatalf("E! %v", err)
+			}
+			return
 		}
 	}
 
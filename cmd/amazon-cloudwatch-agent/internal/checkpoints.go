@@ -0,0 +1,164 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/checkpoint"
+	logsutil "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/util"
+)
+
+const checkpointsUsage = `Usage: amazon-cloudwatch-agent checkpoints <command> [flags]
+
+Manage the offsets of the tailed log files, the agent must be stopped.
+
+Commands:
+  list                 print the checkpoints
+  export               write the checkpoints as JSON
+  import               read the checkpoints from JSON, replacing the ones of the same files
+  reset [file ...]     remove the checkpoints of the files, so they are read again
+`
+
+// RunCheckpoints runs the checkpoints subcommand with the arguments following it.
+func RunCheckpoints(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(checkpointsUsage)
+	}
+	command := args[0]
+	fs := flag.NewFlagSet("checkpoints "+command, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	stateFolder := fs.String("state-folder", logsutil.GetFileStateFolder(), "folder of the checkpoint store")
+	var path *string
+	var all *bool
+	switch command {
+	case "export":
+		path = fs.String("output", "", "file the checkpoints are written to, defaults to stdout")
+	case "import":
+		path = fs.String("input", "", "file the checkpoints are read from, defaults to stdin")
+	case "reset":
+		all = fs.Bool("all", false, "remove all the checkpoints")
+	case "list":
+	default:
+		return fmt.Errorf("unknown checkpoints command %q\n%s", command, checkpointsUsage)
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if command == "reset" && !*all && fs.NArg() == 0 {
+		return errors.New("specify the files to reset or -all")
+	}
+
+	s, err := checkpoint.Open(filepath.Join(*stateFolder, checkpoint.FileName))
+	if errors.Is(err, checkpoint.ErrStoreLocked) {
+		return fmt.Errorf("%w, stop the agent first", err)
+	} else if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	switch command {
+	case "list":
+		return listCheckpoints(s, stdout)
+	case "export":
+		return exportCheckpoints(s, *path, stdout)
+	case "import":
+		return importCheckpoints(s, *path, stdin, stdout)
+	default:
+		return resetCheckpoints(s, fs.Args(), stdout)
+	}
+}
+
+func listCheckpoints(s *checkpoint.Store, stdout io.Writer) error {
+	checkpoints, err := s.List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tOFFSET\tUPDATED\tKEY")
+	for _, c := range checkpoints {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", c.File, c.Offset, c.UpdatedAt.Format(time.RFC3339), c.Key)
+	}
+	return w.Flush()
+}
+
+func exportCheckpoints(s *checkpoint.Store, path string, stdout io.Writer) error {
+	checkpoints, err := s.List()
+	if err != nil {
+		return err
+	}
+	if checkpoints == nil {
+		checkpoints = []checkpoint.Checkpoint{}
+	}
+	content, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+	if path == "" {
+		_, err = stdout.Write(content)
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+func importCheckpoints(s *checkpoint.Store, path string, stdin io.Reader, stdout io.Writer) error {
+	r := stdin
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	var checkpoints []checkpoint.Checkpoint
+	if err := json.NewDecoder(r).Decode(&checkpoints); err != nil {
+		return fmt.Errorf("invalid checkpoints: %w", err)
+	}
+	if err := s.Import(checkpoints); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Imported %d checkpoints\n", len(checkpoints))
+	return nil
+}
+
+// resetCheckpoints removes the checkpoints matching the files by path or by key, or all of them when no
+// file is given.
+func resetCheckpoints(s *checkpoint.Store, files []string, stdout io.Writer) error {
+	var keys []string
+	if len(files) > 0 {
+		checkpoints, err := s.List()
+		if err != nil {
+			return err
+		}
+		match := make(map[string]bool, len(files))
+		for _, file := range files {
+			match[file] = true
+		}
+		for _, c := range checkpoints {
+			if match[c.File] || match[c.Key] {
+				keys = append(keys, c.Key)
+			}
+		}
+		if len(keys) == 0 {
+			fmt.Fprintln(stdout, "No checkpoint matched")
+			return nil
+		}
+	}
+	removed, err := s.Reset(keys...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Removed %d checkpoints\n", removed)
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package internal
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCheckpoints(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	exported := filepath.Join(t.TempDir(), "checkpoints.json")
+
+	var out bytes.Buffer
+	input := `[{"key":"_var_log_app.log","file":"/var/log/app.log","offset":42},{"key":"_var_log_other.log","file":"/var/log/other.log","offset":7}]`
+	require.NoError(t, RunCheckpoints([]string{"import", "-state-folder", src}, strings.NewReader(input), &out))
+	assert.Equal(t, "Imported 2 checkpoints\n", out.String())
+
+	require.NoError(t, RunCheckpoints([]string{"export", "-state-folder", src, "-output", exported}, nil, &out))
+	out.Reset()
+	require.NoError(t, RunCheckpoints([]string{"import", "-state-folder", dst, "-input", exported}, nil, &out))
+	assert.Equal(t, "Imported 2 checkpoints\n", out.String())
+
+	out.Reset()
+	require.NoError(t, RunCheckpoints([]string{"list", "-state-folder", dst}, nil, &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"FILE", "OFFSET", "UPDATED", "KEY"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"/var/log/app.log", "42"}, strings.Fields(lines[1])[:2])
+	assert.Equal(t, []string{"/var/log/other.log", "7"}, strings.Fields(lines[2])[:2])
+
+	out.Reset()
+	require.NoError(t, RunCheckpoints([]string{"reset", "-state-folder", dst, "/var/log/app.log"}, nil, &out))
+	assert.Equal(t, "Removed 1 checkpoints\n", out.String())
+	out.Reset()
+	require.NoError(t, RunCheckpoints([]string{"export", "-state-folder", dst}, nil, &out))
+	assert.NotContains(t, out.String(), "/var/log/app.log")
+	assert.Contains(t, out.String(), "/var/log/other.log")
+
+	assert.Error(t, RunCheckpoints([]string{"reset", "-state-folder", dst}, nil, &out))
+	out.Reset()
+	require.NoError(t, RunCheckpoints([]string{"reset", "-state-folder", dst, "-all"}, nil, &out))
+	assert.Equal(t, "Removed 1 checkpoints\n", out.String())
+
+	assert.Error(t, RunCheckpoints(nil, nil, &out))
+	assert.Error(t, RunCheckpoints([]string{"unknown"}, nil, &out))
+}
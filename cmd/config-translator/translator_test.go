@@ -94,6 +94,10 @@ func TestLogFilesConfig(t *testing.T) {
 	expectedErrorMap2 := map[string]int{}
 	expectedErrorMap2["unique"] = 1
 	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidLogFilesWithDuplicateEntry.json", false, expectedErrorMap2)
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validLogFilesWithWatchMode.json", true, map[string]int{})
+	expectedErrorMap3 := map[string]int{}
+	expectedErrorMap3["enum"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidLogFilesWithWatchMode.json", false, expectedErrorMap3)
 }
 
 func TestLogWindowsEventConfig(t *testing.T) {
@@ -187,6 +191,50 @@ func TestMetricsDestinationsConfig(t *testing.T) {
 	expectedErrorMap["required"] = 1
 	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidMetricsDestinations.json", false, expectedErrorMap)
 }
+
+func TestMetricsStatisticsRulesConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validMetricsStatisticsRules.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["pattern"] = 2
+	expectedErrorMap["required"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidMetricsStatisticsRules.json", false, expectedErrorMap)
+}
+
+func TestMetricsCardinalityLimiterConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validMetricsCardinalityLimiter.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["number_gte"] = 1
+	expectedErrorMap["invalid_type"] = 1
+	expectedErrorMap["additional_property_not_allowed"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidMetricsCardinalityLimiter.json", false, expectedErrorMap)
+}
+
+func TestMetricsBackfillConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validMetricsBackfill.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["number_gt"] = 1
+	expectedErrorMap["invalid_type"] = 1
+	expectedErrorMap["additional_property_not_allowed"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidMetricsBackfill.json", false, expectedErrorMap)
+}
+
+func TestLogsPrometheusECSServiceDiscoveryLocalModeConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validLogsPrometheusECSLocalMode.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["invalid_type"] = 1
+	expectedErrorMap["string_gte"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidLogsPrometheusECSLocalMode.json", false, expectedErrorMap)
+}
+
+func TestLogsPrometheusRemoteWriteConfig(t *testing.T) {
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validLogsPrometheusRemoteWrite.json", true, map[string]int{})
+	expectedErrorMap := map[string]int{}
+	expectedErrorMap["required"] = 1
+	expectedErrorMap["invalid_type"] = 1
+	expectedErrorMap["additional_property_not_allowed"] = 1
+	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/invalidLogsPrometheusRemoteWrite.json", false, expectedErrorMap)
+}
+
 func TestContainerInsightsJmxConfig(t *testing.T) {
 	checkIfSchemaValidateAsExpected(t, "../../translator/config/sampleSchema/validContainerInsightsJmx.json", true, map[string]int{})
 }
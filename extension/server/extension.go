@@ -6,6 +6,7 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"net/http"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aws/amazon-cloudwatch-agent/extension/entitystore"
+	"github.com/aws/amazon-cloudwatch-agent/internal/prometheustargets"
 	tlsInternal "github.com/aws/amazon-cloudwatch-agent/internal/tls"
 )
 
@@ -25,6 +27,7 @@ type Server struct {
 	config         *Config
 	jsonMarshaller jsoniter.API
 	httpsServer    *http.Server
+	httpServer     *http.Server
 	ctx            context.Context
 	watcher        *tlsInternal.CertWatcher
 }
@@ -37,6 +40,7 @@ func (s *Server) setRouter(router *gin.Engine) {
 	router.UseRawPath = true
 	router.UnescapePathValues = false
 	router.GET("/kubernetes/pod-to-service-env-map", s.k8sPodToServiceMapHandler)
+	router.GET("/prometheus/targets", s.prometheusTargetsHandler)
 }
 
 func NewServer(logger *zap.Logger, config *Config) *Server {
@@ -48,6 +52,15 @@ func NewServer(logger *zap.Logger, config *Config) *Server {
 	}
 	gin.SetMode(gin.ReleaseMode)
 
+	// Without certificate, the endpoints are served over plain HTTP, on the local address configured
+	// by the translator
+	if config.TLSCertPath == "" {
+		httpRouter := gin.New()
+		s.setRouter(httpRouter)
+		s.httpServer = &http.Server{Addr: config.ListenAddress, Handler: httpRouter, ReadHeaderTimeout: 90 * time.Second}
+		return s
+	}
+
 	// Initialize a new cert watcher with cert/key pair
 	watcher, err := tlsInternal.NewCertWatcher(config.TLSCertPath, config.TLSKeyPath, config.TLSCAPath, logger)
 	if err != nil {
@@ -81,6 +94,15 @@ func NewServer(logger *zap.Logger, config *Config) *Server {
 }
 
 func (s *Server) Start(context.Context, component.Host) error {
+	if s.httpServer != nil {
+		s.logger.Debug("Starting HTTP server...")
+		go func() {
+			err := s.httpServer.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("failed to serve and listen", zap.Error(err))
+			}
+		}()
+	}
 	if s.httpsServer != nil {
 		s.logger.Debug("Starting HTTPS server...")
 		go func() {
@@ -95,6 +117,10 @@ func (s *Server) Start(context.Context, component.Host) error {
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.ctx.Done()
+	if s.httpServer != nil {
+		s.logger.Debug("Shutting down HTTP server...")
+		return s.httpServer.Shutdown(ctx)
+	}
 	if s.httpsServer != nil {
 		s.logger.Debug("Shutting down HTTPS server...")
 		return s.httpsServer.Shutdown(ctx)
@@ -146,6 +172,13 @@ var getPodServiceEnvironmentMapping = func() *ttlcache.Cache[string, entitystore
 	)
 }
 
+func (s *Server) prometheusTargetsHandler(c *gin.Context) {
+	s.jsonHandler(c.Writer, getPrometheusTargetsStatus())
+}
+
+// Added this for testing purpose
+var getPrometheusTargetsStatus = prometheustargets.Status
+
 func (s *Server) jsonHandler(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	err := s.jsonMarshaller.NewEncoder(w).Encode(data)
@@ -16,10 +16,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/aws/amazon-cloudwatch-agent/extension/entitystore"
+	"github.com/aws/amazon-cloudwatch-agent/internal/prometheustargets"
 )
 
 type mockEntityStore struct {
@@ -91,6 +93,7 @@ func TestNewServer(t *testing.T) {
 		want   *Server
 		config *Config
 		isTLS  bool
+		isHTTP bool
 	}{
 		{
 			name: "Should load valid HTTPS server",
@@ -106,14 +109,15 @@ func TestNewServer(t *testing.T) {
 			isTLS: true,
 		},
 		{
-			name: "should load server with empty HTTPS server as certs are empty",
+			name: "should load HTTP server as certs are empty",
 			want: &Server{
 				logger: logger,
 			},
 			config: &Config{
 				ListenAddress: ":8080",
 			},
-			isTLS: false,
+			isTLS:  false,
+			isHTTP: true,
 		},
 		{
 			name: "should load server with empty HTTPS server as CA cert is not valid",
@@ -176,6 +180,13 @@ func TestNewServer(t *testing.T) {
 			} else {
 				assert.Nil(t, server.httpsServer)
 			}
+			if tt.isHTTP {
+				assert.NotNil(t, server.httpServer)
+				assert.Equal(t, ":8080", server.httpServer.Addr)
+				assert.Nil(t, server.watcher)
+			} else {
+				assert.Nil(t, server.httpServer)
+			}
 		})
 	}
 
@@ -239,6 +250,47 @@ func TestK8sPodToServiceMapHandler(t *testing.T) {
 	}
 }
 
+func TestPrometheusTargetsHandler(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	server := NewServer(logger, &Config{ListenAddress: ":8080"})
+	defer func(f func() prometheustargets.TargetsStatus) { getPrometheusTargetsStatus = f }(getPrometheusTargetsStatus)
+	getPrometheusTargetsStatus = func() prometheustargets.TargetsStatus {
+		return prometheustargets.TargetsStatus{
+			ActiveTargets: []prometheustargets.TargetStatus{{
+				Job:                   "nginx",
+				ScrapeURL:             "http://10.0.0.1:9113/metrics",
+				Labels:                map[string]string{"job": "nginx"},
+				Health:                "down",
+				LastScrape:            time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				LastScrapeDuration:    0.5,
+				LastScrapeSampleCount: 0,
+				LastError:             "connection refused",
+			}},
+			DroppedTargetCounts: map[string]int{"nginx": 1},
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	server.prometheusTargetsHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"activeTargets": [{
+			"job": "nginx",
+			"scrapeUrl": "http://10.0.0.1:9113/metrics",
+			"labels": {"job": "nginx"},
+			"health": "down",
+			"lastScrape": "2024-01-02T03:04:05Z",
+			"lastScrapeDuration": 0.5,
+			"lastScrapeSampleCount": 0,
+			"lastError": "connection refused"
+		}],
+		"droppedTargetCounts": {"nginx": 1}
+	}`, w.Body.String())
+}
+
 func TestJSONHandler(t *testing.T) {
 
 	tests := []struct {
@@ -322,6 +374,26 @@ func TestServerStartAndShutdown(t *testing.T) {
 	}
 }
 
+func TestHTTPServerStartAndShutdown(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer func(f func() prometheustargets.TargetsStatus) { getPrometheusTargetsStatus = f }(getPrometheusTargetsStatus)
+	getPrometheusTargetsStatus = func() prometheustargets.TargetsStatus {
+		return prometheustargets.TargetsStatus{ActiveTargets: []prometheustargets.TargetStatus{}, DroppedTargetCounts: map[string]int{}}
+	}
+
+	server := NewServer(logger, &Config{ListenAddress: "127.0.0.1:8081"})
+	require.NoError(t, server.Start(context.Background(), nil))
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = http.Get("http://127.0.0.1:8081/prometheus/targets")
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, server.Shutdown(context.Background()))
+}
+
 func TestConvertTtlCacheToMap(t *testing.T) {
 	podToServiceMap := map[string]entitystore.ServiceEnvironment{
 		"pod1": {
@@ -0,0 +1,86 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package capture writes the requests the exporters would send to AWS to a local file instead,
+// so the effect of configuration changes can be reviewed without calling AWS.
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Record is a line of the capture file.
+type Record struct {
+	Operation string      `json:"operation"`
+	Request   interface{} `json:"request"`
+}
+
+// Writer appends the captured requests to a file as JSON lines. The exporters sharing a path
+// share the same writer, so their records are not interleaved.
+type Writer struct {
+	path string
+	refs int
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+var (
+	writersMu sync.Mutex
+	writers   = map[string]*Writer{}
+)
+
+// Open returns the writer of the file, creating the file if needed. Each Open must be paired with a Close.
+func Open(path string) (*Writer, error) {
+	path = filepath.Clean(path)
+	writersMu.Lock()
+	defer writersMu.Unlock()
+	if w, ok := writers[path]; ok {
+		w.refs++
+		return w, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("unable to create the directory of the capture file %s: %w", path, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the capture file %s: %w", path, err)
+	}
+	w := &Writer{path: path, refs: 1, file: file}
+	writers[path] = w
+	return w, nil
+}
+
+// Write appends the request of the operation to the file.
+func (w *Writer) Write(operation string, request interface{}) error {
+	data, err := json.Marshal(Record{Operation: operation, Request: request})
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return fmt.Errorf("capture file %s is closed", w.path)
+	}
+	_, err = w.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file once every user of the writer closed it.
+func (w *Writer) Close() error {
+	writersMu.Lock()
+	defer writersMu.Unlock()
+	if w.refs--; w.refs > 0 {
+		return nil
+	}
+	delete(writers, w.path)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
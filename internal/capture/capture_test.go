@@ -0,0 +1,45 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package capture
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir", "capture.jsonl")
+	w1, err := Open(path)
+	require.NoError(t, err)
+	w2, err := Open(path)
+	require.NoError(t, err)
+	assert.Same(t, w1, w2, "the writers of a path should be shared")
+
+	require.NoError(t, w1.Write("PutMetricData", map[string]string{"Namespace": "CWAgent"}))
+	require.NoError(t, w1.Close())
+	require.NoError(t, w2.Write("PutLogEvents", map[string]string{"LogGroupName": "group"}))
+	require.NoError(t, w2.Close())
+	assert.Error(t, w2.Write("PutLogEvents", nil), "the file should be closed once every user closed it")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"operation":"PutMetricData","request":{"Namespace":"CWAgent"}}`, lines[0])
+	assert.JSONEq(t, `{"operation":"PutLogEvents","request":{"LogGroupName":"group"}}`, lines[1])
+
+	// The records are appended to the existing file.
+	w3, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, w3.Write("PutMetricData", nil))
+	require.NoError(t, w3.Close())
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 3)
+}
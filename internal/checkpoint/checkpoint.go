@@ -0,0 +1,341 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package checkpoint persists the read offsets of the tailed files in a single transactional store,
+// so that a crash cannot leave a partially written offset behind.
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// FileName is the name of the store in the file state folder.
+	FileName = "checkpoints.db"
+
+	fileMode = 0644
+)
+
+var (
+	bucketName = []byte("checkpoints")
+
+	// FlushInterval is the interval the pending updates are committed at.
+	FlushInterval = time.Second
+	// OpenTimeout bounds the wait for the lock of a store held by another process.
+	OpenTimeout = 5 * time.Second
+
+	ErrStoreLocked = errors.New("checkpoint store is locked by another process")
+	ErrStoreClosed = errors.New("checkpoint store is closed")
+
+	storesMu sync.Mutex
+	stores   = make(map[string]*Store)
+)
+
+// Checkpoint is the offset already published of a tailed file.
+type Checkpoint struct {
+	// Key identifies the checkpoint, it is the escaped path of the file or the hash of an archive.
+	Key string `json:"key"`
+	// File is the last known path of the file, checkpoints of removed files are cleaned up.
+	File      string    `json:"file,omitempty"`
+	Offset    int64     `json:"offset"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Fingerprint is the hash of the first FingerprintSize bytes of the file, it recognizes the
+	// content of the file once rotated.
+	Fingerprint     string `json:"fingerprint,omitempty"`
+	FingerprintSize int64  `json:"fingerprint_size,omitempty"`
+}
+
+// Store is a bbolt backed checkpoint store. The updates are buffered and committed in a single
+// fsync'd transaction every FlushInterval, the store is shared by all the users of the same path
+// and is closed when the last of them closes it.
+type Store struct {
+	path string
+	db   *bbolt.DB
+	refs int
+
+	mu      sync.Mutex
+	pending map[string]*Checkpoint // nil values are pending deletions
+	// commitErr is the error of the last commit, it is returned to the updates until a commit succeeds.
+	commitErr error
+	closed    bool
+	// flushMu orders the commits, so an older batch cannot overwrite a newer one.
+	flushMu sync.Mutex
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Open opens the store at path, or returns the store already opened by this process.
+func Open(path string) (*Store, error) {
+	path = filepath.Clean(path)
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if s, ok := stores[path]; ok {
+		s.refs++
+		return s, nil
+	}
+
+	db, err := bbolt.Open(path, fileMode, &bbolt.Options{Timeout: OpenTimeout})
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrStoreLocked, path)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open checkpoint store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("unable to initialize checkpoint store %s: %w", path, err)
+	}
+
+	s := &Store{
+		path:    path,
+		db:      db,
+		refs:    1,
+		pending: make(map[string]*Checkpoint),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	stores[path] = s
+	return s, nil
+}
+
+func (s *Store) run() {
+	defer s.wg.Done()
+	t := time.NewTicker(FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := s.Flush(); err != nil {
+				log.Printf("E! [checkpoint] Unable to commit checkpoints to %s: %v", s.path, err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Retain adds a user to the store, which must close it once done.
+func (s *Store) Retain() *Store {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	s.refs++
+	return s
+}
+
+// Get returns the checkpoint of the key, including the updates not committed yet.
+func (s *Store) Get(key string) (Checkpoint, bool, error) {
+	s.mu.Lock()
+	if c, ok := s.pending[key]; ok {
+		s.mu.Unlock()
+		if c == nil {
+			return Checkpoint{}, false, nil
+		}
+		return *c, true, nil
+	}
+	s.mu.Unlock()
+
+	var c Checkpoint
+	var found bool
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(bucketName).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return decode(key, v, &c)
+	})
+	return c, found, err
+}
+
+// Set buffers the checkpoint until the next commit. It returns ErrStoreClosed once the store is
+// closed, and the error of the last commit while the commits fail.
+func (s *Store) Set(c Checkpoint) error {
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = time.Now()
+	}
+	return s.update(c.Key, &c)
+}
+
+// Delete buffers the removal of the checkpoint until the next commit, it returns the same errors as Set.
+func (s *Store) Delete(key string) error {
+	return s.update(key, nil)
+}
+
+func (s *Store) update(key string, c *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrStoreClosed
+	}
+	s.pending[key] = c
+	return s.commitErr
+}
+
+// Flush commits the pending updates in a single transaction.
+func (s *Store) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[string]*Checkpoint)
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		for key, c := range pending {
+			if c == nil {
+				if err := b.Delete([]byte(key)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := put(b, *c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commitErr = err
+	if err != nil {
+		// Keep the updates which have not been superseded meanwhile for the next commit.
+		for key, c := range pending {
+			if _, ok := s.pending[key]; !ok {
+				s.pending[key] = c
+			}
+		}
+	}
+	return err
+}
+
+// List returns all the checkpoints, sorted by key.
+func (s *Store) List() ([]Checkpoint, error) {
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+	var checkpoints []Checkpoint
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucketName).ForEach(func(k, v []byte) error {
+			var c Checkpoint
+			if err := decode(string(k), v, &c); err != nil {
+				return err
+			}
+			checkpoints = append(checkpoints, c)
+			return nil
+		})
+	})
+	return checkpoints, err
+}
+
+// Import writes the checkpoints in a single transaction, replacing the existing ones with the same key.
+func (s *Store) Import(checkpoints []Checkpoint) error {
+	if err := s.Flush(); err != nil {
+		return err
+	}
+	now := time.Now()
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		for _, c := range checkpoints {
+			if c.Key == "" {
+				return fmt.Errorf("checkpoint of file %q has no key", c.File)
+			}
+			if c.Offset < 0 {
+				return fmt.Errorf("checkpoint %s has a negative offset %d", c.Key, c.Offset)
+			}
+			if c.UpdatedAt.IsZero() {
+				c.UpdatedAt = now
+			}
+			if err := put(b, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Reset removes the checkpoints of the keys, or all of them when no key is given.
+// It returns the number of checkpoints removed.
+func (s *Store) Reset(keys ...string) (int, error) {
+	if err := s.Flush(); err != nil {
+		return 0, err
+	}
+	var removed int
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		if len(keys) == 0 {
+			removed = tx.Bucket(bucketName).Stats().KeyN
+			if err := tx.DeleteBucket(bucketName); err != nil {
+				return err
+			}
+			_, err := tx.CreateBucket(bucketName)
+			return err
+		}
+		b := tx.Bucket(bucketName)
+		for _, key := range keys {
+			if b.Get([]byte(key)) == nil {
+				continue
+			}
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// Close commits the pending updates and closes the store once all of its users have closed it.
+func (s *Store) Close() error {
+	storesMu.Lock()
+	s.refs--
+	if s.refs > 0 {
+		storesMu.Unlock()
+		return nil
+	}
+	delete(stores, s.path)
+	storesMu.Unlock()
+
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	close(s.done)
+	s.wg.Wait()
+	err := s.Flush()
+	if closeErr := s.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func put(b *bbolt.Bucket, c Checkpoint) error {
+	v, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(c.Key), v)
+}
+
+func decode(key string, v []byte, c *Checkpoint) error {
+	if err := json.Unmarshal(v, c); err != nil {
+		return fmt.Errorf("invalid checkpoint %s: %w", key, err)
+	}
+	c.Key = key
+	return nil
+}
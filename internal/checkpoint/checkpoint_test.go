@@ -0,0 +1,117 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetIsCommittedOnFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	s.Set(Checkpoint{Key: "_var_log_app.log", File: "/var/log/app.log", Offset: 10})
+	c, ok, err := s.Get("_var_log_app.log")
+	require.NoError(t, err)
+	assert.True(t, ok, "pending checkpoints should be visible before the commit")
+	assert.EqualValues(t, 10, c.Offset)
+
+	require.NoError(t, s.Flush())
+	s.Set(Checkpoint{Key: "_var_log_app.log", File: "/var/log/app.log", Offset: 20})
+	require.NoError(t, s.Close())
+
+	s, err = Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+	c, ok, err = s.Get("_var_log_app.log")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "/var/log/app.log", c.File)
+	assert.EqualValues(t, 20, c.Offset, "close should commit the pending checkpoints")
+	assert.False(t, c.UpdatedAt.IsZero())
+}
+
+func TestDelete(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), FileName))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Import([]Checkpoint{{Key: "a", Offset: 1}}))
+	s.Delete("a")
+	_, ok, err := s.Get("a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	require.NoError(t, s.Flush())
+	_, ok, err = s.Get("a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestUpdateAfterClose(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), FileName))
+	require.NoError(t, err)
+	assert.NoError(t, s.Set(Checkpoint{Key: "a", Offset: 1}))
+	require.NoError(t, s.Close())
+	assert.ErrorIs(t, s.Set(Checkpoint{Key: "a", Offset: 2}), ErrStoreClosed)
+	assert.ErrorIs(t, s.Delete("a"), ErrStoreClosed)
+}
+
+func TestOpenIsShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	s1, err := Open(path)
+	require.NoError(t, err)
+	s2, err := Open(path)
+	require.NoError(t, err)
+	assert.Same(t, s1, s2)
+
+	require.NoError(t, s1.Close())
+	s2.Set(Checkpoint{Key: "a", Offset: 1})
+	_, err = s2.List()
+	require.NoError(t, err, "the store should stay open until all of its users closed it")
+	require.NoError(t, s2.Close())
+}
+
+func TestListImportReset(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), FileName))
+	require.NoError(t, err)
+	defer s.Close()
+
+	updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, s.Import([]Checkpoint{
+		{Key: "b", File: "/b", Offset: 2, UpdatedAt: updated},
+		{Key: "a", File: "/a", Offset: 1, UpdatedAt: updated},
+	}))
+	s.Set(Checkpoint{Key: "c", File: "/c", Offset: 3, UpdatedAt: updated})
+
+	checkpoints, err := s.List()
+	require.NoError(t, err)
+	assert.Equal(t, []Checkpoint{
+		{Key: "a", File: "/a", Offset: 1, UpdatedAt: updated},
+		{Key: "b", File: "/b", Offset: 2, UpdatedAt: updated},
+		{Key: "c", File: "/c", Offset: 3, UpdatedAt: updated},
+	}, checkpoints)
+
+	assert.Error(t, s.Import([]Checkpoint{{Key: "d", Offset: -1}}))
+	assert.Error(t, s.Import([]Checkpoint{{File: "/e", Offset: 1}}))
+
+	removed, err := s.Reset("a", "unknown")
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	checkpoints, err = s.List()
+	require.NoError(t, err)
+	assert.Len(t, checkpoints, 2)
+
+	removed, err = s.Reset()
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	checkpoints, err = s.List()
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+}
@@ -109,4 +109,9 @@ type ServiceDiscoveryConfig struct {
 	ServiceNamesForTasks []*ServiceNameForTasksConfig `toml:"service_name_list_for_tasks"`
 	DockerLabel          *DockerLabelConfig           `toml:"docker_label"`
 	TaskDefinitions      []*TaskDefinitionConfig      `toml:"task_definition_list"`
+	// LocalMode discovers the tasks of the container instance the agent runs on from the ECS agent
+	// introspection endpoint and the Docker engine, instead of the tasks of the cluster from the ECS API.
+	LocalMode        bool   `toml:"sd_local_mode"`
+	ECSAgentEndpoint string `toml:"sd_ecs_agent_endpoint"`
+	DockerEndpoint   string `toml:"sd_docker_endpoint"`
 }
@@ -0,0 +1,296 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+const (
+	defaultECSAgentEndpoint = "http://localhost:51678"
+	defaultDockerEndpoint   = "unix:///var/run/docker.sock"
+	localRequestTimeout     = 5 * time.Second
+	maxLocalResponseLength  = 5 * 1024 * 1024 // 5MB
+
+	ecsAgentMetadataPath = "/v1/metadata"
+	ecsAgentTasksPath    = "/v1/tasks"
+	dockerInspectPath    = "/containers/%s/json"
+
+	// the labels the ECS agent adds to the containers, which are not part of the task definition
+	ecsAgentDockerLabelPrefix = "com.amazonaws.ecs."
+	taskStatusRunning         = "RUNNING"
+	localTaskLaunchType       = "EC2"
+	eniAttachmentType         = "ElasticNetworkInterface"
+	eniPrivateIPv4Detail      = "privateIPv4Address"
+)
+
+// The responses of the ECS agent introspection API, see
+// https://docs.aws.amazon.com/AmazonECS/latest/developerguide/ecs-agent-introspection.html
+type ecsAgentMetadata struct {
+	Cluster              string
+	ContainerInstanceArn string
+}
+
+type ecsAgentTasks struct {
+	Tasks []ecsAgentTask
+}
+
+type ecsAgentTask struct {
+	Arn         string
+	KnownStatus string
+	Family      string
+	Version     string
+	Containers  []ecsAgentContainer
+}
+
+type ecsAgentContainer struct {
+	DockerId string
+	Name     string
+	Ports    []ecsAgentPort
+	Networks []ecsAgentNetwork
+}
+
+type ecsAgentPort struct {
+	ContainerPort int64
+	HostPort      int64
+	Protocol      string
+}
+
+type ecsAgentNetwork struct {
+	NetworkMode   string
+	IPv4Addresses []string
+}
+
+// dockerContainer is the part of the response of the Docker engine container inspect API in use.
+type dockerContainer struct {
+	Config struct {
+		Labels map[string]string
+	}
+}
+
+// LocalTaskProcessor gets the running tasks of the container instance from the local ECS agent
+// introspection endpoint and the docker labels of their containers from the Docker engine, instead
+// of calling the ECS and EC2 APIs. The tasks are decorated like TaskProcessor, TaskDefinitionProcessor
+// and ContainerInstanceProcessor do, so the same discovery processors apply.
+type LocalTaskProcessor struct {
+	ecsAgentEndpoint string
+	dockerEndpoint   string
+	ecsAgentClient   *http.Client
+	dockerClient     *http.Client
+	// ec2MetaData returns the metadata of the instance, from the instance metadata service by default.
+	ec2MetaData func() (*EC2MetaData, error)
+	stats       *ProcessorStats
+
+	ec2Info *EC2MetaData
+}
+
+func NewLocalTaskProcessor(config *ServiceDiscoveryConfig, ec2MetaData func() (*EC2MetaData, error), s *ProcessorStats) *LocalTaskProcessor {
+	p := &LocalTaskProcessor{
+		ecsAgentEndpoint: strings.TrimSuffix(config.ECSAgentEndpoint, "/"),
+		dockerEndpoint:   strings.TrimSuffix(config.DockerEndpoint, "/"),
+		ecsAgentClient:   &http.Client{Timeout: localRequestTimeout},
+		dockerClient:     &http.Client{Timeout: localRequestTimeout},
+		ec2MetaData:      ec2MetaData,
+		stats:            s,
+	}
+	if p.ecsAgentEndpoint == "" {
+		p.ecsAgentEndpoint = defaultECSAgentEndpoint
+	}
+	if p.dockerEndpoint == "" {
+		p.dockerEndpoint = defaultDockerEndpoint
+	}
+	// The Docker engine listens on a unix socket by default
+	if socket, ok := strings.CutPrefix(p.dockerEndpoint, "unix://"); ok {
+		p.dockerEndpoint = "http://docker"
+		p.dockerClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+	}
+	return p
+}
+
+func (p *LocalTaskProcessor) Process(_ string, taskList []*DecoratedTask) ([]*DecoratedTask, error) {
+	var metadata ecsAgentMetadata
+	if err := getLocalJSON(p.ecsAgentClient, p.ecsAgentEndpoint+ecsAgentMetadataPath, &metadata); err != nil {
+		return taskList, newServiceDiscoveryError("Failed to get the container instance from the ECS agent", &err)
+	}
+	p.stats.AddStats(ECSAgentGetMetadata)
+	var tasks ecsAgentTasks
+	if err := getLocalJSON(p.ecsAgentClient, p.ecsAgentEndpoint+ecsAgentTasksPath, &tasks); err != nil {
+		return taskList, newServiceDiscoveryError("Failed to list the tasks from the ECS agent", &err)
+	}
+	p.stats.AddStats(ECSAgentListTasks)
+
+	// The instance does not change, its metadata is only retrieved until it succeeds once
+	if p.ec2Info == nil {
+		ec2Info, err := p.ec2MetaData()
+		if err != nil {
+			return taskList, newServiceDiscoveryError("Failed to get the EC2 instance metadata", &err)
+		}
+		ec2Info.ContainerInstanceId = getContainerInstanceIdFromArn(metadata.ContainerInstanceArn)
+		p.ec2Info = ec2Info
+	}
+
+	for _, t := range tasks.Tasks {
+		if t.KnownStatus != taskStatusRunning {
+			continue
+		}
+		task, err := p.decorateTask(t)
+		if err != nil {
+			return taskList, err
+		}
+		if task != nil {
+			taskList = append(taskList, task)
+		}
+	}
+	return taskList, nil
+}
+
+// decorateTask converts a task of the ECS agent to the task and task definition the ECS API would return.
+func (p *LocalTaskProcessor) decorateTask(t ecsAgentTask) (*DecoratedTask, error) {
+	revision, err := strconv.ParseInt(t.Version, 10, 64)
+	if err != nil {
+		log.Printf("E! Skip the task %v with invalid task definition version %q \n", t.Arn, t.Version)
+		return nil, nil
+	}
+	taskDefinitionArn, err := getTaskDefinitionArn(t.Arn, t.Family, revision)
+	if err != nil {
+		log.Printf("E! Skip the task with invalid ARN %v: %v \n", t.Arn, err)
+		return nil, nil
+	}
+	task := &ecs.Task{
+		TaskArn:           aws.String(t.Arn),
+		TaskDefinitionArn: aws.String(taskDefinitionArn),
+		LaunchType:        aws.String(localTaskLaunchType),
+	}
+	taskDefinition := &ecs.TaskDefinition{
+		TaskDefinitionArn: aws.String(taskDefinitionArn),
+		Family:            aws.String(t.Family),
+		Revision:          aws.Int64(revision),
+	}
+	for _, c := range t.Containers {
+		if c.DockerId == "" {
+			// the container of the task has not been created yet
+			log.Printf("W! Skip the container %v of the task %v without docker id \n", c.Name, t.Arn)
+			continue
+		}
+		var labels dockerContainer
+		err := getLocalJSON(p.dockerClient, p.dockerEndpoint+fmt.Sprintf(dockerInspectPath, c.DockerId), &labels)
+		var statusErr *localStatusError
+		if errors.As(err, &statusErr) {
+			// the engine is reachable, only this container cannot be inspected, e.g. it has been removed
+			p.stats.AddStats(DockerInspectContainer)
+			log.Printf("W! Skip the container %v of the task %v which failed to be inspected: %v \n", c.Name, t.Arn, err)
+			continue
+		}
+		if err != nil {
+			return nil, newServiceDiscoveryError("Failed to inspect the container "+c.DockerId, &err)
+		}
+		p.stats.AddStats(DockerInspectContainer)
+
+		containerDefinition := &ecs.ContainerDefinition{Name: aws.String(c.Name), DockerLabels: map[string]*string{}}
+		for k, v := range labels.Config.Labels {
+			if !strings.HasPrefix(k, ecsAgentDockerLabelPrefix) {
+				containerDefinition.DockerLabels[k] = aws.String(v)
+			}
+		}
+		container := &ecs.Container{Name: aws.String(c.Name)}
+		for _, port := range c.Ports {
+			container.NetworkBindings = append(container.NetworkBindings, &ecs.NetworkBinding{
+				ContainerPort: aws.Int64(port.ContainerPort),
+				HostPort:      aws.Int64(port.HostPort),
+				Protocol:      aws.String(port.Protocol),
+			})
+			containerDefinition.PortMappings = append(containerDefinition.PortMappings, &ecs.PortMapping{
+				ContainerPort: aws.Int64(port.ContainerPort),
+				HostPort:      aws.Int64(port.HostPort),
+				Protocol:      aws.String(port.Protocol),
+			})
+		}
+		for _, network := range c.Networks {
+			if taskDefinition.NetworkMode == nil && network.NetworkMode != "" {
+				taskDefinition.NetworkMode = aws.String(network.NetworkMode)
+			}
+			// the task ENI of the awsvpc network mode
+			if network.NetworkMode == ecs.NetworkModeAwsvpc && len(network.IPv4Addresses) > 0 && len(task.Attachments) == 0 {
+				task.Attachments = append(task.Attachments, &ecs.Attachment{
+					Type:    aws.String(eniAttachmentType),
+					Details: []*ecs.KeyValuePair{{Name: aws.String(eniPrivateIPv4Detail), Value: aws.String(network.IPv4Addresses[0])}},
+				})
+			}
+		}
+		task.Containers = append(task.Containers, container)
+		taskDefinition.ContainerDefinitions = append(taskDefinition.ContainerDefinitions, containerDefinition)
+	}
+	return &DecoratedTask{Task: task, TaskDefinition: taskDefinition, EC2Info: p.ec2Info}, nil
+}
+
+func (p *LocalTaskProcessor) ProcessorName() string {
+	return "LocalTaskProcessor"
+}
+
+// getTaskDefinitionArn returns the ARN of the task definition of a task, which the ECS agent does not return.
+func getTaskDefinitionArn(taskArn, family string, revision int64) (string, error) {
+	a, err := arn.Parse(taskArn)
+	if err != nil {
+		return "", err
+	}
+	a.Resource = fmt.Sprintf("task-definition/%s:%d", family, revision)
+	return a.String(), nil
+}
+
+// getContainerInstanceIdFromArn returns the id of both container instance ARN formats:
+// arn:aws:ecs:region:aws_account_id:container-instance/container-instance-id
+// arn:aws:ecs:region:aws_account_id:container-instance/cluster-name/container-instance-id
+func getContainerInstanceIdFromArn(containerInstanceArn string) string {
+	a, err := arn.Parse(containerInstanceArn)
+	if err != nil {
+		return ""
+	}
+	resource := strings.Split(a.Resource, "/")
+	return resource[len(resource)-1]
+}
+
+func getLocalJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &localStatusError{statusCode: resp.StatusCode, url: url}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxLocalResponseLength))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// localStatusError is returned when a local endpoint responds with an unexpected status code.
+type localStatusError struct {
+	statusCode int
+	url        string
+}
+
+func (e *localStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d from %s", e.statusCode, e.url)
+}
@@ -0,0 +1,187 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	testECSAgentMetadata = `{"Cluster":"ecs-cluster-a","ContainerInstanceArn":"arn:aws:ecs:us-west-2:123456789012:container-instance/ecs-cluster-a/0123456789abcdef","Version":"Amazon ECS Agent - v1.80.0"}`
+	testECSAgentTasks    = `{"Tasks":[
+		{"Arn":"arn:aws:ecs:us-west-2:123456789012:task/ecs-cluster-a/aaaa","KnownStatus":"RUNNING","Family":"nginx","Version":"3","Containers":[
+			{"DockerId":"nginx-id","Name":"nginx","Ports":[{"ContainerPort":9113,"Protocol":"tcp","HostPort":32768}],"Networks":[{"NetworkMode":"bridge","IPv4Addresses":["172.17.0.2"]}]},
+			{"DockerId":"sidecar-id","Name":"sidecar","Networks":[{"NetworkMode":"bridge","IPv4Addresses":["172.17.0.3"]}]}]},
+		{"Arn":"arn:aws:ecs:us-west-2:123456789012:task/ecs-cluster-a/bbbb","KnownStatus":"RUNNING","Family":"envoy","Version":"7","Containers":[
+			{"DockerId":"envoy-id","Name":"envoy","Ports":[{"ContainerPort":9901,"Protocol":"tcp","HostPort":9901}],"Networks":[{"NetworkMode":"awsvpc","IPv4Addresses":["10.0.1.5"]}]}]},
+		{"Arn":"arn:aws:ecs:us-west-2:123456789012:task/ecs-cluster-a/cccc","KnownStatus":"STOPPED","Family":"nginx","Version":"2","Containers":[
+			{"DockerId":"stopped-id","Name":"nginx"}]},
+		{"Arn":"arn:aws:ecs:us-west-2:123456789012:task/ecs-cluster-a/dddd","KnownStatus":"RUNNING","Family":"redis","Version":"1","Containers":[
+			{"DockerId":"","Name":"pending"},
+			{"DockerId":"removed-id","Name":"redis"}]}]}`
+)
+
+func newTestLocalEndpoints(t *testing.T) (*httptest.Server, *httptest.Server) {
+	ecsAgent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ecsAgentMetadataPath:
+			w.Write([]byte(testECSAgentMetadata))
+		case ecsAgentTasksPath:
+			w.Write([]byte(testECSAgentTasks))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ecsAgent.Close)
+	labels := map[string]string{
+		"/containers/nginx-id/json":   `{"Config":{"Labels":{"ECS_PROMETHEUS_EXPORTER_PORT":"9113","ECS_PROMETHEUS_JOB_NAME":"nginx-job","com.amazonaws.ecs.task-arn":"arn"}}}`,
+		"/containers/sidecar-id/json": `{"Config":{"Labels":{"com.amazonaws.ecs.container-name":"sidecar"}}}`,
+		"/containers/envoy-id/json":   `{"Config":{"Labels":{}}}`,
+	}
+	docker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := labels[r.URL.Path]; ok {
+			w.Write([]byte(body))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(docker.Close)
+	return ecsAgent, docker
+}
+
+func testEC2MetaData() (*EC2MetaData, error) {
+	return &EC2MetaData{ECInstanceId: "i-0123456789", PrivateIP: "10.0.0.10", InstanceType: "m5.large"}, nil
+}
+
+func Test_LocalTaskProcessor(t *testing.T) {
+	ecsAgent, docker := newTestLocalEndpoints(t)
+	var stats ProcessorStats
+	p := NewLocalTaskProcessor(&ServiceDiscoveryConfig{ECSAgentEndpoint: ecsAgent.URL, DockerEndpoint: docker.URL}, testEC2MetaData, &stats)
+	assert.Equal(t, "LocalTaskProcessor", p.ProcessorName())
+
+	tasks, err := p.Process("", nil)
+	require.NoError(t, err)
+	require.Len(t, tasks, 3, "only the running tasks should be discovered")
+	assert.Equal(t, 1, stats.GetStats(ECSAgentListTasks))
+	assert.Equal(t, 4, stats.GetStats(DockerInspectContainer))
+
+	nginx := tasks[0]
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:task-definition/nginx:3", *nginx.Task.TaskDefinitionArn)
+	assert.Equal(t, "bridge", *nginx.TaskDefinition.NetworkMode)
+	assert.Equal(t, int64(3), *nginx.TaskDefinition.Revision)
+	assert.Equal(t, &EC2MetaData{ContainerInstanceId: "0123456789abcdef", ECInstanceId: "i-0123456789", PrivateIP: "10.0.0.10", InstanceType: "m5.large"}, nginx.EC2Info)
+	require.Len(t, nginx.TaskDefinition.ContainerDefinitions, 2)
+	labels := nginx.TaskDefinition.ContainerDefinitions[0].DockerLabels
+	assert.Len(t, labels, 2, "the labels of the ECS agent should be dropped")
+	assert.Equal(t, "9113", *labels["ECS_PROMETHEUS_EXPORTER_PORT"])
+	assert.Equal(t, int64(32768), nginx.getPrometheusExporterPort(9113, nginx.TaskDefinition.ContainerDefinitions[0]))
+	assert.Equal(t, "10.0.0.10", nginx.getPrivateIp())
+
+	envoy := tasks[1]
+	assert.Equal(t, "awsvpc", *envoy.TaskDefinition.NetworkMode)
+	assert.Equal(t, "10.0.1.5", envoy.getPrivateIp())
+	assert.Equal(t, int64(9901), envoy.getPrometheusExporterPort(9901, envoy.TaskDefinition.ContainerDefinitions[0]))
+
+	// the containers which are not created yet or already removed are skipped
+	redis := tasks[2]
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:task-definition/redis:1", *redis.Task.TaskDefinitionArn)
+	assert.Empty(t, redis.Task.Containers)
+	assert.Empty(t, redis.TaskDefinition.ContainerDefinitions)
+}
+
+func Test_LocalTaskProcessor_Errors(t *testing.T) {
+	ecsAgent, docker := newTestLocalEndpoints(t)
+	p := NewLocalTaskProcessor(&ServiceDiscoveryConfig{ECSAgentEndpoint: ecsAgent.URL + "/missing", DockerEndpoint: docker.URL}, testEC2MetaData, &ProcessorStats{})
+	_, err := p.Process("", nil)
+	assert.ErrorContains(t, err, "Failed to get the container instance from the ECS agent")
+
+	p = NewLocalTaskProcessor(&ServiceDiscoveryConfig{ECSAgentEndpoint: ecsAgent.URL, DockerEndpoint: docker.URL}, func() (*EC2MetaData, error) {
+		return nil, errors.New("imds unavailable")
+	}, &ProcessorStats{})
+	_, err = p.Process("", nil)
+	assert.ErrorContains(t, err, "imds unavailable")
+
+	// the discovery only fails when the docker engine is unreachable
+	docker.Close()
+	p = NewLocalTaskProcessor(&ServiceDiscoveryConfig{ECSAgentEndpoint: ecsAgent.URL, DockerEndpoint: docker.URL}, testEC2MetaData, &ProcessorStats{})
+	_, err = p.Process("", nil)
+	assert.ErrorContains(t, err, "Failed to inspect the container")
+}
+
+func Test_ServiceDiscovery_LocalMode(t *testing.T) {
+	ecsAgent, docker := newTestLocalEndpoints(t)
+	resultFile := filepath.Join(t.TempDir(), "ecs_sd_targets.yaml")
+	config := &ServiceDiscoveryConfig{
+		Frequency:        "1m",
+		ResultFile:       resultFile,
+		LocalMode:        true,
+		ECSAgentEndpoint: ecsAgent.URL,
+		DockerEndpoint:   docker.URL,
+		DockerLabel: &DockerLabelConfig{
+			PortLabel:    "ECS_PROMETHEUS_EXPORTER_PORT",
+			JobNameLabel: "ECS_PROMETHEUS_JOB_NAME",
+		},
+		TaskDefinitions: []*TaskDefinitionConfig{{TaskDefArnPattern: ".*:task-definition/envoy:[0-9]+", MetricsPorts: "9901"}},
+	}
+	sd := &ServiceDiscovery{Config: config}
+	assert.True(t, sd.validateConfig(), "the cluster should not be required in local mode")
+	sd.initLocalProcessorPipeline()
+	sd.clusterProcessors[0] = NewLocalTaskProcessor(config, testEC2MetaData, &sd.stats)
+	sd.work()
+
+	data, err := os.ReadFile(resultFile)
+	require.NoError(t, err)
+	var targets []*PrometheusTarget
+	require.NoError(t, yaml.Unmarshal(data, &targets))
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Targets[0] < targets[j].Targets[0] })
+	assert.Equal(t, []*PrometheusTarget{
+		{
+			Targets: []string{"10.0.0.10:32768"},
+			Labels: map[string]string{
+				"ECS_PROMETHEUS_EXPORTER_PORT": "9113",
+				"ECS_PROMETHEUS_JOB_NAME":      "nginx-job",
+				"InstanceType":                 "m5.large",
+				"LaunchType":                   "EC2",
+				"TaskClusterName":              "ecs-cluster-a",
+				"TaskDefinitionFamily":         "nginx",
+				"TaskId":                       "aaaa",
+				"TaskRevision":                 "3",
+				"container_name":               "nginx",
+				"job":                          "nginx-job",
+			},
+		},
+		{
+			Targets: []string{"10.0.1.5:9901"},
+			Labels: map[string]string{
+				"InstanceType":         "m5.large",
+				"LaunchType":           "EC2",
+				"TaskClusterName":      "ecs-cluster-a",
+				"TaskDefinitionFamily": "envoy",
+				"TaskId":               "bbbb",
+				"TaskRevision":         "7",
+				"container_name":       "envoy",
+			},
+		},
+	}, targets)
+}
+
+func Test_StartECSServiceDiscovery_LocalModeWithoutDiscovery(t *testing.T) {
+	config := ServiceDiscoveryConfig{
+		Frequency:            "1m",
+		LocalMode:            true,
+		ServiceNamesForTasks: []*ServiceNameForTasksConfig{{ServiceNamePattern: ".*", MetricsPorts: "9113"}},
+	}
+	p := &ServiceDiscovery{Config: &config}
+	assert.False(t, p.validateConfig(), "service name based discovery is not supported in local mode")
+}
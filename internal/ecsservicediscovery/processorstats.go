@@ -21,6 +21,9 @@ const (
 	LRUCacheSizeContainerInstance    = "LRUCache_Size_ContainerInstance"
 	LRUCacheSizeTaskDefinition       = "LRUCache_Size_TaskDefinition"
 	ExporterDiscoveredTargetCount    = "Exporter_DiscoveredTargetCount"
+	ECSAgentGetMetadata              = "ECSAgent_GetMetadata"
+	ECSAgentListTasks                = "ECSAgent_ListTasks"
+	DockerInspectContainer           = "Docker_InspectContainer"
 )
 
 type ProcessorStats struct {
@@ -4,6 +4,7 @@
 package ecsservicediscovery
 
 import (
+	"context"
 	"log"
 	"sync"
 	"time"
@@ -14,6 +15,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/ecs"
 
 	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
+	"github.com/aws/amazon-cloudwatch-agent/internal/ec2metadataprovider"
+	"github.com/aws/amazon-cloudwatch-agent/internal/retryer"
 )
 
 type ServiceDiscovery struct {
@@ -28,6 +31,12 @@ type ServiceDiscovery struct {
 }
 
 func (sd *ServiceDiscovery) init() {
+	// The local mode does not call the AWS APIs
+	if sd.Config.LocalMode {
+		sd.initLocalProcessorPipeline()
+		return
+	}
+
 	credentialConfig := &configaws.CredentialConfig{
 		Region: sd.Config.TargetClusterRegion,
 	}
@@ -62,6 +71,30 @@ func (sd *ServiceDiscovery) initClusterProcessorPipeline() {
 	sd.clusterProcessors = append(sd.clusterProcessors, NewTargetsExportProcessor(sd.Config, &sd.stats))
 }
 
+func (sd *ServiceDiscovery) initLocalProcessorPipeline() {
+	sd.clusterProcessors = append(sd.clusterProcessors, NewLocalTaskProcessor(sd.Config, getEC2MetaData, &sd.stats))
+	sd.clusterProcessors = append(sd.clusterProcessors, NewDockerLabelDiscoveryProcessor(sd.Config.DockerLabel))
+	sd.clusterProcessors = append(sd.clusterProcessors, NewTaskDefinitionDiscoveryProcessor(sd.Config.TaskDefinitions))
+	sd.clusterProcessors = append(sd.clusterProcessors, NewTaskFilterProcessor())
+	sd.clusterProcessors = append(sd.clusterProcessors, NewTargetsExportProcessor(sd.Config, &sd.stats))
+}
+
+// getEC2MetaData returns the metadata of the instance from the instance metadata service, the VPC and
+// subnet are only known from the EC2 API.
+func getEC2MetaData() (*EC2MetaData, error) {
+	mdCredentialConfig := &configaws.CredentialConfig{}
+	provider := ec2metadataprovider.NewMetadataProvider(mdCredentialConfig.Credentials(), retryer.GetDefaultRetryNumber())
+	doc, err := provider.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &EC2MetaData{
+		ECInstanceId: doc.InstanceID,
+		PrivateIP:    doc.PrivateIP,
+		InstanceType: doc.InstanceType,
+	}, nil
+}
+
 func StartECSServiceDiscovery(sd *ServiceDiscovery, shutDownChan chan interface{}, wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -103,12 +136,22 @@ func (sd *ServiceDiscovery) validateConfig() bool {
 		return false
 	}
 
-	if sd.Config.DockerLabel == nil && len(sd.Config.TaskDefinitions) == 0 && len(sd.Config.ServiceNamesForTasks) == 0 {
+	if sd.Config.LocalMode {
+		// The ECS agent does not know the services of the tasks
+		if len(sd.Config.ServiceNamesForTasks) > 0 {
+			log.Printf("W! Service name based discovery is not supported in local mode and is ignored.\n")
+		}
+		if sd.Config.DockerLabel == nil && len(sd.Config.TaskDefinitions) == 0 {
+			log.Printf("E! Neither docker label based discovery, nor task definition based discovery is enabled in local mode.\n")
+			return false
+		}
+	} else if sd.Config.DockerLabel == nil && len(sd.Config.TaskDefinitions) == 0 && len(sd.Config.ServiceNamesForTasks) == 0 {
 		log.Printf("E! Neither docker label based discovery, nor task definition based discovery, nor service name based discovery is enabled.\n")
 		return false
 	}
 
-	if sd.Config.TargetCluster == "" || sd.Config.TargetClusterRegion == "" {
+	// The local mode discovers the tasks of the cluster of the container instance
+	if !sd.Config.LocalMode && (sd.Config.TargetCluster == "" || sd.Config.TargetClusterRegion == "") {
 		log.Printf("E! Target ECS cluster info is not correct.\n")
 		return false
 	}
@@ -0,0 +1,150 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package prometheustargets keeps the status of the scrape targets of the prometheus input, which is
+// served by the server extension.
+package prometheustargets
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
+)
+
+const (
+	// JobLabel and InstanceLabel are added to the targets by the prometheus input to save their job
+	// and address before relabel, they identify the target of the scraped samples.
+	JobLabel      = "cwagent_saved_scrape_job"
+	InstanceLabel = "cwagent_saved_scrape_instance"
+)
+
+// TargetsStatus is the status of the scrape targets, like the /api/v1/targets endpoint of Prometheus.
+type TargetsStatus struct {
+	ActiveTargets []TargetStatus `json:"activeTargets"`
+	// DroppedTargetCounts are the number of targets dropped by relabeling per job.
+	DroppedTargetCounts map[string]int `json:"droppedTargetCounts"`
+}
+
+// TargetStatus is the status of a target discovered by the scrape discovery manager or the target allocator.
+type TargetStatus struct {
+	Job                   string            `json:"job"`
+	ScrapeURL             string            `json:"scrapeUrl"`
+	Labels                map[string]string `json:"labels"`
+	Health                string            `json:"health"`
+	LastScrape            time.Time         `json:"lastScrape"`
+	LastScrapeDuration    float64           `json:"lastScrapeDuration"`
+	LastScrapeSampleCount int               `json:"lastScrapeSampleCount"`
+	LastError             string            `json:"lastError"`
+}
+
+// Manager is the part of the scrape manager listing the targets.
+type Manager interface {
+	TargetsActive() map[string][]*scrape.Target
+	TargetsDroppedCounts() map[string]int
+}
+
+// targetKey identifies a target by the job and instance saved before relabel, in the labels of the
+// target and of its samples.
+type targetKey struct {
+	job      string
+	instance string
+}
+
+// registry keeps the scrape manager of the prometheus input and the number of samples of the last
+// scrape of every target, which the scrape manager does not expose.
+type registry struct {
+	mu           sync.Mutex
+	manager      Manager
+	sampleCounts map[targetKey]int
+}
+
+var defaultRegistry = newRegistry()
+
+func newRegistry() *registry {
+	return &registry{sampleCounts: map[targetKey]int{}}
+}
+
+// SetManager sets the scrape manager whose targets are listed, nil once the input is stopped.
+func SetManager(manager Manager) {
+	defaultRegistry.setManager(manager)
+}
+
+// RecordScrape saves the number of samples of the last scrape of the target with the job and instance
+// saved before relabel.
+func RecordScrape(job, instance string, sampleCount int) {
+	defaultRegistry.recordScrape(job, instance, sampleCount)
+}
+
+// Status returns the status of the scrape targets, no target is returned when the prometheus input
+// is not running.
+func Status() TargetsStatus {
+	return defaultRegistry.status()
+}
+
+func (r *registry) setManager(manager Manager) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manager = manager
+	r.sampleCounts = map[targetKey]int{}
+}
+
+func (r *registry) recordScrape(job, instance string, sampleCount int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sampleCounts[targetKey{job: job, instance: instance}] = sampleCount
+}
+
+func (r *registry) status() TargetsStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := TargetsStatus{ActiveTargets: []TargetStatus{}, DroppedTargetCounts: map[string]int{}}
+	if r.manager == nil {
+		return status
+	}
+	b := labels.NewScratchBuilder(0)
+	active := map[targetKey]bool{}
+	for job, targets := range r.manager.TargetsActive() {
+		for _, t := range targets {
+			lbls := t.Labels(&b)
+			key := targetKey{job: lbls.Get(JobLabel), instance: lbls.Get(InstanceLabel)}
+			active[key] = true
+			ts := TargetStatus{
+				Job:                   job,
+				ScrapeURL:             t.URL().String(),
+				Labels:                map[string]string{},
+				Health:                string(t.Health()),
+				LastScrape:            t.LastScrape(),
+				LastScrapeDuration:    t.LastScrapeDuration().Seconds(),
+				LastScrapeSampleCount: r.sampleCounts[key],
+			}
+			lbls.Range(func(l labels.Label) {
+				if l.Name != JobLabel && l.Name != InstanceLabel {
+					ts.Labels[l.Name] = l.Value
+				}
+			})
+			if err := t.LastError(); err != nil {
+				ts.LastError = err.Error()
+			}
+			status.ActiveTargets = append(status.ActiveTargets, ts)
+		}
+	}
+	for job, count := range r.manager.TargetsDroppedCounts() {
+		status.DroppedTargetCounts[job] = count
+	}
+	sort.Slice(status.ActiveTargets, func(i, j int) bool {
+		if status.ActiveTargets[i].Job != status.ActiveTargets[j].Job {
+			return status.ActiveTargets[i].Job < status.ActiveTargets[j].Job
+		}
+		return status.ActiveTargets[i].ScrapeURL < status.ActiveTargets[j].ScrapeURL
+	})
+	// the targets which are no longer discovered are forgotten
+	for key := range r.sampleCounts {
+		if !active[key] {
+			delete(r.sampleCounts, key)
+		}
+	}
+	return status
+}
@@ -0,0 +1,82 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheustargets
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockManager struct {
+	active  map[string][]*scrape.Target
+	dropped map[string]int
+}
+
+func (m *mockManager) TargetsActive() map[string][]*scrape.Target {
+	return m.active
+}
+
+func (m *mockManager) TargetsDroppedCounts() map[string]int {
+	return m.dropped
+}
+
+// newTarget returns a target of the job whose address and job may have been relabeled.
+func newTarget(job, address, relabeledJob, relabeledAddress string) *scrape.Target {
+	return scrape.NewTarget(
+		labels.FromStrings("__address__", relabeledAddress, "__scheme__", "http", "__metrics_path__", "/metrics", "job", relabeledJob, JobLabel, job, InstanceLabel, address),
+		labels.FromStrings("__address__", address, "job", job),
+		nil,
+	)
+}
+
+func TestRegistry(t *testing.T) {
+	r := newRegistry()
+	assert.Equal(t, TargetsStatus{ActiveTargets: []TargetStatus{}, DroppedTargetCounts: map[string]int{}}, r.status(), "no target should be listed without scrape manager")
+
+	// The sample counts are keyed by the job and address before relabel, like the samples.
+	up := newTarget("nginx", "10.0.0.1:9113", "web", "10.0.0.1:9114")
+	lastScrape := time.Now()
+	up.Report(lastScrape, 250*time.Millisecond, nil)
+	down := newTarget("envoy", "10.0.0.2:9901", "envoy", "10.0.0.2:9901")
+	down.Report(lastScrape, time.Second, errors.New("connection refused"))
+	r.setManager(&mockManager{
+		active:  map[string][]*scrape.Target{"nginx": {up}, "envoy": {down}},
+		dropped: map[string]int{"nginx": 3},
+	})
+	r.recordScrape("nginx", "10.0.0.1:9113", 2)
+
+	status := r.status()
+	assert.Equal(t, map[string]int{"nginx": 3}, status.DroppedTargetCounts)
+	require.Len(t, status.ActiveTargets, 2)
+	assert.Equal(t, TargetStatus{
+		Job:                "envoy",
+		ScrapeURL:          "http://10.0.0.2:9901/metrics",
+		Labels:             map[string]string{"job": "envoy"},
+		Health:             "down",
+		LastScrape:         lastScrape,
+		LastScrapeDuration: 1,
+		LastError:          "connection refused",
+	}, status.ActiveTargets[0])
+	assert.Equal(t, TargetStatus{
+		Job:                   "nginx",
+		ScrapeURL:             "http://10.0.0.1:9114/metrics",
+		Labels:                map[string]string{"job": "web"},
+		Health:                "up",
+		LastScrape:            lastScrape,
+		LastScrapeDuration:    0.25,
+		LastScrapeSampleCount: 2,
+	}, status.ActiveTargets[1])
+
+	// The sample counts of the targets which are no longer discovered are dropped.
+	r.setManager(&mockManager{})
+	r.recordScrape("nginx", "10.0.0.1:9113", 1)
+	r.status()
+	assert.Empty(t, r.sampleCounts)
+}
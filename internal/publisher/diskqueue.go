@@ -0,0 +1,188 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package publisher
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/seqfile"
+)
+
+const diskQueueFileSuffix = ".req"
+
+// DiskQueueCodec converts the requests of a DiskQueue from and to their persisted form.
+type DiskQueueCodec interface {
+	Marshal(req interface{}) ([]byte, error)
+	// Unmarshal returns the request, which must be comparable, e.g. a pointer, since the dequeued
+	// requests are acknowledged by their value.
+	Unmarshal(data []byte) (interface{}, error)
+	// Timestamp returns the time the age of the request is measured from, so a request which is
+	// queued again keeps its age.
+	Timestamp(req interface{}) time.Time
+}
+
+// DiskQueue is a FIFO queue persisting each request in its own file, so the requests survive agent
+// restarts and are replayed in the order they were enqueued. When the size limit is reached the oldest
+// requests are dropped, and the requests older than the max age are dropped when dequeued.
+// A dequeued request is removed from the disk once acknowledged, so the requests which were being
+// sent when the agent stopped are replayed as well.
+type DiskQueue struct {
+	dir     string
+	files   *seqfile.Dir
+	maxSize int64
+	maxAge  time.Duration
+	codec   DiskQueueCodec
+	sync.Mutex
+	entries []seqfile.Entry
+	pending map[interface{}]seqfile.Entry // dequeued and not acknowledged yet
+	size    int64
+	closed  bool
+}
+
+var _ Queue = (*DiskQueue)(nil)
+
+// NewDiskQueue opens the queue persisted in dir, bounded to maxSize bytes. A maxAge of 0 disables the
+// expiry of the requests.
+func NewDiskQueue(dir string, maxSize int64, maxAge time.Duration, codec DiskQueueCodec) (*DiskQueue, error) {
+	if maxSize <= 0 {
+		return nil, errors.New("disk queue size should be larger than 0")
+	}
+	files, entries, err := seqfile.Open(dir, diskQueueFileSuffix, func(name string) {
+		log.Printf("W! Ignoring unexpected file %v in disk queue %v", name, dir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	q := &DiskQueue{dir: dir, files: files, maxSize: maxSize, maxAge: maxAge, codec: codec, entries: entries, pending: map[interface{}]seqfile.Entry{}}
+	for _, entry := range entries {
+		q.size += entry.Size
+	}
+	if len(q.entries) > 0 {
+		log.Printf("I! Found %d queued requests (%d bytes) in %v", len(q.entries), q.size, dir)
+	}
+	return q, nil
+}
+
+// Len returns the number of requests held in the queue, the dequeued requests excluded.
+func (q *DiskQueue) Len() int {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.entries)
+}
+
+// Enqueue persists the request, dropping the oldest requests when the size limit would be exceeded.
+// The request is dropped when it cannot be persisted.
+func (q *DiskQueue) Enqueue(req interface{}) {
+	if q.expired(req) {
+		log.Printf("W! message is dropped due to being older than the disk queue max age %v", q.maxAge)
+		return
+	}
+	data, err := q.codec.Marshal(req)
+	if err != nil {
+		log.Printf("E! message is dropped due to failing to encode it for the disk queue: %v", err)
+		return
+	}
+
+	q.Lock()
+	defer q.Unlock()
+
+	size := int64(len(data))
+	if size > q.maxSize {
+		log.Printf("W! message is dropped due to being larger than the disk queue size limit")
+		return
+	}
+	for len(q.entries) > 0 && q.size+size > q.maxSize {
+		log.Printf("W! message is dropped due to disk queue %v is full", q.dir)
+		if err = q.removeOldest(); err != nil {
+			log.Printf("E! %v", err)
+			return
+		}
+	}
+
+	entry, err := q.files.Write(data)
+	if err != nil {
+		log.Printf("E! message is dropped due to failing to write it to disk queue %v: %v", q.dir, err)
+		return
+	}
+	q.entries = append(q.entries, entry)
+	q.size += entry.Size
+}
+
+// Dequeue returns the oldest request which has not expired. The request stays on the disk until
+// it is acknowledged with Ack.
+func (q *DiskQueue) Dequeue() (interface{}, bool) {
+	q.Lock()
+	defer q.Unlock()
+	for !q.closed && len(q.entries) > 0 {
+		req, err := q.read(q.entries[0])
+		if err != nil {
+			log.Printf("E! Unable to read queued request %v, discarding it: %v", q.files.Path(q.entries[0].Seq), err)
+		} else if q.expired(req) {
+			log.Printf("W! message is dropped due to being older than the disk queue max age %v", q.maxAge)
+		} else {
+			q.pending[req] = q.entries[0]
+			q.entries = q.entries[1:]
+			return req, true
+		}
+		if err = q.removeOldest(); err != nil {
+			log.Printf("E! %v", err)
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// Ack removes the dequeued request from the disk once it has been sent, or queued again.
+func (q *DiskQueue) Ack(req interface{}) {
+	q.Lock()
+	defer q.Unlock()
+	entry, ok := q.pending[req]
+	if !ok {
+		return
+	}
+	delete(q.pending, req)
+	if err := q.remove(entry); err != nil {
+		log.Printf("E! %v", err)
+	}
+}
+
+// Close stops handing out the requests. The requests still queued, and the ones enqueued afterwards,
+// stay on the disk to be replayed once the queue is opened again.
+func (q *DiskQueue) Close() {
+	q.Lock()
+	defer q.Unlock()
+	q.closed = true
+}
+
+func (q *DiskQueue) expired(req interface{}) bool {
+	return q.maxAge > 0 && time.Since(q.codec.Timestamp(req)) > q.maxAge
+}
+
+func (q *DiskQueue) read(entry seqfile.Entry) (interface{}, error) {
+	data, err := q.files.Read(entry)
+	if err != nil {
+		return nil, err
+	}
+	return q.codec.Unmarshal(data)
+}
+
+func (q *DiskQueue) removeOldest() error {
+	if err := q.remove(q.entries[0]); err != nil {
+		return err
+	}
+	q.entries = q.entries[1:]
+	return nil
+}
+
+func (q *DiskQueue) remove(entry seqfile.Entry) error {
+	if err := q.files.Remove(entry); err != nil {
+		return fmt.Errorf("unable to remove queued request: %w", err)
+	}
+	q.size -= entry.Size
+	return nil
+}
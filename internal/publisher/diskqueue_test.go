@@ -0,0 +1,104 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package publisher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	ID        int       `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type testCodec struct{}
+
+func (testCodec) Marshal(req interface{}) ([]byte, error) {
+	return json.Marshal(req)
+}
+
+func (testCodec) Unmarshal(data []byte) (interface{}, error) {
+	var req testRequest
+	err := json.Unmarshal(data, &req)
+	return &req, err
+}
+
+func (testCodec) Timestamp(req interface{}) time.Time {
+	return req.(*testRequest).Timestamp
+}
+
+func TestDiskQueueReplaysAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	queue, err := NewDiskQueue(dir, 1024, time.Hour, testCodec{})
+	require.NoError(t, err)
+	queue.Enqueue(&testRequest{ID: 1, Timestamp: now})
+	queue.Enqueue(&testRequest{ID: 2, Timestamp: now})
+	queue.Enqueue(&testRequest{ID: 3, Timestamp: now})
+	v, ok := queue.Dequeue()
+	require.True(t, ok)
+	assert.Equal(t, 1, v.(*testRequest).ID)
+	queue.Ack(v)
+	v, ok = queue.Dequeue()
+	require.True(t, ok)
+	assert.Equal(t, 2, v.(*testRequest).ID)
+	queue.Close()
+	_, ok = queue.Dequeue()
+	assert.False(t, ok, "closed queue should not hand out requests")
+
+	// The request which was not acknowledged is replayed.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000009.req.tmp"), []byte("{"), 0600))
+	queue, err = NewDiskQueue(dir, 1024, time.Hour, testCodec{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, queue.Len())
+	for _, want := range []int{2, 3} {
+		v, ok = queue.Dequeue()
+		require.True(t, ok)
+		assert.Equal(t, want, v.(*testRequest).ID)
+		queue.Ack(v)
+	}
+	_, ok = queue.Dequeue()
+	assert.False(t, ok)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestDiskQueueDropsOldest(t *testing.T) {
+	now := time.Now()
+	data, err := testCodec{}.Marshal(&testRequest{ID: 1, Timestamp: now})
+	require.NoError(t, err)
+	queue, err := NewDiskQueue(t.TempDir(), int64(2*len(data)), time.Hour, testCodec{})
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		queue.Enqueue(&testRequest{ID: i, Timestamp: now})
+	}
+	assert.Equal(t, 2, queue.Len())
+	v, ok := queue.Dequeue()
+	require.True(t, ok)
+	assert.Equal(t, 2, v.(*testRequest).ID)
+}
+
+func TestDiskQueueDropsExpired(t *testing.T) {
+	now := time.Now()
+	queue, err := NewDiskQueue(t.TempDir(), 1024, time.Hour, testCodec{})
+	require.NoError(t, err)
+	queue.Enqueue(&testRequest{ID: 1, Timestamp: now.Add(-2 * time.Hour)})
+	assert.Equal(t, 0, queue.Len())
+
+	queue.Enqueue(&testRequest{ID: 2, Timestamp: now.Add(-time.Hour + 100*time.Millisecond)})
+	queue.Enqueue(&testRequest{ID: 3, Timestamp: now})
+	time.Sleep(200 * time.Millisecond)
+	v, ok := queue.Dequeue()
+	require.True(t, ok)
+	assert.Equal(t, 3, v.(*testRequest).ID)
+	assert.Equal(t, 0, queue.Len())
+}
@@ -0,0 +1,248 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const (
+	MaskAction = "mask"
+	HashAction = "hash"
+	DropAction = "drop"
+
+	CreditCardDetector   = "credit_card"
+	AWSAccessKeyDetector = "aws_access_key"
+	AWSSecretKeyDetector = "aws_secret_key"
+	EmailDetector        = "email"
+	BearerTokenDetector  = "bearer_token"
+
+	// secretGroup is the capture group replaced when present in the expression of a rule, so
+	// that the context of the secret, e.g. "Bearer ", is kept.
+	secretGroup = "secret"
+)
+
+var (
+	validActions    = []string{MaskAction, HashAction, DropAction}
+	validActionsSet = map[string]bool{
+		MaskAction: true,
+		HashAction: true,
+		DropAction: true,
+	}
+
+	detectors = map[string]*detector{
+		CreditCardDetector: {
+			expression: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+			validate:   isCardNumber,
+		},
+		AWSAccessKeyDetector: {
+			expression: regexp.MustCompile(`\b(?:AKIA|ASIA|AIDA|AROA|AGPA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`),
+		},
+		AWSSecretKeyDetector: {
+			expression: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})\b`),
+		},
+		EmailDetector: {
+			expression: regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
+		},
+		BearerTokenDetector: {
+			expression: regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]+=*)`),
+		},
+	}
+)
+
+type detector struct {
+	expression *regexp.Regexp
+	validate   func(string) bool
+}
+
+// Rule redacts the matches of a built-in detector, or of a custom expression, from log events.
+type Rule struct {
+	// Name of the built-in detector, or label of the custom expression used in stats and masks.
+	Name string `toml:"name"`
+	// Custom regular expression. When it has a capture group named "secret" only the group is redacted.
+	Expression string `toml:"expression"`
+	// mask (default), hash or drop
+	Action string `toml:"action"`
+
+	detector *detector
+}
+
+// Redactor applies the redaction rules of a log source or destination.
+type Redactor struct {
+	rules   []*Rule
+	hashKey []byte
+}
+
+// Result describes the redactions applied to a log event.
+type Result struct {
+	// Message after redaction, empty when the event must be dropped.
+	Message string
+	// Number of redactions per rule name.
+	Counts map[string]int
+	// Drop is set when a rule with the drop action matched the event.
+	Drop bool
+}
+
+// New returns a Redactor for the rules, or nil when there are no rules. The hash key is
+// required by rules with the hash action.
+func New(rules []*Rule, hashKey string) (*Redactor, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	for _, rule := range rules {
+		if err := rule.init(); err != nil {
+			return nil, err
+		}
+		if rule.Action == HashAction && hashKey == "" {
+			return nil, fmt.Errorf("redaction %s uses the hash action which requires a hash key", rule.Name)
+		}
+	}
+	return &Redactor{rules: rules, hashKey: []byte(hashKey)}, nil
+}
+
+// Detectors returns the names of the built-in detectors.
+func Detectors() []string {
+	names := make([]string, 0, len(detectors))
+	for name := range detectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (rule *Rule) init() error {
+	if rule.Action == "" {
+		rule.Action = MaskAction
+	}
+	if !validActionsSet[rule.Action] {
+		return fmt.Errorf("redaction action %s is incorrect, valid actions are: %v", rule.Action, validActions)
+	}
+	if rule.Expression == "" {
+		d, ok := detectors[rule.Name]
+		if !ok {
+			return fmt.Errorf("redaction detector %s is incorrect, valid detectors are: %v", rule.Name, Detectors())
+		}
+		rule.detector = d
+		return nil
+	}
+	if rule.Name == "" {
+		return fmt.Errorf("redaction expression %s requires a name", rule.Expression)
+	}
+	expression, err := regexp.Compile(rule.Expression)
+	if err != nil {
+		return fmt.Errorf("redaction regex has issue, regexp: Compile( %v ): %v", rule.Expression, err.Error())
+	}
+	rule.detector = &detector{expression: expression}
+	return nil
+}
+
+// Redact applies the rules in order to the message.
+func (r *Redactor) Redact(msg string) Result {
+	result := Result{Message: msg}
+	for _, rule := range r.rules {
+		count := 0
+		result.Message = rule.replaceAll(result.Message, func(secret string) string {
+			count++
+			if rule.Action == HashAction {
+				return fmt.Sprintf("[HASHED:%s:%s]", rule.Name, r.hash(secret))
+			}
+			return fmt.Sprintf("[REDACTED:%s]", rule.Name)
+		})
+		if count == 0 {
+			continue
+		}
+		if result.Counts == nil {
+			result.Counts = map[string]int{}
+		}
+		result.Counts[rule.Name] += count
+		if rule.Action == DropAction {
+			result.Drop = true
+			result.Message = ""
+			return result
+		}
+	}
+	return result
+}
+
+func (r *Redactor) hash(secret string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(secret))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// replaceAll replaces the secret of each valid match with the result of replace.
+func (rule *Rule) replaceAll(s string, replace func(string) string) string {
+	expression := rule.detector.expression
+	group := expression.SubexpIndex(secretGroup)
+	matches := expression.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	var out []byte
+	last := 0
+	replaced := false
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 {
+			start, end = m[2*group], m[2*group+1]
+			if start < 0 {
+				continue
+			}
+		}
+		secret := s[start:end]
+		if rule.detector.validate != nil && !rule.detector.validate(secret) {
+			continue
+		}
+		out = append(out, s[last:start]...)
+		out = append(out, replace(secret)...)
+		last = end
+		replaced = true
+	}
+	if !replaced {
+		return s
+	}
+	return string(append(out, s[last:]...))
+}
+
+// cardNumberPrefix matches the issuer prefixes of Visa, Mastercard, American Express, Discover,
+// Diners Club and JCB, which keeps numbers like epoch timestamps from being mistaken for cards.
+var cardNumberPrefix = regexp.MustCompile(`^(?:4|5[1-5]|2[2-7]|3[47]|3[068]|6(?:011|5)|35)`)
+
+func isCardNumber(number string) bool {
+	digits := make([]byte, 0, len(number))
+	for i := 0; i < len(number); i++ {
+		if number[i] >= '0' && number[i] <= '9' {
+			digits = append(digits, number[i])
+		}
+	}
+	return cardNumberPrefix.Match(digits) && isLuhnValid(string(digits))
+}
+
+// isLuhnValid reports whether the digits of the number, ignoring separators, pass the Luhn checksum.
+func isLuhnValid(number string) bool {
+	sum := 0
+	digits := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
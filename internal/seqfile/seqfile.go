@@ -0,0 +1,124 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package seqfile persists records in sequence numbered files of a directory, e.g. to replay them
+// after agent restarts in the order they were written.
+package seqfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const tempSuffix = ".tmp"
+
+// Entry is a record persisted in the directory.
+type Entry struct {
+	Seq  uint64
+	Size int64
+}
+
+// Dir writes each record to its own file named after the sequence number and the suffix. A record
+// is written to a temporary file first, so a partially written record is never read. Dir is not
+// go-routine safe.
+type Dir struct {
+	path    string
+	suffix  string
+	nextSeq uint64
+}
+
+// Open creates the directory when it is missing, removes the records which were partially written
+// and returns the records found in the directory, oldest first. ignored is called with the names of
+// the files which have the suffix but no sequence number.
+func Open(path string, suffix string, ignored func(name string)) (*Dir, []Entry, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, nil, err
+	}
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	d := &Dir{path: path, suffix: suffix}
+	var entries []Entry
+	for _, f := range files {
+		name := f.Name()
+		if strings.HasSuffix(name, suffix+tempSuffix) {
+			os.Remove(filepath.Join(path, name))
+			continue
+		}
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, suffix), 10, 64)
+		if err != nil {
+			ignored(name)
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, Entry{Seq: seq, Size: info.Size()})
+		if seq >= d.nextSeq {
+			d.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Seq < entries[j].Seq
+	})
+	return d, entries, nil
+}
+
+// Write persists the record with the next sequence number and syncs it to the disk.
+func (d *Dir) Write(data []byte) (Entry, error) {
+	seq := d.nextSeq
+	tmp := d.Path(seq) + tempSuffix
+	err := writeFileSync(tmp, data)
+	if err == nil {
+		err = os.Rename(tmp, d.Path(seq))
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return Entry{}, err
+	}
+	d.nextSeq++
+	return Entry{Seq: seq, Size: int64(len(data))}, nil
+}
+
+// Read returns the content of the record.
+func (d *Dir) Read(e Entry) ([]byte, error) {
+	return os.ReadFile(d.Path(e.Seq))
+}
+
+// Remove deletes the record, a record which is already gone is not an error.
+func (d *Dir) Remove(e Entry) error {
+	if err := os.Remove(d.Path(e.Seq)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Path returns the file of the record with the sequence number.
+func (d *Dir) Path(seq uint64) string {
+	return filepath.Join(d.path, fmt.Sprintf("%020d%s", seq, d.suffix))
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,46 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package seqfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records")
+	d, entries, err := Open(path, ".rec", nil)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	for _, data := range []string{"first", "second", "third"} {
+		_, err = d.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, d.Remove(Entry{Seq: 0}))
+	require.NoError(t, d.Remove(Entry{Seq: 0}), "a removed record is not an error")
+
+	require.NoError(t, os.WriteFile(d.Path(3)+tempSuffix, []byte("partial"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "unexpected.rec"), nil, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "other.txt"), nil, 0600))
+	var ignored []string
+	d, entries, err = Open(path, ".rec", func(name string) {
+		ignored = append(ignored, name)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Seq: 1, Size: 6}, {Seq: 2, Size: 5}}, entries)
+	assert.Equal(t, []string{"unexpected.rec"}, ignored)
+	assert.NoFileExists(t, d.Path(3)+tempSuffix)
+	data, err := d.Read(entries[1])
+	require.NoError(t, err)
+	assert.Equal(t, "third", string(data))
+
+	entry, err := d.Write([]byte("fourth"))
+	require.NoError(t, err)
+	assert.Equal(t, Entry{Seq: 3, Size: 6}, entry)
+	assert.FileExists(t, filepath.Join(path, "00000000000000000003.rec"))
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"errors"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -18,18 +20,37 @@ import (
 
 var ErrOutputStopped = errors.New("Output plugin stopped")
 
+// destBufferSize is the number of log events buffered for each destination of a log source, so a
+// destination slower than the others does not hold them back.
+const destBufferSize = 100
+
 // A LogCollection is a collection of LogSrc, a plugin which can provide many LogSrc
 type LogCollection interface {
 	FindLogSrc() []LogSrc
 	Start(acc telegraf.Accumulator) error
 }
 
+// A LogCollectionNotifier is a LogCollection which notifies when new LogSrc may be found, so it is
+// not scanned every second.
+type LogCollectionNotifier interface {
+	LogCollection
+	// Notify returns the channel receiving a value when FindLogSrc should be called, or nil when
+	// the collection should be scanned every second.
+	Notify() <-chan struct{}
+}
+
 type LogEvent interface {
 	Message() string
 	Time() time.Time
 	Done()
 }
 
+// StructuredLogEvent is a LogEvent carrying the fields extracted by the parsers of its source.
+type StructuredLogEvent interface {
+	LogEvent
+	Fields() map[string]interface{}
+}
+
 // A LogSrc is a single source where log events are generated
 // e.g. a single log file
 type LogSrc interface {
@@ -44,6 +65,13 @@ type LogSrc interface {
 	Stop()
 }
 
+// MultiDestinationLogSrc is a LogSrc publishing each of its log events to several destinations.
+// The events are only acknowledged to the source once every destination has acknowledged them.
+type MultiDestinationLogSrc interface {
+	LogSrc
+	Destinations() []string
+}
+
 // A LogBackend is able to return a LogDest of a given name.
 // The same name should always return the same LogDest.
 type LogBackend interface {
@@ -103,40 +131,78 @@ func (l *LogAgent) Run(ctx context.Context) {
 		}
 	}
 
+	// The collections notifying when new sources may be found are only scanned on notification
+	var polled []LogCollection
+	notifiedCh := make(chan LogCollection)
+	for _, c := range l.collections {
+		if n, ok := c.(LogCollectionNotifier); ok && n.Notify() != nil {
+			go forwardNotifications(ctx, c, n.Notify(), notifiedCh)
+		} else {
+			polled = append(polled, c)
+		}
+	}
+
 	t := time.NewTicker(time.Second)
 	defer t.Stop()
 	for {
 		select {
 		case <-t.C:
 			log.Printf("D! [logagent] open file count, %v", tail.OpenFileCount.Load())
-			for _, c := range l.collections {
-				srcs := c.FindLogSrc()
-				for _, src := range srcs {
-					dname := src.Destination()
-					logGroup := src.Group()
-					logStream := src.Stream()
-					description := src.Description()
-					retention := src.Retention()
-					logGroupClass := src.Class()
-					backend, ok := l.backends[dname]
-					if !ok {
-						log.Printf("E! [logagent] Failed to find destination %s for log source %s/%s(%s) ", dname, logGroup, logStream, description)
-						continue
-					}
-					retention = l.checkRetentionAlreadyAttempted(retention, logGroup)
-					dest := backend.CreateDest(logGroup, logStream, retention, logGroupClass, src)
-					l.destNames[dest] = dname
-					log.Printf("I! [logagent] piping log from %s/%s(%s) to %s with retention %d", logGroup, logStream, description, dname, retention)
-					go l.runSrcToDest(src, dest)
-				}
+			for _, c := range polled {
+				l.connectLogSrcs(c)
 			}
+		case c := <-notifiedCh:
+			l.connectLogSrcs(c)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (l *LogAgent) runSrcToDest(src LogSrc, dest LogDest) {
+// connectLogSrcs finds the new LogSrc of the collection and pipes them to their LogDest.
+func (l *LogAgent) connectLogSrcs(c LogCollection) {
+	srcs := c.FindLogSrc()
+	for _, src := range srcs {
+		logGroup := src.Group()
+		logStream := src.Stream()
+		description := src.Description()
+		logGroupClass := src.Class()
+		var dests []LogDest
+		for _, dname := range destinationNames(src) {
+			backend, ok := l.backends[dname]
+			if !ok {
+				log.Printf("E! [logagent] Failed to find destination %s for log source %s/%s(%s) ", dname, logGroup, logStream, description)
+				continue
+			}
+			retention := l.checkRetentionAlreadyAttempted(src.Retention(), dname, logGroup)
+			dest := backend.CreateDest(logGroup, logStream, retention, logGroupClass, src)
+			l.destNames[dest] = dname
+			log.Printf("I! [logagent] piping log from %s/%s(%s) to %s with retention %d", logGroup, logStream, description, dname, retention)
+			dests = append(dests, dest)
+		}
+		if len(dests) == 0 {
+			continue
+		}
+		go l.runSrcToDest(src, dests)
+	}
+}
+
+func forwardNotifications(ctx context.Context, c LogCollection, notify <-chan struct{}, notifiedCh chan<- LogCollection) {
+	for {
+		select {
+		case <-notify:
+			select {
+			case notifiedCh <- c:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (l *LogAgent) runSrcToDest(src LogSrc, dests []LogDest) {
 	eventsCh := make(chan LogEvent)
 	defer src.Stop()
 
@@ -149,26 +215,122 @@ func (l *LogAgent) runSrcToDest(src LogSrc, dest LogDest) {
 		eventsCh <- e
 	})
 
-	for e := range eventsCh {
+	// each destination publishes from its own goroutine, so a stalled destination does not hold
+	// back the others until its buffer is full. The source is finalized once any of them stops.
+	stopped := make(chan struct{})
+	var stopOnce sync.Once
+	var wg sync.WaitGroup
+	destChs := make([]chan LogEvent, len(dests))
+	for i, dest := range dests {
+		destChs[i] = make(chan LogEvent, destBufferSize)
+		wg.Add(1)
+		go func(dest LogDest, destCh <-chan LogEvent) {
+			defer wg.Done()
+			if !l.publishToDest(src, dest, destCh) {
+				stopOnce.Do(func() { close(stopped) })
+			}
+		}(dest, destChs[i])
+	}
+	closeDests := func() {
+		for _, destCh := range destChs {
+			close(destCh)
+		}
+	}
+
+	for {
+		select {
+		case e, ok := <-eventsCh:
+			if !ok {
+				// the events of the stopped source are published before it is finalized
+				closeDests()
+				wg.Wait()
+				return
+			}
+			if len(dests) > 1 {
+				e = newFanOutLogEvent(e, len(dests))
+			}
+			for _, destCh := range destChs {
+				select {
+				case destCh <- e:
+				case <-stopped:
+					closeDests()
+					return
+				}
+			}
+		case <-stopped:
+			closeDests()
+			return
+		}
+	}
+}
+
+// publishToDest publishes the events of the channel to the destination, and returns false when
+// the destination has stopped or failed to publish.
+func (l *LogAgent) publishToDest(src LogSrc, dest LogDest, destCh <-chan LogEvent) bool {
+	for e := range destCh {
 		err := dest.Publish([]LogEvent{e})
 		if err == ErrOutputStopped {
 			log.Printf("I! [logagent] Log destination %v has stopped, finalizing %v/%v", l.destNames[dest], src.Group(), src.Stream())
-			return
+			return false
 		}
 		if err != nil {
 			log.Printf("E! [logagent] Failed to publish log to %v, error: %v", l.destNames[dest], err)
-			return
+			return false
+		}
+	}
+	return true
+}
+
+// destinationNames returns the names of the destinations the log source publishes to.
+func destinationNames(src LogSrc) []string {
+	if multi, ok := src.(MultiDestinationLogSrc); ok {
+		if names := multi.Destinations(); len(names) > 0 {
+			return names
 		}
 	}
+	return []string{src.Destination()}
+}
+
+// fanOutLogEvent is a log event published to several destinations, it is acknowledged to its
+// source when the last destination acknowledges it.
+type fanOutLogEvent struct {
+	LogEvent
+	pending int32
+}
+
+func newFanOutLogEvent(e LogEvent, destinations int) LogEvent {
+	fe := &fanOutLogEvent{LogEvent: e, pending: int32(destinations)}
+	if se, ok := e.(StructuredLogEvent); ok {
+		return &fanOutStructuredLogEvent{fanOutLogEvent: fe, fields: se.Fields}
+	}
+	return fe
+}
+
+func (e *fanOutLogEvent) Done() {
+	if atomic.AddInt32(&e.pending, -1) == 0 {
+		e.LogEvent.Done()
+	}
+}
+
+type fanOutStructuredLogEvent struct {
+	*fanOutLogEvent
+	fields func() map[string]interface{}
+}
+
+func (e *fanOutStructuredLogEvent) Fields() map[string]interface{} {
+	return e.fields()
 }
 
-func (l *LogAgent) checkRetentionAlreadyAttempted(retention int, logGroup string) int {
-	if retention > 0 && l.retentionAlreadyAttempted[logGroup] {
-		log.Printf("D! [logagent] Retention already set for log group %s, current retention %d", logGroup, retention)
+// checkRetentionAlreadyAttempted returns -1 when the retention of the log group was already set
+// through the destination. The same log group in different destinations is set separately.
+func (l *LogAgent) checkRetentionAlreadyAttempted(retention int, dname, logGroup string) int {
+	key := dname + "/" + logGroup
+	if retention > 0 && l.retentionAlreadyAttempted[key] {
+		log.Printf("D! [logagent] Retention already set for log group %s in %s, current retention %d", logGroup, dname, retention)
 		retention = -1
 	} else if retention > 0 {
-		log.Printf("I! First time setting retention for log group %s, update map to avoid setting twice", logGroup)
-		l.retentionAlreadyAttempted[logGroup] = true
+		log.Printf("I! First time setting retention for log group %s in %s, update map to avoid setting twice", logGroup, dname)
+		l.retentionAlreadyAttempted[key] = true
 	}
 	return retention
 }
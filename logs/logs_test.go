@@ -5,18 +5,151 @@ package logs
 
 import (
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatchlogs"
 )
 
 func TestRetentionAlreadySet(t *testing.T) {
 	c := config.NewConfig()
 	l := NewLogAgent(c)
-	assert.False(t, l.retentionAlreadyAttempted["logGroup1"])
-	firstAttempt := l.checkRetentionAlreadyAttempted(3, "logGroup1")
+	assert.False(t, l.retentionAlreadyAttempted["cloudwatchlogs/logGroup1"])
+	firstAttempt := l.checkRetentionAlreadyAttempted(3, "cloudwatchlogs", "logGroup1")
 	assert.Equal(t, 3, firstAttempt)
-	secondAttempt := l.checkRetentionAlreadyAttempted(3, "logGroup1")
+	secondAttempt := l.checkRetentionAlreadyAttempted(3, "cloudwatchlogs", "logGroup1")
 	assert.Equal(t, -1, secondAttempt)
-	assert.True(t, l.retentionAlreadyAttempted["logGroup1"])
+	assert.True(t, l.retentionAlreadyAttempted["cloudwatchlogs/logGroup1"])
+	otherDestination := l.checkRetentionAlreadyAttempted(3, "security", "logGroup1")
+	assert.Equal(t, 3, otherDestination)
+}
+
+type testLogEvent struct {
+	msg  string
+	done chan struct{}
+}
+
+func (e *testLogEvent) Message() string { return e.msg }
+func (e *testLogEvent) Time() time.Time { return time.Time{} }
+func (e *testLogEvent) Done()           { close(e.done) }
+
+type testLogSrc struct {
+	destinations []string
+	outputFn     func(LogEvent)
+	ready        chan struct{}
+	stopped      chan struct{}
+}
+
+func (s *testLogSrc) SetOutput(fn func(LogEvent)) {
+	s.outputFn = fn
+	close(s.ready)
+}
+func (s *testLogSrc) Group() string                  { return "group" }
+func (s *testLogSrc) Stream() string                 { return "stream" }
+func (s *testLogSrc) Destination() string            { return s.destinations[0] }
+func (s *testLogSrc) Destinations() []string         { return s.destinations }
+func (s *testLogSrc) Description() string            { return "test" }
+func (s *testLogSrc) Retention() int                 { return -1 }
+func (s *testLogSrc) Class() string                  { return "" }
+func (s *testLogSrc) Entity() *cloudwatchlogs.Entity { return nil }
+func (s *testLogSrc) Stop()                          { close(s.stopped) }
+
+type testLogDest struct {
+	events chan LogEvent
+}
+
+func (d *testLogDest) Publish(events []LogEvent) error {
+	for _, e := range events {
+		d.events <- e
+	}
+	return nil
+}
+
+func TestDestinationNames(t *testing.T) {
+	assert.Equal(t, []string{"cloudwatchlogs", "security"}, destinationNames(&testLogSrc{destinations: []string{"cloudwatchlogs", "security"}}))
+	assert.Equal(t, []string{"cloudwatchlogs"}, destinationNames(&testLogSrc{destinations: []string{"cloudwatchlogs"}}))
+}
+
+func TestFanOutAcknowledgedByAllDestinations(t *testing.T) {
+	l := NewLogAgent(config.NewConfig())
+	src := &testLogSrc{
+		destinations: []string{"cloudwatchlogs", "security"},
+		ready:        make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	local := &testLogDest{events: make(chan LogEvent, 1)}
+	central := &testLogDest{events: make(chan LogEvent, 1)}
+	go l.runSrcToDest(src, []LogDest{local, central})
+	<-src.ready
+
+	e := &testLogEvent{msg: "audit", done: make(chan struct{})}
+	src.outputFn(e)
+
+	localEvent := <-local.events
+	centralEvent := <-central.events
+	assert.Equal(t, "audit", localEvent.Message())
+	assert.Equal(t, "audit", centralEvent.Message())
+
+	localEvent.Done()
+	select {
+	case <-e.done:
+		require.Fail(t, "event acknowledged before all destinations accepted it")
+	case <-time.After(10 * time.Millisecond):
+	}
+	centralEvent.Done()
+	select {
+	case <-e.done:
+	case <-time.After(time.Second):
+		require.Fail(t, "event not acknowledged after all destinations accepted it")
+	}
+
+	src.outputFn(nil)
+	<-src.stopped
+}
+
+func TestStalledDestinationDoesNotBlockOthers(t *testing.T) {
+	l := NewLogAgent(config.NewConfig())
+	src := &testLogSrc{
+		destinations: []string{"cloudwatchlogs", "security"},
+		ready:        make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	stalled := &testLogDest{events: make(chan LogEvent)}
+	other := &testLogDest{events: make(chan LogEvent, 3)}
+	go l.runSrcToDest(src, []LogDest{stalled, other})
+	<-src.ready
+
+	var events []*testLogEvent
+	for _, msg := range []string{"a", "b", "c"} {
+		e := &testLogEvent{msg: msg, done: make(chan struct{})}
+		events = append(events, e)
+		src.outputFn(e)
+	}
+	for _, e := range events {
+		select {
+		case oe := <-other.events:
+			assert.Equal(t, e.msg, oe.Message())
+			oe.Done()
+		case <-time.After(time.Second):
+			require.Fail(t, "event held back by the stalled destination")
+		}
+	}
+
+	// the events are acknowledged once the stalled destination accepts them too
+	for _, e := range events {
+		se := <-stalled.events
+		assert.Equal(t, e.msg, se.Message())
+		se.Done()
+		select {
+		case <-e.done:
+		case <-time.After(time.Second):
+			require.Fail(t, "event not acknowledged after all destinations accepted it")
+		}
+	}
+
+	src.outputFn(nil)
+	<-src.stopped
 }
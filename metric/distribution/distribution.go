@@ -6,6 +6,7 @@ package distribution
 import (
 	"errors"
 	"math"
+	"sort"
 
 	"go.opentelemetry.io/collector/pdata/pmetric"
 )
@@ -55,3 +56,31 @@ var NewDistribution func() Distribution
 func IsSupportedValue(value, min, max float64) bool {
 	return !math.IsNaN(value) && value >= min && value <= max
 }
+
+// Percentile returns the value below which p percent of the samples of the distribution fall, with p
+// between 0 and 100. The value is estimated from the values and counts, so it is only as accurate as
+// the bucketing of the distribution.
+func Percentile(d Distribution, p float64) float64 {
+	values, counts := d.ValuesAndCounts()
+	if len(values) == 0 {
+		return 0
+	}
+	indexes := make([]int, len(values))
+	var total float64
+	for i := range indexes {
+		indexes[i] = i
+		total += counts[i]
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return values[indexes[i]] < values[indexes[j]]
+	})
+	rank := p / 100 * total
+	var cumulative float64
+	for _, i := range indexes {
+		cumulative += counts[i]
+		if cumulative >= rank {
+			return math.Max(d.Minimum(), math.Min(d.Maximum(), values[i]))
+		}
+	}
+	return d.Maximum()
+}
@@ -28,3 +28,36 @@ func TestIsAcceptedValue(t *testing.T) {
 		assert.Equal(t, testCase.want, IsSupportedValue(testCase.input, MinValue, MaxValue))
 	}
 }
+
+type testDistribution struct {
+	Distribution
+	values, counts []float64
+	min, max       float64
+}
+
+func (d testDistribution) ValuesAndCounts() ([]float64, []float64) {
+	return d.values, d.counts
+}
+
+func (d testDistribution) Minimum() float64 {
+	return d.min
+}
+
+func (d testDistribution) Maximum() float64 {
+	return d.max
+}
+
+func TestPercentile(t *testing.T) {
+	d := testDistribution{
+		values: []float64{40, 10, 30, 20},
+		counts: []float64{1, 50, 39, 10},
+		min:    5,
+		max:    35,
+	}
+	assert.Equal(t, 10.0, Percentile(d, 0))
+	assert.Equal(t, 10.0, Percentile(d, 50))
+	assert.Equal(t, 20.0, Percentile(d, 60))
+	assert.Equal(t, 30.0, Percentile(d, 90))
+	assert.Equal(t, 35.0, Percentile(d, 100), "values beyond the maximum should be clamped")
+	assert.Equal(t, 0.0, Percentile(testDistribution{}, 50))
+}
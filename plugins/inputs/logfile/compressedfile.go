@@ -0,0 +1,333 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/checkpoint"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
+)
+
+const (
+	compressedStateFilePrefix = "compressed_"
+	rotatedStateFilePrefix    = "rotated_"
+
+	// fingerprintSize is the number of leading bytes hashed to recognize the content of a rotated
+	// file in its archive.
+	fingerprintSize = 1024
+
+	// Archives last modified before the oldest timestamp accepted by CloudWatch Logs
+	// cannot contain any event which would be accepted.
+	compressedFileMaxAge = 14 * 24 * time.Hour
+)
+
+// compressedFileSettleTime is how long an archive must be left unmodified before it is read, so an
+// archive still being written by the log rotation is not read while partial.
+var compressedFileSettleTime = 5 * time.Second
+
+// rotationSuffix matches the index or date appended to the rotated file, e.g. foo.log.1 or foo.log-20240101.
+var rotationSuffix = regexp.MustCompile(`[.-]\d+$`)
+
+type compressedFileInfo struct {
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+// isReadableCompressedFile checks whether the archive uses a compression the agent can stream,
+// is no longer being written and may still contain events CloudWatch Logs accepts.
+func isReadableCompressedFile(filename string, info os.FileInfo) bool {
+	switch filepath.Ext(filename) {
+	case ".gz", ".zst", ".bz2":
+		age := time.Since(info.ModTime())
+		return age >= compressedFileSettleTime && age < compressedFileMaxAge
+	}
+	return false
+}
+
+type decompressedReader struct {
+	io.Reader
+	closeFns []func() error
+}
+
+func (r *decompressedReader) Close() error {
+	var err error
+	for _, fn := range r.closeFns {
+		if closeErr := fn(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// openCompressedFile returns a reader of the decompressed content of the archive.
+func openCompressedFile(filename string) (io.ReadCloser, error) {
+	f, err := tail.OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	r := &decompressedReader{closeFns: []func() error{f.Close}}
+	switch filepath.Ext(filename) {
+	case ".gz":
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		r.Reader = gr
+		r.closeFns = append([]func() error{gr.Close}, r.closeFns...)
+	case ".zst":
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		r.Reader = zr
+		r.closeFns = append([]func() error{func() error { zr.Close(); return nil }}, r.closeFns...)
+	case ".bz2":
+		r.Reader = bzip2.NewReader(f)
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported compressed file %v", filename)
+	}
+	return r, nil
+}
+
+// compressedFileHash returns the content hash of the archive. Hashes are cached until the
+// size or modification time of the file changes.
+func (t *LogFile) compressedFileHash(filename string) (string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+	if cached, ok := t.compressedFiles[filename]; ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.hash, nil
+	}
+
+	f, err := tail.OpenFile(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+	t.compressedFiles[filename] = compressedFileInfo{size: info.Size(), modTime: info.ModTime(), hash: hash}
+	return hash, nil
+}
+
+// The checkpoint of an archive is keyed by its content hash instead of its path, so that an archive
+// renamed by the log rotation is not read again.
+func (t *LogFile) getCompressedStateKey(hash string) string {
+	return compressedStateFilePrefix + hash
+}
+
+// restoreCompressedState restores the decompressed offset of the archive and records its current
+// path in the checkpoint, so the checkpoint is not cleaned up after the archive has been renamed.
+func (t *LogFile) restoreCompressedState(stateKey string, filename string) (int64, error) {
+	offset, err := t.restoreCheckpoint(stateKey, filename)
+	if err != nil {
+		return 0, err
+	}
+	if err = t.checkpointStore().Set(checkpoint.Checkpoint{Key: stateKey, File: filename, Offset: offset}); err != nil {
+		t.Log.Warnf("Unable to record the path %s of checkpoint %s: %v", filename, stateKey, err)
+	}
+	return offset, nil
+}
+
+// fingerprint hashes the first n bytes of the reader. It returns the number of bytes hashed,
+// which is less than n when the content is shorter.
+func fingerprint(r io.Reader, n int64) (string, int64, error) {
+	h := sha256.New()
+	size, err := io.Copy(h, io.LimitReader(r, n))
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// rotatedStateKey is the key of the checkpoint kept for the file once rotated. The fingerprint tells
+// apart the successive rotations of the file.
+func rotatedStateKey(stateKey string, fp string) string {
+	return rotatedStateFilePrefix + stateKey + "_" + fp
+}
+
+// rotatedFileCandidates returns the paths the archive may have been rotated from, e.g. foo.log.1
+// and foo.log for foo.log.1.gz.
+func rotatedFileCandidates(filename string) []string {
+	uncompressed := strings.TrimSuffix(filename, filepath.Ext(filename))
+	candidates := []string{uncompressed}
+	if loc := rotationSuffix.FindStringIndex(uncompressed); loc != nil && loc[0] > 0 {
+		candidates = append(candidates, uncompressed[:loc[0]])
+	}
+	return candidates
+}
+
+// rotatedOffset returns the offset the file the archive was rotated from had been read up to. The
+// checkpoint of the file is only trusted when its fingerprint matches the start of the archive.
+func (t *LogFile) rotatedOffset(filename string) (string, int64, bool) {
+	s := t.checkpointStore()
+	if s == nil {
+		return "", 0, false
+	}
+	checkpoints, err := s.List()
+	if err != nil {
+		t.Log.Warnf("Unable to list the checkpoints for compressed file %v: %v", filename, err)
+		return "", 0, false
+	}
+	fingerprints := map[int64]string{}
+	for _, candidate := range rotatedFileCandidates(filename) {
+		stateKey := t.getStateKey(candidate)
+		for _, c := range checkpoints {
+			if c.Fingerprint == "" || (c.Key != stateKey && c.Key != rotatedStateKey(stateKey, c.Fingerprint)) {
+				continue
+			}
+			fp, ok := fingerprints[c.FingerprintSize]
+			if !ok {
+				fp = compressedFileFingerprint(filename, c.FingerprintSize)
+				fingerprints[c.FingerprintSize] = fp
+			}
+			if fp == c.Fingerprint {
+				return c.Key, c.Offset, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// compressedFileFingerprint returns the fingerprint of the first n decompressed bytes of the archive,
+// or an empty string when the archive holds less than n bytes.
+func compressedFileFingerprint(filename string, n int64) string {
+	r, err := openCompressedFile(filename)
+	if err != nil {
+		return ""
+	}
+	defer r.Close()
+	fp, size, err := fingerprint(r, n)
+	if err != nil || size != n {
+		return ""
+	}
+	return fp
+}
+
+// newCompressedFileSrc creates a source reading the decompressed content of the archive once.
+// It returns nil when the content of the archive has already been read.
+func (t *LogFile) newCompressedFileSrc(fileconfig *FileConfig, filename string) *tailerSrc {
+	hash, err := t.compressedFileHash(filename)
+	if err != nil {
+		t.Log.Errorf("Failed to hash compressed file %v with error: %v", filename, err)
+		return nil
+	}
+	t.compressedMu.Lock()
+	_, found := t.compressedHashes[hash]
+	if !found {
+		t.compressedHashes[hash] = false
+	}
+	t.compressedMu.Unlock()
+	if found {
+		return nil
+	}
+
+	stateKey := t.getCompressedStateKey(hash)
+	var seekFile *tail.SeekInfo
+	if offset, err := t.restoreCompressedState(stateKey, filename); err == nil {
+		seekFile = &tail.SeekInfo{Whence: io.SeekStart, Offset: offset}
+	} else if rotatedKey, offset, ok := t.rotatedOffset(filename); ok {
+		// the content of the file had been read before its rotation
+		t.Log.Infof("Reading compressed file %v from offset %d of the rotated file", filename, offset)
+		seekFile = &tail.SeekInfo{Whence: io.SeekStart, Offset: offset}
+		t.seedCompressedState(stateKey, rotatedKey, filename, offset)
+	}
+
+	r, err := openCompressedFile(filename)
+	if err != nil {
+		t.Log.Errorf("Failed to open compressed file %v with error: %v", filename, err)
+		t.setCompressedFileRead(hash, false)
+		return nil
+	}
+	t.Log.Infof("Reading compressed file %v", filename)
+	tailer := tail.TailReader(filename, r, tail.Config{
+		Location:    seekFile,
+		MaxLineSize: fileconfig.MaxEventSize,
+		IsUTF16:     fileconfig.isUTF16(),
+	})
+	src := t.newTailerSrc(fileconfig, filename, stateKey, tailer)
+	src.AddCleanUpFn(func() {
+		select {
+		case <-src.done:
+			// stopped before the end of the archive
+			t.setCompressedFileRead(hash, false)
+		default:
+			// the lines are closed before the reader records its error
+			<-tailer.Dead()
+			if err := tailer.Err(); err != nil {
+				t.Log.Warnf("Failed to read compressed file %v, it is read again at the next search: %v", filename, err)
+				t.setCompressedFileRead(hash, false)
+				return
+			}
+			t.setCompressedFileRead(hash, true)
+		}
+	})
+	return src
+}
+
+// seedCompressedState moves the offset of the rotated file to the checkpoint of its archive.
+func (t *LogFile) seedCompressedState(stateKey string, rotatedKey string, filename string, offset int64) {
+	s := t.checkpointStore()
+	if err := s.Set(checkpoint.Checkpoint{Key: stateKey, File: filename, Offset: offset}); err != nil {
+		t.Log.Warnf("Unable to save checkpoint %s of compressed file %s: %v", stateKey, filename, err)
+		return
+	}
+	if strings.HasPrefix(rotatedKey, rotatedStateFilePrefix) {
+		if err := s.Delete(rotatedKey); err != nil {
+			t.Log.Warnf("Unable to delete checkpoint %s of rotated file: %v", rotatedKey, err)
+		}
+	}
+}
+
+// setCompressedFileRead marks the archive of the hash as read, or forgets it so it is read again.
+func (t *LogFile) setCompressedFileRead(hash string, read bool) {
+	t.compressedMu.Lock()
+	defer t.compressedMu.Unlock()
+	if read {
+		t.compressedHashes[hash] = true
+	} else {
+		delete(t.compressedHashes, hash)
+	}
+}
+
+// pruneCompressedFiles forgets the archives which no longer match any file config, so the cached
+// hashes do not grow with every rotation. The archives being read are kept.
+func (t *LogFile) pruneCompressedFiles(matched map[string]bool) {
+	hashes := map[string]bool{}
+	for filename, info := range t.compressedFiles {
+		if matched[filename] {
+			hashes[info.hash] = true
+		} else {
+			delete(t.compressedFiles, filename)
+		}
+	}
+	t.compressedMu.Lock()
+	defer t.compressedMu.Unlock()
+	for hash, read := range t.compressedHashes {
+		if read && !hashes[hash] {
+			delete(t.compressedHashes, hash)
+		}
+	}
+}
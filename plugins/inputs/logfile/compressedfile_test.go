@@ -0,0 +1,61 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenCompressedFile(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "app.log.zst")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw, err := zstd.NewWriter(f)
+	require.NoError(t, err)
+	_, err = zw.Write([]byte("line1\nline2\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	r, err := openCompressedFile(archive)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "line1\nline2\n", string(content))
+
+	_, err = openCompressedFile(filepath.Join(dir, "app.log.zip"))
+	assert.Error(t, err)
+}
+
+func TestIsReadableCompressedFile(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "app.log.gz")
+	require.NoError(t, os.WriteFile(archive, nil, 0600))
+	info, err := os.Stat(archive)
+	require.NoError(t, err)
+	assert.False(t, isReadableCompressedFile(archive, info), "the archive may still be written")
+
+	settled := time.Now().Add(-compressedFileSettleTime)
+	require.NoError(t, os.Chtimes(archive, settled, settled))
+	info, err = os.Stat(archive)
+	require.NoError(t, err)
+	assert.True(t, isReadableCompressedFile(archive, info))
+	assert.False(t, isReadableCompressedFile("app.log.zip", info))
+	assert.False(t, isReadableCompressedFile("app.log.tar", info))
+
+	old := time.Now().Add(-15 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(archive, old, old))
+	info, err = os.Stat(archive)
+	require.NoError(t, err)
+	assert.False(t, isReadableCompressedFile(archive, info))
+}
@@ -0,0 +1,179 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"gopkg.in/fsnotify.v1"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail/watch"
+)
+
+// dirWatcher watches the directories which can contain the files of the file configs with inotify,
+// and notifies when an entry is created or moved into one of them, so the target files are only
+// searched again when they may have changed.
+type dirWatcher struct {
+	watcher *fsnotify.Watcher
+	notify  func()
+	log     telegraf.Logger
+
+	mu sync.Mutex
+	// depths are the depths of the subdirectories to watch below each watched directory, -1 for all.
+	depths map[string]int
+}
+
+func newDirWatcher(notify func(), log telegraf.Logger) (*dirWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &dirWatcher{watcher: watcher, notify: notify, log: log, depths: map[string]int{}}, nil
+}
+
+// add watches the root directory and its subdirectories down to depth, -1 for all of them.
+func (w *dirWatcher) add(root string, depth int) error {
+	if err := watch.InotifySupported(root); err != nil {
+		return err
+	}
+	return w.addDir(root, depth)
+}
+
+func (w *dirWatcher) addDir(dir string, depth int) error {
+	w.mu.Lock()
+	watched, ok := w.depths[dir]
+	if ok && !deeper(depth, watched) {
+		w.mu.Unlock()
+		return nil
+	}
+	if !ok {
+		// the number of watches is limited by fs.inotify.max_user_watches
+		if err := w.watcher.Add(dir); err != nil {
+			w.mu.Unlock()
+			return err
+		}
+	}
+	w.mu.Unlock()
+
+	// the depth is only recorded once the subdirectories are watched, so the directory is walked
+	// again by the next search when they cannot be
+	if err := w.addSubDirs(dir, depth); err != nil {
+		if !ok {
+			w.watcher.Remove(dir)
+		}
+		return err
+	}
+	w.mu.Lock()
+	w.depths[dir] = depth
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *dirWatcher) addSubDirs(dir string, depth int) error {
+	if depth == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	// the symbolic links are not followed, like the search of the target files
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		subDir := filepath.Join(dir, entry.Name())
+		if err = w.addDir(subDir, subDepth(depth)); err != nil {
+			// the unreadable or removed subdirectories are skipped, like by the search of the target files
+			if errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist) {
+				w.log.Debugf("Skipping the log directory %s which cannot be watched: %v", subDir, err)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// remove forgets the directory and its subdirectories once it is deleted or moved away.
+func (w *dirWatcher) remove(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.depths[dir]; !ok {
+		return
+	}
+	prefix := dir + string(os.PathSeparator)
+	for d := range w.depths {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			// inotify already removed the watch of the deleted directories
+			w.watcher.Remove(d)
+			delete(w.depths, d)
+		}
+	}
+}
+
+func (w *dirWatcher) run(done <-chan struct{}) {
+	defer w.watcher.Close()
+	for {
+		select {
+		case evt, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.handle(evt)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			// the events are lost when the queue overflows, so the files are searched again
+			w.log.Warnf("Error watching the log directories: %v", err)
+			w.notify()
+		case <-done:
+			return
+		}
+	}
+}
+
+func (w *dirWatcher) handle(evt fsnotify.Event) {
+	name := filepath.Clean(evt.Name)
+	if evt.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		w.remove(name)
+	}
+	// the files moved into a directory are reported as created
+	if evt.Op&fsnotify.Create == 0 {
+		return
+	}
+	if info, err := os.Lstat(name); err == nil && info.IsDir() {
+		w.mu.Lock()
+		parentDepth, ok := w.depths[filepath.Dir(name)]
+		w.mu.Unlock()
+		if ok && parentDepth != 0 {
+			if err = w.addDir(name, subDepth(parentDepth)); err != nil {
+				w.log.Warnf("Unable to watch the log directory %s, its files are found by the periodic search: %v", name, err)
+			}
+		}
+	}
+	w.notify()
+}
+
+// deeper returns true when the depth a covers more subdirectories than the depth b.
+func deeper(a, b int) bool {
+	if b < 0 {
+		return false
+	}
+	return a < 0 || a > b
+}
+
+func subDepth(depth int) int {
+	if depth < 0 {
+		return depth
+	}
+	return depth - 1
+}
@@ -0,0 +1,152 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+// +build linux
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitNotified(t *testing.T, notifyCh chan struct{}, expected bool) {
+	t.Helper()
+	select {
+	case <-notifyCh:
+		assert.True(t, expected, "unexpected notification")
+	case <-time.After(500 * time.Millisecond):
+		assert.False(t, expected, "no notification")
+	}
+}
+
+func TestDirWatcher(t *testing.T) {
+	notifyCh := make(chan struct{}, 1)
+	w, err := newDirWatcher(func() {
+		select {
+		case notifyCh <- struct{}{}:
+		default:
+		}
+	}, TestLogger{t})
+	require.NoError(t, err)
+	done := make(chan struct{})
+	defer close(done)
+	go w.run(done)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "existing"), 0755))
+	require.NoError(t, w.add(root, 1))
+	w.mu.Lock()
+	assert.Equal(t, map[string]int{root: 1, filepath.Join(root, "existing"): 0}, w.depths)
+	w.mu.Unlock()
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "existing", "app.log"), nil, 0644))
+	waitNotified(t, notifyCh, true)
+
+	// the new subdirectories are watched down to the depth of the glob
+	app := filepath.Join(root, "app")
+	require.NoError(t, os.Mkdir(app, 0755))
+	waitNotified(t, notifyCh, true)
+	require.NoError(t, os.Mkdir(filepath.Join(app, "nested"), 0755))
+	waitNotified(t, notifyCh, true)
+	require.NoError(t, os.WriteFile(filepath.Join(app, "nested", "app.log"), nil, 0644))
+	waitNotified(t, notifyCh, false)
+
+	// the files written or removed do not need a search
+	require.NoError(t, os.WriteFile(filepath.Join(app, "app.log"), []byte("line\n"), 0644))
+	waitNotified(t, notifyCh, true)
+	require.NoError(t, os.WriteFile(filepath.Join(app, "app.log"), []byte("line\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(app, "app.log")))
+	waitNotified(t, notifyCh, false)
+
+	require.NoError(t, os.RemoveAll(app))
+	waitNotified(t, notifyCh, false)
+	w.mu.Lock()
+	assert.Equal(t, map[string]int{root: 1, filepath.Join(root, "existing"): 0}, w.depths)
+	w.mu.Unlock()
+
+	// a directory watched with a larger depth again is walked again
+	require.NoError(t, w.add(root, -1))
+	w.mu.Lock()
+	assert.Equal(t, map[string]int{root: -1, filepath.Join(root, "existing"): -1}, w.depths)
+	w.mu.Unlock()
+}
+
+func TestDirWatcherRootRecreated(t *testing.T) {
+	notifyCh := make(chan struct{}, 1)
+	w, err := newDirWatcher(func() {
+		select {
+		case notifyCh <- struct{}{}:
+		default:
+		}
+	}, TestLogger{t})
+	require.NoError(t, err)
+	done := make(chan struct{})
+	defer close(done)
+	go w.run(done)
+
+	root := filepath.Join(t.TempDir(), "logs")
+	require.NoError(t, os.Mkdir(root, 0755))
+	require.NoError(t, w.add(root, 0))
+
+	// the removed root directory is forgotten, so it is watched again once created again
+	require.NoError(t, os.RemoveAll(root))
+	require.Eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return len(w.depths) == 0
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, os.Mkdir(root, 0755))
+	require.NoError(t, w.add(root, 0))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app.log"), nil, 0644))
+	waitNotified(t, notifyCh, true)
+}
+
+func TestDirWatcherUnreadableSubDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("the permissions of the directories are not enforced for root")
+	}
+	w, err := newDirWatcher(func() {}, TestLogger{t})
+	require.NoError(t, err)
+	defer w.watcher.Close()
+
+	root := t.TempDir()
+	readable := filepath.Join(root, "readable")
+	unreadable := filepath.Join(root, "unreadable")
+	require.NoError(t, os.Mkdir(readable, 0755))
+	require.NoError(t, os.Mkdir(unreadable, 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(unreadable, "nested"), 0755))
+	require.NoError(t, os.Chmod(unreadable, 0))
+	defer os.Chmod(unreadable, 0755)
+
+	// the unreadable subdirectory is skipped, the other directories are still watched
+	require.NoError(t, w.add(root, -1))
+	w.mu.Lock()
+	assert.Equal(t, map[string]int{root: -1, readable: -1}, w.depths)
+	w.mu.Unlock()
+
+	// the root directory which cannot be walked is not recorded, so it is walked again by the next search
+	require.NoError(t, os.Chmod(root, 0300))
+	defer os.Chmod(root, 0755)
+	w, err = newDirWatcher(func() {}, TestLogger{t})
+	require.NoError(t, err)
+	defer w.watcher.Close()
+	assert.Error(t, w.add(root, -1))
+	w.mu.Lock()
+	assert.Empty(t, w.depths)
+	w.mu.Unlock()
+}
+
+func TestDeeper(t *testing.T) {
+	assert.True(t, deeper(1, 0))
+	assert.True(t, deeper(-1, 3))
+	assert.False(t, deeper(0, 0))
+	assert.False(t, deeper(2, -1))
+	assert.False(t, deeper(-1, -1))
+}
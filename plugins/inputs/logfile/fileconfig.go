@@ -16,6 +16,7 @@ import (
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/ianaindex"
 
+	"github.com/aws/amazon-cloudwatch-agent/internal/redact"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/profiler"
 )
@@ -66,12 +67,19 @@ type FileConfig struct {
 	//Indicate whether it is a named pipe.
 	Pipe bool `toml:"pipe"`
 
+	//Indicate whether rotated compressed files matching the file path are read once instead of being skipped.
+	ReadCompressedFiles bool `toml:"read_compressed_files"`
+
 	//Indicate logType for scroll
 	LogType string `toml:"log_type"`
 
 	//Log Destination override
 	Destination string `toml:"destination"`
 
+	//Log Destinations every event of the file is published to, the offset of the file only advances
+	//once all of them have accepted the event. Overrides Destination when set.
+	Destinations []string `toml:"destinations"`
+
 	//Max size for a single log event to be in bytes
 	MaxEventSize int `toml:"max_event_size"`
 
@@ -83,6 +91,23 @@ type FileConfig struct {
 
 	Filters []*LogFilter `toml:"filters"`
 
+	//Parsers extracting structured fields from the log event, applied in order.
+	Parsers []*LogParser `toml:"parsers"`
+	//The parsed field promoted to the log event timestamp, parsed with the timestamp layout.
+	TimestampField string `toml:"timestamp_field"`
+	//The parsed field promoted to the log event level.
+	LevelField string `toml:"level_field"`
+	//Indicate whether the log event is published as the JSON rendering of its parsed fields.
+	RenderJSON bool `toml:"render_json"`
+
+	//Redactions applied to the log event before it is published.
+	Redactions []*redact.Rule `toml:"redactions"`
+	//The HMAC key of the redactions with the hash action.
+	RedactionHashKey string `toml:"redaction_hash_key"`
+
+	//Metrics extracted from the log events and published through the metrics pipeline.
+	LogMetrics []*LogMetric `toml:"log_metrics"`
+
 	//Customer specified service.name
 	ServiceName string `toml:"service_name"`
 	//Customer specified deployment.environment
@@ -99,6 +124,14 @@ type FileConfig struct {
 	//Decoder object
 	Enc         encoding.Encoding
 	sampleCount int
+	//Pipeline of the parsers, nil when no parser is configured
+	parserPipeline *ParserPipeline
+	//Redactor of the redactions, nil when no redaction is configured
+	redactor *redact.Redactor
+	//Recorder of the log metrics, nil when no log metric is configured
+	logMetrics *logMetricRecorder
+	//The last error watching the directories of the file path in inotify watch mode
+	dirsWatchErr error
 }
 
 // Initialize some variables in the FileConfig object based on the rest info fetched from the configuration file.
@@ -165,6 +198,34 @@ func (config *FileConfig) init() error {
 		}
 	}
 
+	for _, p := range config.Parsers {
+		if err = p.init(); err != nil {
+			return err
+		}
+	}
+	if config.redactor, err = redact.New(config.Redactions, config.RedactionHashKey); err != nil {
+		return err
+	}
+	for _, m := range config.LogMetrics {
+		if err = m.init(); err != nil {
+			return err
+		}
+	}
+	if len(config.LogMetrics) > 0 {
+		config.logMetrics = newLogMetricRecorder(config.LogMetrics)
+	}
+
+	if len(config.Parsers) > 0 {
+		config.parserPipeline = &ParserPipeline{
+			parsers:         config.Parsers,
+			timestampField:  config.TimestampField,
+			timestampLayout: config.TimestampLayout,
+			timezone:        config.TimezoneLoc,
+			levelField:      config.LevelField,
+			renderJSON:      config.RenderJSON,
+		}
+	}
+
 	return nil
 }
 
@@ -213,6 +274,10 @@ func (config *FileConfig) timestampFromLogLine(logValue string) time.Time {
 	return time.Time{}
 }
 
+func (config *FileConfig) isUTF16() bool {
+	return config.Encoding == "utf-16" || config.Encoding == "utf-16le" || config.Encoding == "UTF-16" || config.Encoding == "UTF-16LE"
+}
+
 // This method determine whether the line is a start line for multiline log entry.
 func (config *FileConfig) isMultilineStart(logValue string) bool {
 
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/aws/amazon-cloudwatch-agent/internal/redact"
 	"github.com/aws/amazon-cloudwatch-agent/tool/util"
 )
 
@@ -308,6 +309,58 @@ func TestFileConfigInitWithFiltersFails(t *testing.T) {
 	assert.Equal(t, "filter regex has issue, regexp: Compile( StatusCode: ([4-5]\\d\\d ): error parsing regexp: missing closing ): `StatusCode: ([4-5]\\d\\d`", err.Error())
 }
 
+func TestFileConfigInitWithParsers(t *testing.T) {
+	fileConfig := &FileConfig{
+		FilePath: "/tmp/logfile.log",
+		Parsers: []*LogParser{
+			{Type: regexParserType, Expression: "^(?P<time>\\S+) (?P<payload>.*)$"},
+			{Type: jsonParserType, Source: "payload"},
+		},
+		TimestampField: "time",
+		LevelField:     "severity",
+		RenderJSON:     true,
+	}
+
+	err := fileConfig.init()
+	assert.NoError(t, err)
+	assert.NotNil(t, fileConfig.Parsers[0].expressionP)
+	assert.NotNil(t, fileConfig.parserPipeline)
+	assert.Equal(t, "time", fileConfig.parserPipeline.timestampField)
+	assert.Equal(t, "severity", fileConfig.parserPipeline.levelField)
+	assert.True(t, fileConfig.parserPipeline.renderJSON)
+
+	fileConfig = &FileConfig{FilePath: "/tmp/logfile.log"}
+	assert.NoError(t, fileConfig.init())
+	assert.Nil(t, fileConfig.parserPipeline)
+}
+
+func TestFileConfigInitWithParsersFails(t *testing.T) {
+	fileConfig := &FileConfig{
+		FilePath: "/tmp/logfile.log",
+		Parsers:  []*LogParser{{Type: "xml"}},
+	}
+
+	err := fileConfig.init()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "parser type xml is incorrect")
+}
+
+func TestFileConfigInitWithRedactions(t *testing.T) {
+	fileConfig := &FileConfig{
+		FilePath:         "/tmp/logfile.log",
+		Redactions:       []*redact.Rule{{Name: redact.EmailDetector, Action: redact.HashAction}},
+		RedactionHashKey: "key",
+	}
+	assert.NoError(t, fileConfig.init())
+	assert.NotNil(t, fileConfig.redactor)
+
+	fileConfig = &FileConfig{
+		FilePath:   "/tmp/logfile.log",
+		Redactions: []*redact.Rule{{Name: redact.EmailDetector, Action: redact.HashAction}},
+	}
+	assert.Error(t, fileConfig.init())
+}
+
 func TestLogEmptyFilters(t *testing.T) {
 	assertPublishedForFilters(t, []*LogFilter{}, "foo")
 	assertPublishedForFilters(t, []*LogFilter{}, "Some other log message")
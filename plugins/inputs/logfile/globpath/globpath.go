@@ -77,6 +77,24 @@ func (g *GlobPath) Match() map[string]os.FileInfo {
 	return walkFilePath(g.root, g.g)
 }
 
+// Dirs returns the root directory of the files the path can match, and the depth of the
+// subdirectories of the root which can contain them, -1 when any subdirectory can.
+// ie:
+//
+//	/var/log/telegraf.log -> /var/log, 0
+//	/var/log/*/*.log ->      /var/log, 1
+//	/var/log/**.log ->       /var/log, -1
+func (g *GlobPath) Dirs() (string, int) {
+	if !g.hasMeta && !g.hasSuperMeta {
+		return filepath.Dir(g.path), 0
+	}
+	if g.hasSuperMeta {
+		return g.root, -1
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(g.path, g.root), sepStr)
+	return g.root, strings.Count(rel, sepStr)
+}
+
 // walk the filepath from the given root and return a list of files that match
 // the given glob.
 func walkFilePath(root string, g glob.Glob) map[string]os.FileInfo {
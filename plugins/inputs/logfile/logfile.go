@@ -11,12 +11,15 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	"github.com/aws/amazon-cloudwatch-agent/extension/entitystore"
+	"github.com/aws/amazon-cloudwatch-agent/internal/checkpoint"
 	"github.com/aws/amazon-cloudwatch-agent/internal/logscommon"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/globpath"
@@ -30,23 +33,48 @@ type LogFile struct {
 	FileStateFolder string `toml:"file_state_folder"`
 	//destination
 	Destination string `toml:"destination"`
+	//how the changes of the files are detected, poll (default) or inotify
+	WatchMode string `toml:"watch_mode"`
 
 	Log telegraf.Logger `toml:"-"`
 
 	configs           map[*FileConfig]map[string]*tailerSrc
+	compressedFiles   map[string]compressedFileInfo
+	compressedHashes  map[string]bool // false while the archive is read, true once it has been read
+	compressedMu      sync.Mutex
 	done              chan struct{}
 	removeTailerSrcCh chan *tailerSrc
+	checkpoints       *checkpoint.Store
+	checkpointsOnce   sync.Once
 	started           bool
+	startOnce         sync.Once
+	startErr          error
+	// dirWatcher is only set in inotify watch mode, the log agent then searches the target files
+	// when notified on notifyCh instead of every second
+	dirWatcher    *dirWatcher
+	notifyCh      chan struct{}
+	unwatchedDirs atomic.Bool
 }
 
 func NewLogFile() *LogFile {
 	return &LogFile{
 		configs:           make(map[*FileConfig]map[string]*tailerSrc),
+		compressedFiles:   make(map[string]compressedFileInfo),
+		compressedHashes:  make(map[string]bool),
 		done:              make(chan struct{}),
 		removeTailerSrcCh: make(chan *tailerSrc, 100),
+		notifyCh:          make(chan struct{}, 1),
 	}
 }
 
+const (
+	watchModePoll    = "poll"
+	watchModeInotify = "inotify"
+	// The target files are searched again periodically in inotify watch mode, in case the events of
+	// the directories were lost
+	inotifyRescanInterval = time.Minute
+)
+
 const sampleConfig = `
   ## log files to tail.
   ## These accept standard unix glob matching rules, but with the addition of
@@ -64,6 +92,11 @@ const sampleConfig = `
   ## folder path where state of how much of a file has been transferred is stored
   file_state_folder = "/tmp/logfile/state"
 
+  ## How the new files and the changes of the files are detected, poll (default) or inotify.
+  ## In inotify mode the files on network filesystems, and the files which cannot be watched
+  ## because of the fs.inotify.max_user_watches limit, are polled.
+  watch_mode = "poll"
+
   [[inputs.logs.file_config]]
       file_path = "/tmp/logfile.log*"
       ## Regular expression for log files to ignore
@@ -86,6 +119,44 @@ const sampleConfig = `
       max_event_size = 262144
       ## Suffix to be added to truncated logline to indicate its truncation, defaults to "[Truncated...]"
       truncate_suffix = "[Truncated...]"
+      ## Parsed field promoted to the log event timestamp, parsed with timestamp_layout
+      timestamp_field = "time"
+      ## Parsed field promoted to the log event level
+      level_field = "severity"
+      ## Publish the parsed fields as a JSON log event
+      render_json = false
+      ## HMAC key of the redactions with the hash action
+      redaction_hash_key = "<redaction_hash_key>"
+      ## Read rotated .gz, .zst and .bz2 archives matching file_path once instead of skipping them
+      read_compressed_files = false
+      ## Parsers extracting structured fields, applied in order. Valid types are
+      ## json, logfmt, regex, syslog_rfc5424, apache_combined and nginx_combined.
+      [[inputs.logs.file_config.parsers]]
+          type = "regex"
+          expression = "^(?P<time>\\S+) (?P<severity>\\w+) (?P<payload>.*)$"
+      [[inputs.logs.file_config.parsers]]
+          type = "json"
+          ## Parse a field extracted by a previous parser instead of the message
+          source = "payload"
+      ## Redactions applied before the log event is published. The built-in detectors are
+      ## credit_card, aws_access_key, aws_secret_key, email and bearer_token, custom ones are
+      ## defined by a name and an expression. Valid actions are mask (default), hash and drop.
+      [[inputs.logs.file_config.redactions]]
+          name = "email"
+          action = "hash"
+      [[inputs.logs.file_config.redactions]]
+          name = "password"
+          expression = "password=(?P<secret>\\S+)"
+      ## Metrics extracted from the log events, published through the metrics pipeline. The
+      ## value and dimensions are named capture groups of the expression or parsed fields,
+      ## the log events matching the expression are counted when there is no value.
+      [[inputs.logs.file_config.log_metrics]]
+          name = "http_errors"
+          expression = "status=(?P<status>5\\d\\d)"
+          dimensions = ["status"]
+      [[inputs.logs.file_config.log_metrics]]
+          name = "request_latency"
+          value = "latency_ms"
 
 `
 
@@ -97,26 +168,49 @@ func (t *LogFile) Description() string {
 	return "Stream a log file, like the tail -f command"
 }
 
+// Gather publishes the metrics extracted from the log events since the last collection.
 func (t *LogFile) Gather(acc telegraf.Accumulator) error {
+	for i := range t.FileConfig {
+		fileconfig := &t.FileConfig[i]
+		if fileconfig.logMetrics == nil {
+			continue
+		}
+		if dropped := fileconfig.logMetrics.flush(acc); dropped > 0 {
+			t.Log.Warnf("Dropped %d log metric values of %s, more than %d values were extracted between collections", dropped, fileconfig.FilePath, maxPendingLogMetricValues)
+		}
+	}
 	return nil
 }
 
+// Start is called by the log agent, and by the metrics pipeline when log metrics are configured,
+// the plugin is only started once.
 func (t *LogFile) Start(acc telegraf.Accumulator) error {
+	t.startOnce.Do(func() {
+		t.startErr = t.start()
+	})
+	return t.startErr
+}
+
+func (t *LogFile) start() error {
+	if t.WatchMode != "" && t.WatchMode != watchModePoll && t.WatchMode != watchModeInotify {
+		return fmt.Errorf("invalid watch_mode %q, should be %s or %s", t.WatchMode, watchModePoll, watchModeInotify)
+	}
+
 	// Create the log file state folder.
 	err := os.MkdirAll(t.FileStateFolder, 0755)
 	if err != nil {
 		return fmt.Errorf("failed to create state file directory %s: %v", t.FileStateFolder, err)
 	}
 
-	// Clean state file on init and regularly
+	// Clean checkpoints on init and regularly
 	go func() {
-		t.cleanupStateFolder()
+		t.cleanupCheckpoints()
 		ticker := time.NewTicker(1 * time.Hour)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				t.cleanupStateFolder()
+				t.cleanupCheckpoints()
 			case <-t.done:
 				t.Log.Debugf("Cleanup state folder routine received shutdown signal, stopping.")
 				return
@@ -131,6 +225,18 @@ func (t *LogFile) Start(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if t.WatchMode == watchModeInotify {
+		if dw, err := newDirWatcher(t.notify, t.Log); err != nil {
+			t.Log.Warnf("Polling the log files, unable to create the inotify watcher: %v", err)
+		} else {
+			t.dirWatcher = dw
+			go dw.run(t.done)
+			go t.rescanPeriodically()
+			// search the target files on start
+			t.notify()
+		}
+	}
+
 	t.started = true
 	t.Log.Infof("turned on logs plugin")
 	return nil
@@ -140,6 +246,66 @@ func (t *LogFile) Stop() {
 	// Tailer srcs are stopped by log agent after the output plugin is stopped instead of here
 	// because the tailersrc would like to record an accurate uploaded offset
 	close(t.done)
+	if t.checkpoints != nil {
+		if err := t.checkpoints.Close(); err != nil {
+			t.Log.Errorf("Unable to close the checkpoint store: %v", err)
+		}
+	}
+}
+
+// Notify returns the channel notified when new files may need to be monitored in inotify watch mode.
+func (t *LogFile) Notify() <-chan struct{} {
+	if t.dirWatcher == nil {
+		return nil
+	}
+	return t.notifyCh
+}
+
+func (t *LogFile) notify() {
+	select {
+	case t.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// rescanPeriodically notifies every second while the directories of a file config are not watched,
+// and every inotifyRescanInterval otherwise.
+func (t *LogFile) rescanPeriodically() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	lastRescan := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			if t.unwatchedDirs.Load() || now.Sub(lastRescan) >= inotifyRescanInterval {
+				t.notify()
+				lastRescan = now
+			}
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// watchDirs watches the directories which can contain the files of the file config, and returns
+// false when they cannot be watched. It is called on every search, so a root directory removed
+// and created again is watched again.
+func (t *LogFile) watchDirs(fileconfig *FileConfig) bool {
+	g, err := globpath.Compile(fileconfig.FilePath)
+	if err != nil {
+		return false
+	}
+	root, depth := g.Dirs()
+	if err = t.dirWatcher.add(root, depth); err != nil {
+		// only log the error once, the directory is retried on every search
+		if fileconfig.dirsWatchErr == nil || fileconfig.dirsWatchErr.Error() != err.Error() {
+			t.Log.Warnf("Searching the files of %s every second, unable to watch %s: %v", fileconfig.FilePath, root, err)
+		}
+		fileconfig.dirsWatchErr = err
+		return false
+	}
+	fileconfig.dirsWatchErr = nil
+	return true
 }
 
 // Try to find if there is any new file needs to be added for monitoring.
@@ -155,16 +321,23 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 
 	es := entitystore.GetEntityStore()
 
+	unwatchedDirs := false
+	matchedCompressedFiles := map[string]bool{}
 	// Create a "tailer" for each file
 	for i := range t.FileConfig {
 		fileconfig := &t.FileConfig[i]
 
+		// Watch the directories before the search, so the files created in between are not missed
+		if t.dirWatcher != nil && !t.watchDirs(fileconfig) {
+			unwatchedDirs = true
+		}
+
 		//Add file -> {serviceName,  deploymentEnvironment} mapping to entity store
 		if es != nil {
 			es.AddServiceAttrEntryForLogFile(entitystore.LogFileGlob(fileconfig.FilePath), fileconfig.ServiceName, fileconfig.Environment)
 		}
 
-		targetFiles, err := t.getTargetFiles(fileconfig)
+		targetFiles, compressedFiles, err := t.getTargetFiles(fileconfig)
 		if err != nil {
 			t.Log.Errorf("Failed to find target files for file config %v, with error: %v", fileconfig.FilePath, err)
 		}
@@ -193,11 +366,6 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				seekFile = &tail.SeekInfo{Whence: io.SeekEnd, Offset: 0}
 			}
 
-			isutf16 := false
-			if fileconfig.Encoding == "utf-16" || fileconfig.Encoding == "utf-16le" || fileconfig.Encoding == "UTF-16" || fileconfig.Encoding == "UTF-16LE" {
-				isutf16 = true
-			}
-
 			tailer, err := tail.TailFile(filename,
 				tail.Config{
 					ReOpen:      false,
@@ -205,9 +373,9 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 					Location:    seekFile,
 					MustExist:   true,
 					Pipe:        fileconfig.Pipe,
-					Poll:        true,
+					Poll:        t.dirWatcher == nil,
 					MaxLineSize: fileconfig.MaxEventSize,
-					IsUTF16:     isutf16,
+					IsUTF16:     fileconfig.isUTF16(),
 				})
 
 			if err != nil {
@@ -215,74 +383,102 @@ func (t *LogFile) FindLogSrc() []logs.LogSrc {
 				continue
 			}
 
-			var mlCheck func(string) bool
-			if fileconfig.MultiLineStartPattern != "" {
-				mlCheck = fileconfig.isMultilineStart
-			}
-
-			groupName := fileconfig.LogGroupName
-			streamName := fileconfig.LogStreamName
+			src := t.newTailerSrc(fileconfig, filename, t.getStateKey(filename), tailer)
+			srcs = append(srcs, src)
 
-			// In case of multilog, the group and stream has to be generated here
-			// since it is based on the actual file name
-			if fileconfig.PublishMultiLogs {
-				if groupName == "" {
-					groupName = generateLogGroupName(filename)
-				} else {
-					streamName = generateLogStreamName(filename, fileconfig.LogStreamName)
-				}
-			}
+			dests[filename] = src
+		}
 
-			destination := fileconfig.Destination
-			if destination == "" {
-				destination = t.Destination
+		for _, filename := range compressedFiles {
+			matchedCompressedFiles[filename] = true
+			if src := t.newCompressedFileSrc(fileconfig, filename); src != nil {
+				srcs = append(srcs, src)
 			}
+		}
+	}
+	t.unwatchedDirs.Store(unwatchedDirs)
+	t.pruneCompressedFiles(matchedCompressedFiles)
 
-			src := NewTailerSrc(
-				groupName, streamName,
-				t.Destination,
-				t.getStateFilePath(filename),
-				fileconfig.LogGroupClass,
-				fileconfig.FilePath,
-				tailer,
-				fileconfig.AutoRemoval,
-				mlCheck,
-				fileconfig.Filters,
-				fileconfig.timestampFromLogLine,
-				fileconfig.Enc,
-				fileconfig.MaxEventSize,
-				fileconfig.TruncateSuffix,
-				fileconfig.RetentionInDays,
-			)
-
-			src.AddCleanUpFn(func(ts *tailerSrc) func() {
-				return func() {
-					select {
-					case <-t.done: // No clean up needed after input plugin is stopped
-					case t.removeTailerSrcCh <- ts:
-					}
+	return srcs
+}
 
-				}
-			}(src))
+func (t *LogFile) newTailerSrc(fileconfig *FileConfig, filename, stateKey string, tailer *tail.Tail) *tailerSrc {
+	var mlCheck func(string) bool
+	if fileconfig.MultiLineStartPattern != "" {
+		mlCheck = fileconfig.isMultilineStart
+	}
 
-			srcs = append(srcs, src)
+	groupName := fileconfig.LogGroupName
+	streamName := fileconfig.LogStreamName
 
-			dests[filename] = src
+	// In case of multilog, the group and stream has to be generated here
+	// since it is based on the actual file name
+	if fileconfig.PublishMultiLogs {
+		if groupName == "" {
+			groupName = generateLogGroupName(filename)
+		} else {
+			streamName = generateLogStreamName(filename, fileconfig.LogStreamName)
 		}
 	}
 
-	return srcs
+	destination := fileconfig.Destination
+	if len(fileconfig.Destinations) > 0 {
+		destination = fileconfig.Destinations[0]
+	}
+	if destination == "" {
+		destination = t.Destination
+	}
+
+	src := NewTailerSrc(
+		groupName, streamName,
+		destination,
+		stateKey,
+		fileconfig.LogGroupClass,
+		fileconfig.FilePath,
+		t.checkpointStore(),
+		tailer,
+		fileconfig.AutoRemoval,
+		mlCheck,
+		fileconfig.Filters,
+		fileconfig.parserPipeline,
+		fileconfig.redactor,
+		fileconfig.timestampFromLogLine,
+		fileconfig.Enc,
+		fileconfig.MaxEventSize,
+		fileconfig.TruncateSuffix,
+		fileconfig.RetentionInDays,
+	)
+	src.destinations = fileconfig.Destinations
+	src.logMetrics = fileconfig.logMetrics
+	src.keepRotatedState = fileconfig.ReadCompressedFiles && !isCompressedFile(filename)
+
+	src.AddCleanUpFn(func(ts *tailerSrc) func() {
+		return func() {
+			select {
+			case <-t.done: // No clean up needed after input plugin is stopped
+			case t.removeTailerSrcCh <- ts:
+				// the file may need to be tailed again
+				t.notify()
+			}
+
+		}
+	}(src))
+
+	return src
 }
 
-func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
+// getTargetFiles returns the files to tail for the file config, and the rotated archives to
+// read once when compressed files are enabled.
+func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, []string, error) {
 	filePath := fileconfig.FilePath
 	blacklistP := fileconfig.BlacklistRegexP
 	g, err := globpath.Compile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("file_path glob %s failed to compile, %s", filePath, err)
+		return nil, nil, fmt.Errorf("file_path glob %s failed to compile, %s", filePath, err)
 	}
 
 	var targetFileList []string
+	var compressedFileList []string
 	var targetFileName string
 	var targetModTime time.Time
 	for matchedFileName, matchedFileInfo := range g.Match() {
@@ -292,13 +488,9 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 			continue
 		}
 
-		if isCompressedFile(matchedFileName) {
-			continue
-		}
-
 		// If it's a dir or a symbolic link pointing to a dir, ignore it
 		if isDir, err := isDirectory(matchedFileName); err != nil {
-			return nil, fmt.Errorf("error tailing file %v with error: %v", matchedFileName, err)
+			return nil, nil, fmt.Errorf("error tailing file %v with error: %v", matchedFileName, err)
 		} else if isDir {
 			continue
 		}
@@ -308,6 +500,14 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 		if blacklistP != nil && blacklistP.MatchString(fileBaseName) {
 			continue
 		}
+
+		if isCompressedFile(matchedFileName) {
+			if fileconfig.ReadCompressedFiles && isReadableCompressedFile(matchedFileName, matchedFileInfo) {
+				compressedFileList = append(compressedFileList, matchedFileName)
+			}
+			continue
+		}
+
 		if !fileconfig.PublishMultiLogs {
 			if targetFileName == "" || matchedFileInfo.ModTime().After(targetModTime) {
 				targetFileName = matchedFileName
@@ -322,76 +522,138 @@ func (t *LogFile) getTargetFiles(fileconfig *FileConfig) ([]string, error) {
 		targetFileList = append(targetFileList, targetFileName)
 	}
 
-	return targetFileList, nil
+	return targetFileList, compressedFileList, nil
 }
 
-// The plugin will look at the state folder, and restore the offset of the file seeked if such state exists.
-func (t *LogFile) restoreState(filename string) (int64, error) {
-	filePath := t.getStateFilePath(filename)
+// checkpointStore opens the checkpoint store of the file state folder on first use, after migrating
+// the state files written by the previous versions of the agent into it.
+func (t *LogFile) checkpointStore() *checkpoint.Store {
+	t.checkpointsOnce.Do(func() {
+		if t.FileStateFolder == "" {
+			return
+		}
+		s, err := checkpoint.Open(filepath.Join(t.FileStateFolder, checkpoint.FileName))
+		if err != nil {
+			t.Log.Errorf("Unable to open the checkpoint store, file offsets will not be saved: %v", err)
+			return
+		}
+		if migrated, err := t.migrateStateFiles(s); err != nil {
+			t.Log.Errorf("Unable to migrate the state files of %s to the checkpoint store: %v", t.FileStateFolder, err)
+		} else if migrated > 0 {
+			t.Log.Infof("Migrated %d state files of %s to the checkpoint store", migrated, t.FileStateFolder)
+		}
+		t.checkpoints = s
+	})
+	return t.checkpoints
+}
 
-	if _, err := os.Stat(filePath); err != nil {
-		t.Log.Debugf("The state file %s for %s does not exist: %v", filePath, filename, err)
+// migrateStateFiles imports the plaintext state files, one per tailed file, into the checkpoint store
+// and removes them once the import has been committed. The state files which cannot be parsed are
+// left untouched.
+func (t *LogFile) migrateStateFiles(s *checkpoint.Store) (int, error) {
+	files, err := filepath.Glob(filepath.Join(t.FileStateFolder, "*"))
+	if err != nil {
 		return 0, err
 	}
-
-	byteArray, err := os.ReadFile(filePath)
-	if err != nil {
-		t.Log.Warnf("Issue encountered when reading offset from file %s: %v", filename, err)
+	var checkpoints []checkpoint.Checkpoint
+	var migrated []string
+	for _, file := range files {
+		name := filepath.Base(file)
+		if name == checkpoint.FileName || strings.Contains(name, logscommon.WindowsEventLogPrefix) {
+			continue
+		}
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		byteArray, err := os.ReadFile(file)
+		if err != nil {
+			t.Log.Warnf("Issue encountered when reading state file %s: %v", file, err)
+			continue
+		}
+		contentArray := strings.Split(string(byteArray), "\n")
+		offset, err := strconv.ParseInt(contentArray[0], 10, 64)
+		if err != nil || offset < 0 {
+			t.Log.Warnf("Skipping state file %s with invalid offset %q, it is kept on disk", file, contentArray[0])
+			continue
+		}
+		migrated = append(migrated, file)
+		c := checkpoint.Checkpoint{Key: name, Offset: offset}
+		if len(contentArray) >= 2 {
+			c.File = contentArray[1]
+		}
+		checkpoints = append(checkpoints, c)
+	}
+	if err = s.Import(checkpoints); err != nil {
 		return 0, err
 	}
+	for _, file := range migrated {
+		if err = os.Remove(file); err != nil {
+			t.Log.Warnf("Unable to remove migrated state file %s: %v", file, err)
+		}
+	}
+	return len(checkpoints), nil
+}
+
+// The plugin will look at the checkpoint store, and restore the offset of the file seeked if such checkpoint exists.
+func (t *LogFile) restoreState(filename string) (int64, error) {
+	return t.restoreCheckpoint(t.getStateKey(filename), filename)
+}
 
-	offset, err := strconv.ParseInt(strings.Split(string(byteArray), "\n")[0], 10, 64)
+func (t *LogFile) restoreCheckpoint(key string, filename string) (int64, error) {
+	s := t.checkpointStore()
+	if s == nil {
+		return 0, fmt.Errorf("no checkpoint store in file state folder %q", t.FileStateFolder)
+	}
+	c, ok, err := s.Get(key)
 	if err != nil {
-		t.Log.Warnf("Issue encountered when parsing offset value %v: %v", byteArray, err)
+		t.Log.Warnf("Issue encountered when reading checkpoint of %s: %v", filename, err)
 		return 0, err
 	}
-
-	if offset < 0 {
-		return 0, fmt.Errorf("negative state file offset, %v, %v", filePath, offset)
+	if !ok {
+		t.Log.Debugf("The checkpoint %s for %s does not exist", key, filename)
+		return 0, fmt.Errorf("no checkpoint %s for %s", key, filename)
 	}
-	t.Log.Infof("Reading from offset %v in %s", offset, filename)
-	return offset, nil
+	t.Log.Infof("Reading from offset %v in %s", c.Offset, filename)
+	return c.Offset, nil
 }
 
-func (t *LogFile) getStateFilePath(filename string) string {
-	if t.FileStateFolder == "" {
-		return ""
-	}
-
-	return filepath.Join(t.FileStateFolder, escapeFilePath(filename))
+func (t *LogFile) getStateKey(filename string) string {
+	return escapeFilePath(filename)
 }
 
-func (t *LogFile) cleanupStateFolder() {
-	files, err := filepath.Glob(t.FileStateFolder + string(filepath.Separator) + "*")
+// cleanupCheckpoints removes the checkpoints of the files which no longer exist, and the checkpoints
+// of rotated files whose archive can no longer be read.
+func (t *LogFile) cleanupCheckpoints() {
+	s := t.checkpointStore()
+	if s == nil {
+		return
+	}
+	checkpoints, err := s.List()
 	if err != nil {
-		t.Log.Errorf("Error happens in cleanup state folder %s: %v", t.FileStateFolder, err)
+		t.Log.Errorf("Error happens in cleanup checkpoints of %s: %v", t.FileStateFolder, err)
+		return
 	}
-	for _, file := range files {
-		if info, err := os.Stat(file); err != nil || info.IsDir() {
-			t.Log.Debugf("File %v does not exist or is a dirctory: %v, %v", file, err, info)
-			continue
-		}
-
-		if strings.Contains(file, logscommon.WindowsEventLogPrefix) {
-			continue
-		}
-
-		byteArray, err := os.ReadFile(file)
-		if err != nil {
-			t.Log.Errorf("Error happens when reading the content from file %s in clean up state fodler step: %v", file, err)
+	var deleteErr error
+	for _, c := range checkpoints {
+		if strings.HasPrefix(c.Key, rotatedStateFilePrefix) && time.Since(c.UpdatedAt) > compressedFileMaxAge {
+			if err = s.Delete(c.Key); err != nil && deleteErr == nil {
+				deleteErr = err
+			}
 			continue
 		}
-		contentArray := strings.Split(string(byteArray), "\n")
-		if len(contentArray) >= 2 {
-			if _, err = os.Stat(contentArray[1]); err == nil {
+		if c.File != "" {
+			if _, err = os.Stat(c.File); err == nil {
 				// the original source file still exists
 				continue
 			}
 		}
-		if err = os.Remove(file); err != nil {
-			t.Log.Errorf("Error happens when deleting old state file %s: %v", file, err)
+		if err = s.Delete(c.Key); err != nil && deleteErr == nil {
+			deleteErr = err
 		}
 	}
+	if deleteErr != nil {
+		t.Log.Warnf("Unable to delete the checkpoints of removed files in %s: %v", t.FileStateFolder, deleteErr)
+	}
 }
 
 func (t *LogFile) cleanUpStoppedTailerSrc() {
@@ -412,7 +674,7 @@ func (t *LogFile) cleanUpStoppedTailerSrc() {
 	}
 }
 
-// Compressed file should be skipped.
+// Compressed file should be skipped unless compressed files are read.
 // This func is to determine whether the file is compressed or not based on the file name suffix.
 func isCompressedFile(filename string) bool {
 	suffix := filepath.Ext(filename)
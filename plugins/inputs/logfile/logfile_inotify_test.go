@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+// +build linux
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+func TestLogFileInotifyWatchMode(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	dir := t.TempDir()
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = t.TempDir()
+	tt.WatchMode = watchModeInotify
+	tt.FileConfig = []FileConfig{{
+		FilePath:         filepath.Join(dir, "*", "*.log"),
+		FromBeginning:    true,
+		PublishMultiLogs: true,
+	}}
+	require.NoError(t, tt.Start(nil))
+	defer tt.Stop()
+	var _ logs.LogCollectionNotifier = tt
+	notifyCh := tt.Notify()
+	require.NotNil(t, notifyCh)
+
+	waitNotified(t, tt.notifyCh, true)
+	assert.Empty(t, tt.FindLogSrc())
+	waitNotified(t, tt.notifyCh, false)
+
+	// the files of the new directories are found once they are created
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "app"), 0755))
+	waitNotified(t, tt.notifyCh, true)
+	assert.Empty(t, tt.FindLogSrc())
+	filename := filepath.Join(dir, "app", "app.log")
+	require.NoError(t, os.WriteFile(filename, []byte("first line\n"), 0644))
+	waitNotified(t, tt.notifyCh, true)
+
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	lines := make(chan string, 2)
+	lsrcs[0].SetOutput(func(e logs.LogEvent) {
+		if e != nil {
+			lines <- e.Message()
+		}
+	})
+	defer lsrcs[0].Stop()
+	assert.Equal(t, "first line", <-lines)
+
+	// the appended lines are read on the inotify events of the file
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString("second line\n")
+	require.NoError(t, err)
+	select {
+	case line := <-lines:
+		assert.Equal(t, "second line", line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the appended line was not read")
+	}
+}
+
+func TestLogFileInvalidWatchMode(t *testing.T) {
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = t.TempDir()
+	tt.WatchMode = "fanotify"
+	assert.ErrorContains(t, tt.Start(nil), "invalid watch_mode")
+	assert.Nil(t, tt.Notify(), "the files are searched every second in poll mode")
+}
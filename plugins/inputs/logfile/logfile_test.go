@@ -4,11 +4,14 @@
 package logfile
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,6 +21,7 @@ import (
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/transform"
 
+	"github.com/aws/amazon-cloudwatch-agent/internal/checkpoint"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 )
 
@@ -90,6 +94,46 @@ func TestLogs(t *testing.T) {
 	tt.Stop()
 }
 
+func TestLogsFileDestination(t *testing.T) {
+	dir := t.TempDir()
+	defaultFile := filepath.Join(dir, "default.log")
+	routedFile := filepath.Join(dir, "routed.log")
+	auditFile := filepath.Join(dir, "audit.log")
+	require.NoError(t, os.WriteFile(defaultFile, []byte("default\n"), 0600))
+	require.NoError(t, os.WriteFile(routedFile, []byte("routed\n"), 0600))
+	require.NoError(t, os.WriteFile(auditFile, []byte("audit\n"), 0600))
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.Destination = "cloudwatchlogs"
+	tt.FileConfig = []FileConfig{
+		{FilePath: defaultFile, FromBeginning: true},
+		{FilePath: routedFile, FromBeginning: true, Destination: "security"},
+		{FilePath: auditFile, FromBeginning: true, Destinations: []string{"cloudwatchlogs", "security"}},
+	}
+	for i := range tt.FileConfig {
+		require.NoError(t, tt.FileConfig[i].init())
+	}
+	tt.started = true
+
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 3)
+	destinations := map[string][]string{}
+	for _, lsrc := range lsrcs {
+		destinations[lsrc.Description()] = []string{lsrc.Destination()}
+		if multi := lsrc.(logs.MultiDestinationLogSrc); len(multi.Destinations()) > 0 {
+			destinations[lsrc.Description()] = multi.Destinations()
+		}
+		lsrc.Stop()
+	}
+	assert.Equal(t, map[string][]string{
+		defaultFile: {"cloudwatchlogs"},
+		routedFile:  {"security"},
+		auditFile:   {"cloudwatchlogs", "security"},
+	}, destinations)
+	tt.Stop()
+}
+
 func TestLogsEncoding(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	//2 * rune_len when it is coded in gbk encoding.
@@ -190,6 +234,243 @@ func TestCompressedFile(t *testing.T) {
 	assert.True(t, compressed, "This should be a compressed file.")
 }
 
+func TestReadCompressedFiles(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	dir := t.TempDir()
+	stateDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.log"), []byte("live\n"), 0600))
+	archive := filepath.Join(dir, "app.log.1.gz")
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write([]byte("line1\nline2\nline3\n"))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0600))
+	settled := time.Now().Add(-compressedFileSettleTime)
+	require.NoError(t, os.Chtimes(archive, settled, settled))
+
+	newLogFile := func(readCompressed bool) *LogFile {
+		tt := NewLogFile()
+		tt.Log = TestLogger{t}
+		tt.FileStateFolder = stateDir
+		tt.FileConfig = []FileConfig{{FilePath: filepath.Join(dir, "app.log*"), FromBeginning: true, ReadCompressedFiles: readCompressed}}
+		require.NoError(t, tt.FileConfig[0].init())
+		tt.started = true
+		return tt
+	}
+
+	tt := newLogFile(false)
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	assert.Equal(t, filepath.Join(dir, "app.log"), lsrcs[0].Description())
+	lsrcs[0].Stop()
+	tt.Stop()
+
+	tt = newLogFile(true)
+	lsrcs = tt.FindLogSrc()
+	require.Len(t, lsrcs, 2)
+	lsrc := lsrcs[1]
+	assert.Equal(t, archive, lsrc.Description())
+	lsrcs[0].Stop()
+
+	var msgs []string
+	done := make(chan struct{})
+	lsrc.SetOutput(func(e logs.LogEvent) {
+		if e == nil {
+			close(done)
+			return
+		}
+		msgs = append(msgs, e.Message())
+		e.Done()
+	})
+	<-done
+	assert.Equal(t, []string{"line1", "line2", "line3"}, msgs)
+	assert.Empty(t, tt.FindLogSrc(), "the archive should only be read once")
+	require.Eventually(t, func() bool {
+		checkpoints, _ := tt.checkpointStore().List()
+		for _, c := range checkpoints {
+			if strings.HasPrefix(c.Key, compressedStateFilePrefix) {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+	lsrc.Stop()
+	tt.Stop()
+
+	// The archive has been fully read, a restart should not publish it again even after it has been renamed.
+	require.NoError(t, os.Rename(archive, filepath.Join(dir, "app.log.2.gz")))
+	tt = newLogFile(true)
+	lsrcs = tt.FindLogSrc()
+	require.Len(t, lsrcs, 2)
+	lsrcs[0].Stop()
+	done = make(chan struct{})
+	lsrcs[1].SetOutput(func(e logs.LogEvent) {
+		if e == nil {
+			close(done)
+			return
+		}
+		t.Errorf("Unexpected log event %v from already read archive", e.Message())
+	})
+	<-done
+	lsrcs[1].Stop()
+	tt.Stop()
+}
+
+func TestReadCompressedFilesRetry(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	defer func(settleTime time.Duration) { compressedFileSettleTime = settleTime }(compressedFileSettleTime)
+	compressedFileSettleTime = 0
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "app.log.1.gz")
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write([]byte("line1\nline2\nline3\n"))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	// The truncated archive fails to be read after its first lines.
+	require.NoError(t, os.WriteFile(archive, buf.Bytes()[:buf.Len()-10], 0600))
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileConfig = []FileConfig{{FilePath: filepath.Join(dir, "app.log*"), FromBeginning: true, ReadCompressedFiles: true}}
+	require.NoError(t, tt.FileConfig[0].init())
+	tt.started = true
+	defer tt.Stop()
+
+	readAll := func(lsrc logs.LogSrc) {
+		done := make(chan struct{})
+		lsrc.SetOutput(func(e logs.LogEvent) {
+			if e == nil {
+				close(done)
+				return
+			}
+			e.Done()
+		})
+		<-done
+		lsrc.Stop()
+	}
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	readAll(lsrcs[0])
+	assert.Empty(t, tt.compressedHashes, "the archive which failed to be read should be read again")
+
+	require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0600))
+	lsrcs = tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	readAll(lsrcs[0])
+	assert.Empty(t, tt.FindLogSrc(), "the archive should only be read once")
+	assert.Len(t, tt.compressedHashes, 1)
+
+	// The archives which no longer match are forgotten.
+	require.NoError(t, os.Remove(archive))
+	assert.Empty(t, tt.FindLogSrc())
+	assert.Empty(t, tt.compressedHashes)
+	assert.Empty(t, tt.compressedFiles)
+}
+
+func TestReadCompressedFilesSettled(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "app.log.1.gz")
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write([]byte("line1\n"))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	// The archive is still being written by the rotation.
+	require.NoError(t, os.WriteFile(archive, buf.Bytes()[:buf.Len()/2], 0600))
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileConfig = []FileConfig{{FilePath: archive, FromBeginning: true, ReadCompressedFiles: true}}
+	require.NoError(t, tt.FileConfig[0].init())
+	tt.started = true
+	defer tt.Stop()
+	assert.Empty(t, tt.FindLogSrc(), "the archive being written should not be read")
+	assert.Empty(t, tt.compressedHashes)
+
+	require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0600))
+	settled := time.Now().Add(-compressedFileSettleTime)
+	require.NoError(t, os.Chtimes(archive, settled, settled))
+	lsrcs := tt.FindLogSrc()
+	require.Len(t, lsrcs, 1)
+	lsrcs[0].Stop()
+}
+
+func TestReadCompressedFilesFromRotatedState(t *testing.T) {
+	multilineWaitPeriod = 10 * time.Millisecond
+	defer func(settleTime time.Duration) { compressedFileSettleTime = settleTime }(compressedFileSettleTime)
+	compressedFileSettleTime = 0
+	content := []byte("line1\nline2\nline3\n")
+	fp, size, err := fingerprint(bytes.NewReader(content), 6)
+	require.NoError(t, err)
+	otherFp, _, err := fingerprint(bytes.NewReader([]byte("other\n")), 6)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		fingerprint string
+		want        []string
+	}{
+		"Matching": {fingerprint: fp, want: []string{"line3"}},
+		"Mismatch": {fingerprint: otherFp, want: []string{"line1", "line2", "line3"}},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			logFile := filepath.Join(dir, "app.log")
+			archive := logFile + ".1.gz"
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			_, err := gw.Write(content)
+			require.NoError(t, err)
+			require.NoError(t, gw.Close())
+			require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0600))
+
+			tt := NewLogFile()
+			tt.Log = TestLogger{t}
+			tt.FileStateFolder = t.TempDir()
+			tt.FileConfig = []FileConfig{{FilePath: archive, FromBeginning: true, ReadCompressedFiles: true}}
+			require.NoError(t, tt.FileConfig[0].init())
+			tt.started = true
+			defer tt.Stop()
+			rotatedKey := rotatedStateKey(tt.getStateKey(logFile), testCase.fingerprint)
+			require.NoError(t, tt.checkpointStore().Set(checkpoint.Checkpoint{
+				Key:             rotatedKey,
+				File:            logFile,
+				Offset:          12,
+				Fingerprint:     testCase.fingerprint,
+				FingerprintSize: size,
+			}))
+
+			lsrcs := tt.FindLogSrc()
+			require.Len(t, lsrcs, 1)
+			var got []string
+			done := make(chan struct{})
+			lsrcs[0].SetOutput(func(e logs.LogEvent) {
+				if e == nil {
+					close(done)
+					return
+				}
+				got = append(got, e.Message())
+				e.Done()
+			})
+			<-done
+			lsrcs[0].Stop()
+			assert.Equal(t, testCase.want, got)
+
+			_, found, err := tt.checkpointStore().Get(rotatedKey)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.fingerprint != fp, found, "the checkpoint of the rotated file should only be moved to the matching archive")
+		})
+	}
+}
+
+func TestRotatedFileCandidates(t *testing.T) {
+	assert.Equal(t, []string{"/var/log/app.log.1", "/var/log/app.log"}, rotatedFileCandidates("/var/log/app.log.1.gz"))
+	assert.Equal(t, []string{"/var/log/app.log-20240101", "/var/log/app.log"}, rotatedFileCandidates("/var/log/app.log-20240101.zst"))
+	assert.Equal(t, []string{"/var/log/app.log"}, rotatedFileCandidates("/var/log/app.log.gz"))
+}
+
 func TestRestoreState(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	tmpfolder, err := os.MkdirTemp("", "")
@@ -212,6 +493,9 @@ func TestRestoreState(t *testing.T) {
 	roffset, err := tt.restoreState(logFilePath)
 	require.NoError(t, err)
 	assert.Equal(t, offset, roffset, fmt.Sprintf("The actual offset is %d, different from the expected offset %d.", roffset, offset))
+	_, err = os.Stat(tmpfolder + string(filepath.Separator) + logFileStateFileName)
+	assert.True(t, os.IsNotExist(err), "the state file should be removed once migrated")
+	tt.Stop()
 
 	// Test negative offset.
 	offset = int64(-8675)
@@ -220,13 +504,46 @@ func TestRestoreState(t *testing.T) {
 		[]byte(strconv.FormatInt(offset, 10)+"\n"+logFilePath),
 		os.ModePerm)
 	require.NoError(t, err)
+	tt = NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = tmpfolder
+	roffset, err = tt.restoreState(logFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9323), roffset, "the invalid state file should not replace the checkpoint")
+	_, err = os.Stat(tmpfolder + string(filepath.Separator) + logFileStateFileName)
+	assert.NoError(t, err, "the invalid state file should be kept")
+
+	tt.checkpointStore().Delete(logFileStateFileName)
 	roffset, err = tt.restoreState(logFilePath)
 	require.Error(t, err)
-	assert.Equal(t, int64(0), roffset, fmt.Sprintf("The actual offset is %d, different from the expected offset %d.", roffset, offset))
+	assert.Equal(t, int64(0), roffset, fmt.Sprintf("The actual offset is %d, different from the expected offset %d.", roffset, 0))
 
 	tt.Stop()
 }
 
+func TestCleanupCheckpoints(t *testing.T) {
+	stateDir := t.TempDir()
+	tmpfile, err := createTempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	tt := NewLogFile()
+	tt.Log = TestLogger{t}
+	tt.FileStateFolder = stateDir
+	require.NoError(t, tt.checkpointStore().Import([]checkpoint.Checkpoint{
+		{Key: escapeFilePath(tmpfile.Name()), File: tmpfile.Name(), Offset: 10},
+		{Key: escapeFilePath("/removed.log"), File: "/removed.log", Offset: 20},
+		{Key: "unknown", Offset: 30},
+	}))
+	tt.cleanupCheckpoints()
+
+	checkpoints, err := tt.checkpointStore().List()
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	assert.Equal(t, tmpfile.Name(), checkpoints[0].File)
+	tt.Stop()
+}
+
 func TestMultipleFilesForSameConfig(t *testing.T) {
 	multilineWaitPeriod = 10 * time.Millisecond
 	tmpfile1, err := createTempFile("", "tmp1_")
@@ -4,8 +4,12 @@
 package logfile
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 )
@@ -13,35 +17,215 @@ import (
 const (
 	includeFilterType = "include"
 	excludeFilterType = "exclude"
+	sampleFilterType  = "sample"
+
+	allFilterMatch = "all"
+	anyFilterMatch = "any"
 )
 
 var (
-	validFilterTypes    = []string{includeFilterType, excludeFilterType}
+	validFilterTypes    = []string{includeFilterType, excludeFilterType, sampleFilterType}
 	validFilterTypesSet = map[string]bool{
 		includeFilterType: true,
 		excludeFilterType: true,
+		sampleFilterType:  true,
+	}
+	validFilterMatches    = []string{allFilterMatch, anyFilterMatch}
+	validFilterMatchesSet = map[string]bool{
+		allFilterMatch: true,
+		anyFilterMatch: true,
+	}
+	validFilterOperators = map[string]func(a, b float64) bool{
+		">":  func(a, b float64) bool { return a > b },
+		">=": func(a, b float64) bool { return a >= b },
+		"<":  func(a, b float64) bool { return a < b },
+		"<=": func(a, b float64) bool { return a <= b },
+		"==": func(a, b float64) bool { return a == b },
+		"!=": func(a, b float64) bool { return a != b },
 	}
 )
 
+// LogFilterCondition matches a log event either with a regex or with a numeric comparison. The
+// condition applies to the log message, or to a parsed field when Field is set.
+type LogFilterCondition struct {
+	Field      string  `toml:"field"`
+	Expression string  `toml:"expression"`
+	Operator   string  `toml:"operator"`
+	Value      float64 `toml:"value"`
+
+	expressionP *regexp.Regexp
+	compare     func(a, b float64) bool
+}
+
+func (c *LogFilterCondition) init() error {
+	if c.Operator != "" {
+		var ok bool
+		if c.compare, ok = validFilterOperators[c.Operator]; !ok {
+			return fmt.Errorf("filter operator %s is incorrect, valid operators are: >, >=, <, <=, ==, !=", c.Operator)
+		}
+		if c.Field == "" {
+			return fmt.Errorf("filter operator %s requires a field", c.Operator)
+		}
+		if c.Expression == "" {
+			return nil
+		}
+	}
+
+	var err error
+	if c.expressionP, err = regexp.Compile(c.Expression); err != nil {
+		return fmt.Errorf("filter regex has issue, regexp: Compile( %v ): %v", c.Expression, err.Error())
+	}
+	return nil
+}
+
+func (c *LogFilterCondition) matches(event logs.LogEvent) bool {
+	if c.Field == "" {
+		return c.expressionP.MatchString(event.Message())
+	}
+
+	val, ok := lookupField(event, c.Field)
+	if !ok {
+		return false
+	}
+	if c.expressionP != nil && !c.expressionP.MatchString(fieldString(val)) {
+		return false
+	}
+	if c.compare != nil {
+		num, ok := fieldNumber(val)
+		if !ok || !c.compare(num, c.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// LogFilter decides whether a log event is published. Include and exclude filters publish the
+// events which do or do not match, sample filters publish one in SampleRate of the matching
+// events and every event which does not match.
+//
+// A filter matches when its own condition matches, or when all or any of its Conditions match
+// depending on Match.
 type LogFilter struct {
-	Type        string `toml:"type"`
-	Expression  string `toml:"expression"`
+	Type       string                `toml:"type"`
+	Expression string                `toml:"expression"`
+	Field      string                `toml:"field"`
+	Operator   string                `toml:"operator"`
+	Value      float64               `toml:"value"`
+	Match      string                `toml:"match"`
+	Conditions []*LogFilterCondition `toml:"conditions"`
+	SampleRate int64                 `toml:"sample_rate"`
+
+	conditions  []*LogFilterCondition
 	expressionP *regexp.Regexp
+	sampleCount int64
 }
 
 func (filter *LogFilter) init() error {
 	if _, present := validFilterTypesSet[filter.Type]; !present {
 		return fmt.Errorf("filter type %s is incorrect, valid types are: %v", filter.Type, validFilterTypes)
 	}
+	if filter.Match == "" {
+		filter.Match = allFilterMatch
+	}
+	if _, present := validFilterMatchesSet[filter.Match]; !present {
+		return fmt.Errorf("filter match %s is incorrect, valid matches are: %v", filter.Match, validFilterMatches)
+	}
+	if filter.Type == sampleFilterType && filter.SampleRate < 1 {
+		return fmt.Errorf("filter sample_rate %d is incorrect, it must be at least 1", filter.SampleRate)
+	}
 
-	var err error
-	if filter.expressionP, err = regexp.Compile(filter.Expression); err != nil {
-		return fmt.Errorf("filter regex has issue, regexp: Compile( %v ): %v", filter.Expression, err.Error())
+	filter.conditions = filter.Conditions
+	if len(filter.conditions) == 0 {
+		filter.conditions = []*LogFilterCondition{{
+			Field:      filter.Field,
+			Expression: filter.Expression,
+			Operator:   filter.Operator,
+			Value:      filter.Value,
+		}}
+	}
+	for _, c := range filter.conditions {
+		if err := c.init(); err != nil {
+			return err
+		}
+	}
+	if len(filter.Conditions) == 0 {
+		filter.expressionP = filter.conditions[0].expressionP
 	}
 	return nil
 }
 
+func (filter *LogFilter) matches(event logs.LogEvent) bool {
+	for _, c := range filter.conditions {
+		if c.matches(event) == (filter.Match == anyFilterMatch) {
+			return filter.Match == anyFilterMatch
+		}
+	}
+	return filter.Match == allFilterMatch
+}
+
 func (filter *LogFilter) ShouldPublish(event logs.LogEvent) bool {
-	match := filter.expressionP.MatchString(event.Message())
-	return (filter.Type == includeFilterType) == match
+	match := filter.matches(event)
+	switch filter.Type {
+	case sampleFilterType:
+		return !match || (atomic.AddInt64(&filter.sampleCount, 1)-1)%filter.SampleRate == 0
+	case includeFilterType:
+		return match
+	default:
+		return !match
+	}
+}
+
+// lookupField returns the parsed field of the event. Nested fields are looked up with a dotted
+// path, e.g. "http.status", when the field name itself is not present.
+func lookupField(event logs.LogEvent, name string) (interface{}, bool) {
+	structured, ok := event.(logs.StructuredLogEvent)
+	if !ok {
+		return nil, false
+	}
+	fields := structured.Fields()
+	if val, ok := fields[name]; ok {
+		return val, true
+	}
+	parts := strings.Split(name, ".")
+	var val interface{} = fields
+	for _, part := range parts {
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if val, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+func fieldString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(val)
+}
+
+func fieldNumber(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
 }
@@ -4,6 +4,7 @@
 package logfile
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,6 +48,82 @@ func TestLogFilterShouldPublishExclude(t *testing.T) {
 	assertShouldPublish(t, filter, "something else")
 }
 
+func TestLogFilterInitInvalidConditions(t *testing.T) {
+	testCases := map[string]*LogFilter{
+		"match":       {Type: includeFilterType, Match: "none"},
+		"operator":    {Type: includeFilterType, Field: "latency_ms", Operator: "=>"},
+		"no field":    {Type: includeFilterType, Operator: ">"},
+		"sample rate": {Type: sampleFilterType, Expression: "DEBUG"},
+		"condition":   {Type: includeFilterType, Conditions: []*LogFilterCondition{{Expression: "abc)"}}},
+	}
+	for name, filter := range testCases {
+		assert.Error(t, filter.init(), name)
+	}
+}
+
+func TestLogFilterFields(t *testing.T) {
+	filter := &LogFilter{Type: includeFilterType, Field: "latency_ms", Operator: ">", Value: 500}
+	assert.NoError(t, filter.init())
+
+	assert.True(t, filter.ShouldPublish(LogEvent{fields: map[string]interface{}{"latency_ms": json.Number("501")}}))
+	assert.True(t, filter.ShouldPublish(LogEvent{fields: map[string]interface{}{"latency_ms": "750.5"}}))
+	assert.False(t, filter.ShouldPublish(LogEvent{fields: map[string]interface{}{"latency_ms": int64(500)}}))
+	assert.False(t, filter.ShouldPublish(LogEvent{fields: map[string]interface{}{"latency_ms": "slow"}}))
+	assert.False(t, filter.ShouldPublish(LogEvent{msg: "latency_ms=900"}))
+
+	filter = &LogFilter{Type: excludeFilterType, Field: "http.path", Expression: "^/health"}
+	assert.NoError(t, filter.init())
+	nested := func(path string) LogEvent {
+		return LogEvent{fields: map[string]interface{}{"http": map[string]interface{}{"path": path}}}
+	}
+	assert.False(t, filter.ShouldPublish(nested("/healthz")))
+	assert.True(t, filter.ShouldPublish(nested("/api")))
+	assert.True(t, filter.ShouldPublish(LogEvent{msg: "/health"}))
+}
+
+func TestLogFilterMatch(t *testing.T) {
+	conditions := func() []*LogFilterCondition {
+		return []*LogFilterCondition{
+			{Field: "level", Expression: "^ERROR$"},
+			{Field: "status", Operator: ">=", Value: 500},
+		}
+	}
+	all := &LogFilter{Type: includeFilterType, Conditions: conditions()}
+	assert.NoError(t, all.init())
+	anyOf := &LogFilter{Type: includeFilterType, Match: anyFilterMatch, Conditions: conditions()}
+	assert.NoError(t, anyOf.init())
+
+	testCases := []struct {
+		fields   map[string]interface{}
+		all, any bool
+	}{
+		{fields: map[string]interface{}{"level": "ERROR", "status": int64(503)}, all: true, any: true},
+		{fields: map[string]interface{}{"level": "ERROR", "status": int64(200)}, all: false, any: true},
+		{fields: map[string]interface{}{"level": "INFO", "status": int64(502)}, all: false, any: true},
+		{fields: map[string]interface{}{"level": "INFO", "status": int64(200)}, all: false, any: false},
+		{fields: nil, all: false, any: false},
+	}
+	for _, testCase := range testCases {
+		event := LogEvent{fields: testCase.fields}
+		assert.Equal(t, testCase.all, all.ShouldPublish(event), "all %v", testCase.fields)
+		assert.Equal(t, testCase.any, anyOf.ShouldPublish(event), "any %v", testCase.fields)
+	}
+}
+
+func TestLogFilterSample(t *testing.T) {
+	filter := &LogFilter{Type: sampleFilterType, Field: "level", Expression: "DEBUG", SampleRate: 10}
+	assert.NoError(t, filter.init())
+
+	published := 0
+	for i := 0; i < 100; i++ {
+		if filter.ShouldPublish(LogEvent{fields: map[string]interface{}{"level": "DEBUG"}}) {
+			published++
+		}
+		assert.True(t, filter.ShouldPublish(LogEvent{fields: map[string]interface{}{"level": "ERROR"}}))
+	}
+	assert.Equal(t, 10, published)
+}
+
 func BenchmarkLogFilterShouldPublish(b *testing.B) {
 	exp := "(foo|bar|baz)"
 	filter, err := initLogFilter(excludeFilterType, exp)
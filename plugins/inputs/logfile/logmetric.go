@@ -0,0 +1,196 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+const (
+	// logMetricValueField is the field of the log metrics, which names them after their measurement.
+	logMetricValueField = "value"
+	// logMetricAggregationInterval aggregates the log metrics into statistic sets in the cloudwatch output.
+	logMetricAggregationInterval = "60s"
+	aggregationIntervalTagKey    = "aws:AggregationInterval"
+	// maxPendingLogMetricValues bounds the extracted values kept between two collections.
+	maxPendingLogMetricValues = 10000
+)
+
+// LogMetric counts the log events matching Expression, or extracts a numeric value from them,
+// and publishes it through the metrics pipeline.
+//
+// Value and Dimensions refer to named capture groups of Expression, or to fields extracted by the
+// parsers of the file. The metric is a count of the matching log events when Value is empty.
+type LogMetric struct {
+	Name       string   `toml:"name"`
+	Expression string   `toml:"expression"`
+	Value      string   `toml:"value"`
+	Dimensions []string `toml:"dimensions"`
+
+	expressionP *regexp.Regexp
+}
+
+func (m *LogMetric) init() error {
+	if m.Name == "" {
+		return fmt.Errorf("log metric requires a name")
+	}
+	if m.Expression == "" {
+		if m.Value == "" {
+			return fmt.Errorf("log metric %s requires an expression or a value", m.Name)
+		}
+		return nil
+	}
+	var err error
+	if m.expressionP, err = regexp.Compile(m.Expression); err != nil {
+		return fmt.Errorf("log metric regex has issue, regexp: Compile( %v ): %v", m.Expression, err.Error())
+	}
+	return nil
+}
+
+// lookup returns the capture group of the expression match, or the parsed field of the event.
+func (m *LogMetric) lookup(event logs.LogEvent, match []string, name string) (interface{}, bool) {
+	if match != nil {
+		if i := m.expressionP.SubexpIndex(name); i > 0 && match[i] != "" {
+			return match[i], true
+		}
+	}
+	return lookupField(event, name)
+}
+
+type logMetricCount struct {
+	name  string
+	tags  map[string]string
+	count float64
+}
+
+type logMetricValue struct {
+	name  string
+	tags  map[string]string
+	value float64
+	t     time.Time
+}
+
+// logMetricRecorder records the log metrics of the log events of a file config until they are
+// collected. Counts are summed per metric and dimensions, values are kept individually.
+type logMetricRecorder struct {
+	metrics []*LogMetric
+
+	mu      sync.Mutex
+	counts  map[string]*logMetricCount
+	values  []logMetricValue
+	dropped int
+}
+
+func newLogMetricRecorder(metrics []*LogMetric) *logMetricRecorder {
+	return &logMetricRecorder{
+		metrics: metrics,
+		counts:  make(map[string]*logMetricCount),
+	}
+}
+
+func (r *logMetricRecorder) record(event logs.LogEvent) {
+	now := time.Now()
+	for _, m := range r.metrics {
+		var match []string
+		if m.expressionP != nil {
+			if match = m.expressionP.FindStringSubmatch(event.Message()); match == nil {
+				continue
+			}
+		}
+		tags := make(map[string]string, len(m.Dimensions))
+		for _, dimension := range m.Dimensions {
+			if val, ok := m.lookup(event, match, dimension); ok {
+				tags[dimension] = fieldString(val)
+			}
+		}
+		if m.Value == "" {
+			r.addCount(m.Name, tags)
+			continue
+		}
+		val, ok := m.lookup(event, match, m.Value)
+		if !ok {
+			continue
+		}
+		if num, ok := fieldNumber(val); ok {
+			r.addValue(logMetricValue{name: m.Name, tags: tags, value: num, t: now})
+		}
+	}
+}
+
+func (r *logMetricRecorder) addCount(name string, tags map[string]string) {
+	key := seriesKey(name, tags)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.counts[key]
+	if !ok {
+		c = &logMetricCount{name: name, tags: tags}
+		r.counts[key] = c
+	}
+	c.count++
+}
+
+func (r *logMetricRecorder) addValue(v logMetricValue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.values) >= maxPendingLogMetricValues {
+		r.dropped++
+		return
+	}
+	r.values = append(r.values, v)
+}
+
+// flush adds the recorded log metrics to the accumulator and returns the number of values
+// dropped since the last flush.
+func (r *logMetricRecorder) flush(acc telegraf.Accumulator) int {
+	r.mu.Lock()
+	counts, values, dropped := r.counts, r.values, r.dropped
+	r.counts = make(map[string]*logMetricCount)
+	r.values = nil
+	r.dropped = 0
+	r.mu.Unlock()
+
+	now := time.Now()
+	for _, c := range counts {
+		acc.AddFields(c.name, map[string]interface{}{logMetricValueField: c.count}, withAggregationInterval(c.tags), now)
+	}
+	for _, v := range values {
+		acc.AddFields(v.name, map[string]interface{}{logMetricValueField: v.value}, withAggregationInterval(v.tags), v.t)
+	}
+	return dropped
+}
+
+func withAggregationInterval(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		result[k] = v
+	}
+	result[aggregationIntervalTagKey] = logMetricAggregationInterval
+	return result
+}
+
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, k := range keys {
+		sb.WriteString("\x00")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(tags[k])
+	}
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMetricInit(t *testing.T) {
+	assert.Error(t, (&LogMetric{Expression: "ERROR"}).init())
+	assert.Error(t, (&LogMetric{Name: "errors"}).init())
+	assert.Error(t, (&LogMetric{Name: "errors", Expression: "abc)"}).init())
+	assert.NoError(t, (&LogMetric{Name: "errors", Expression: "ERROR"}).init())
+	assert.NoError(t, (&LogMetric{Name: "latency", Value: "latency_ms"}).init())
+}
+
+func TestLogMetricCount(t *testing.T) {
+	m := &LogMetric{Name: "http_errors", Expression: `status=(?P<status>5\d\d)`, Dimensions: []string{"status"}}
+	require.NoError(t, m.init())
+	r := newLogMetricRecorder([]*LogMetric{m})
+
+	for _, msg := range []string{"status=500", "status=503", "status=500", "status=200"} {
+		r.record(&LogEvent{msg: msg})
+	}
+
+	acc := &testutil.Accumulator{}
+	assert.Equal(t, 0, r.flush(acc))
+	require.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "http_errors", map[string]interface{}{"value": float64(2)},
+		map[string]string{"status": "500", aggregationIntervalTagKey: logMetricAggregationInterval})
+	acc.AssertContainsTaggedFields(t, "http_errors", map[string]interface{}{"value": float64(1)},
+		map[string]string{"status": "503", aggregationIntervalTagKey: logMetricAggregationInterval})
+
+	acc.ClearMetrics()
+	r.flush(acc)
+	assert.Len(t, acc.Metrics, 0)
+}
+
+func TestLogMetricValue(t *testing.T) {
+	captured := &LogMetric{Name: "duration", Expression: `took (?P<duration>\d+)ms`, Value: "duration"}
+	parsed := &LogMetric{Name: "latency", Value: "http.latency_ms", Dimensions: []string{"level", "http.method"}}
+	require.NoError(t, captured.init())
+	require.NoError(t, parsed.init())
+	r := newLogMetricRecorder([]*LogMetric{captured, parsed})
+
+	r.record(&LogEvent{msg: "request took 42ms"})
+	r.record(&LogEvent{msg: "request", fields: map[string]interface{}{
+		"level": "info",
+		"http":  map[string]interface{}{"method": "GET", "latency_ms": json.Number("12.5")},
+	}})
+	r.record(&LogEvent{msg: "request", fields: map[string]interface{}{"http": map[string]interface{}{"latency_ms": "slow"}}})
+
+	acc := &testutil.Accumulator{}
+	r.flush(acc)
+	require.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "duration", map[string]interface{}{"value": float64(42)},
+		map[string]string{aggregationIntervalTagKey: logMetricAggregationInterval})
+	acc.AssertContainsTaggedFields(t, "latency", map[string]interface{}{"value": 12.5},
+		map[string]string{"level": "info", "http.method": "GET", aggregationIntervalTagKey: logMetricAggregationInterval})
+}
+
+func TestLogMetricValueLimit(t *testing.T) {
+	m := &LogMetric{Name: "duration", Expression: `took (?P<duration>\d+)ms`, Value: "duration"}
+	require.NoError(t, m.init())
+	r := newLogMetricRecorder([]*LogMetric{m})
+	for i := 0; i < maxPendingLogMetricValues+3; i++ {
+		r.record(&LogEvent{msg: "took 1ms"})
+	}
+	acc := &testutil.Accumulator{}
+	assert.Equal(t, 3, r.flush(acc))
+	assert.Len(t, acc.Metrics, maxPendingLogMetricValues)
+}
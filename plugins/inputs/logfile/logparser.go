@@ -0,0 +1,344 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logfmt/logfmt"
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/rfc5424"
+
+	"github.com/aws/amazon-cloudwatch-agent/profiler"
+)
+
+const (
+	jsonParserType           = "json"
+	logfmtParserType         = "logfmt"
+	regexParserType          = "regex"
+	syslogRFC5424ParserType  = "syslog_rfc5424"
+	apacheCombinedParserType = "apache_combined"
+	nginxCombinedParserType  = "nginx_combined"
+
+	// LevelFieldKey is the field the level of a log event is promoted to.
+	LevelFieldKey = "level"
+)
+
+var (
+	validParserTypes    = []string{jsonParserType, logfmtParserType, regexParserType, syslogRFC5424ParserType, apacheCombinedParserType, nginxCombinedParserType}
+	validParserTypesSet = map[string]bool{
+		jsonParserType:           true,
+		logfmtParserType:         true,
+		regexParserType:          true,
+		syslogRFC5424ParserType:  true,
+		apacheCombinedParserType: true,
+		nginxCombinedParserType:  true,
+	}
+
+	// Matches both the common and the combined log format used by Apache and nginx.
+	combinedLogFormatRegexp  = regexp.MustCompile(`^(?P<remote_addr>\S+) (?P<ident>\S+) (?P<remote_user>\S+) \[(?P<time>[^\]]+)\] "(?P<method>\S+)(?: (?P<path>\S+))?(?: (?P<protocol>[^"]+))?" (?P<status>\d{3}) (?P<body_bytes_sent>\d+|-)(?: "(?P<referer>[^"]*)" "(?P<user_agent>[^"]*)")?`)
+	combinedLogFormatNumbers = map[string]bool{"status": true, "body_bytes_sent": true}
+
+	// Layouts tried on promoted timestamp fields when no timestamp_layout is configured.
+	defaultFieldTimestampLayouts = []string{time.RFC3339Nano, "02/Jan/2006:15:04:05 -0700", "2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999"}
+
+	levelAliases = map[string]string{
+		"TRACE":         "TRACE",
+		"DEBUG":         "DEBUG",
+		"INFO":          "INFO",
+		"INFORMATION":   "INFO",
+		"INFORMATIONAL": "INFO",
+		"NOTICE":        "INFO",
+		"WARN":          "WARN",
+		"WARNING":       "WARN",
+		"ERR":           "ERROR",
+		"ERROR":         "ERROR",
+		"CRIT":          "FATAL",
+		"CRITICAL":      "FATAL",
+		"ALERT":         "FATAL",
+		"EMERG":         "FATAL",
+		"EMERGENCY":     "FATAL",
+		"FATAL":         "FATAL",
+		"PANIC":         "FATAL",
+	}
+)
+
+// LogParser extracts structured fields from the log message, or from a field extracted by a previous parser.
+type LogParser struct {
+	Type       string `toml:"type"`
+	Expression string `toml:"expression"`
+	Source     string `toml:"source"`
+
+	expressionP *regexp.Regexp
+	parse       func(string) (map[string]interface{}, error)
+}
+
+func (parser *LogParser) init() error {
+	if _, present := validParserTypesSet[parser.Type]; !present {
+		return fmt.Errorf("parser type %s is incorrect, valid types are: %v", parser.Type, validParserTypes)
+	}
+
+	switch parser.Type {
+	case jsonParserType:
+		parser.parse = parseJSONFields
+	case logfmtParserType:
+		parser.parse = parseLogfmtFields
+	case regexParserType:
+		var err error
+		if parser.expressionP, err = regexp.Compile(parser.Expression); err != nil {
+			return fmt.Errorf("parser regex has issue, regexp: Compile( %v ): %v", parser.Expression, err.Error())
+		}
+		if !hasNamedCaptureGroup(parser.expressionP) {
+			return fmt.Errorf("parser regex %v has no named capture group", parser.Expression)
+		}
+		parser.parse = func(s string) (map[string]interface{}, error) {
+			return parseRegexFields(parser.expressionP, s, nil)
+		}
+	case syslogRFC5424ParserType:
+		parser.parse = func(s string) (map[string]interface{}, error) {
+			// the parser locks its stateful machine while parsing, one parser per call lets the
+			// tailers of the file config parse concurrently
+			machine := rfc5424.NewParser(rfc5424.WithBestEffort())
+			return parseSyslogRFC5424Fields(machine.Parse, s)
+		}
+	case apacheCombinedParserType, nginxCombinedParserType:
+		parser.parse = func(s string) (map[string]interface{}, error) {
+			return parseRegexFields(combinedLogFormatRegexp, s, combinedLogFormatNumbers)
+		}
+	}
+	return nil
+}
+
+// Parse adds the fields extracted from the parser source to fields.
+func (parser *LogParser) Parse(msg string, fields map[string]interface{}) error {
+	source := msg
+	if parser.Source != "" {
+		val, ok := fields[parser.Source]
+		if !ok {
+			return fmt.Errorf("source field %v is missing", parser.Source)
+		}
+		if source, ok = val.(string); !ok {
+			return fmt.Errorf("source field %v is not a string", parser.Source)
+		}
+	}
+	parsed, err := parser.parse(source)
+	if err != nil {
+		return err
+	}
+	for k, v := range parsed {
+		fields[k] = v
+	}
+	return nil
+}
+
+func parseJSONFields(s string) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, fmt.Errorf("not a json object")
+	}
+	return fields, nil
+}
+
+func parseLogfmtFields(s string) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	decoder := logfmt.NewDecoder(strings.NewReader(s))
+	for decoder.ScanRecord() {
+		for decoder.ScanKeyval() {
+			// Bare words without a value are free text rather than key value pairs.
+			if decoder.Value() != nil {
+				fields[string(decoder.Key())] = string(decoder.Value())
+			}
+		}
+	}
+	if err := decoder.Err(); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no logfmt key value pair found")
+	}
+	return fields, nil
+}
+
+func hasNamedCaptureGroup(expression *regexp.Regexp) bool {
+	for _, name := range expression.SubexpNames() {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRegexFields(expression *regexp.Regexp, s string, numbers map[string]bool) (map[string]interface{}, error) {
+	match := expression.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("no match for %v", expression)
+	}
+	fields := map[string]interface{}{}
+	for i, name := range expression.SubexpNames() {
+		if name == "" || match[i] == "" {
+			continue
+		}
+		fields[name] = match[i]
+		if numbers[name] {
+			if n, err := strconv.ParseInt(match[i], 10, 64); err == nil {
+				fields[name] = n
+			}
+		}
+	}
+	return fields, nil
+}
+
+func parseSyslogRFC5424Fields(parse func([]byte) (syslog.Message, error), s string) (map[string]interface{}, error) {
+	m, err := parse([]byte(s))
+	if m == nil {
+		return nil, err
+	}
+	msg, ok := m.(*rfc5424.SyslogMessage)
+	if !ok || !msg.Valid() {
+		return nil, fmt.Errorf("not a rfc5424 syslog message: %v", err)
+	}
+	fields := map[string]interface{}{
+		"priority": int64(*msg.Priority),
+		"facility": *msg.FacilityLevel(),
+		"severity": *msg.SeverityLevel(),
+		"version":  int64(msg.Version),
+	}
+	if msg.Timestamp != nil {
+		fields["timestamp"] = msg.Timestamp.Format(time.RFC3339Nano)
+	}
+	if msg.Hostname != nil {
+		fields["hostname"] = *msg.Hostname
+	}
+	if msg.Appname != nil {
+		fields["appname"] = *msg.Appname
+	}
+	if msg.ProcID != nil {
+		fields["procid"] = *msg.ProcID
+	}
+	if msg.MsgID != nil {
+		fields["msgid"] = *msg.MsgID
+	}
+	if msg.StructuredData != nil {
+		fields["structured_data"] = *msg.StructuredData
+	}
+	if msg.Message != nil {
+		fields["message"] = *msg.Message
+	}
+	return fields, nil
+}
+
+// ParserPipeline runs the configured parsers over each log event, promotes the parsed fields to
+// the event timestamp and level, and optionally renders the event as JSON.
+type ParserPipeline struct {
+	parsers         []*LogParser
+	timestampField  string
+	timestampLayout []string
+	timezone        *time.Location
+	levelField      string
+	renderJSON      bool
+}
+
+// Process adds the parsed fields to the log event. Parsers which do not match the event are skipped.
+func (pipeline *ParserPipeline) Process(e *LogEvent) {
+	fields := map[string]interface{}{}
+	failed := 0
+	for _, parser := range pipeline.parsers {
+		if err := parser.Parse(e.msg, fields); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 && e.src != nil {
+		profiler.Profiler.AddStats([]string{"logfile", e.src.group, e.src.stream, "messages", "parse_failed"}, float64(failed))
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	if pipeline.timestampField != "" {
+		if val, ok := fields[pipeline.timestampField]; ok {
+			if t, err := pipeline.parseTimestamp(val); err == nil {
+				e.t = t
+			} else {
+				log.Printf("D! [logfile] Unable to parse timestamp field %v: %v", pipeline.timestampField, err)
+			}
+		}
+	}
+
+	if pipeline.levelField != "" {
+		if val, ok := fields[pipeline.levelField]; ok {
+			delete(fields, pipeline.levelField)
+			fields[LevelFieldKey] = normalizeLevel(fmt.Sprint(val))
+		}
+	}
+
+	e.fields = fields
+	if pipeline.renderJSON {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(fields); err != nil {
+			log.Printf("E! [logfile] Unable to render log event as json: %v", err)
+			return
+		}
+		e.msg = strings.TrimSuffix(buf.String(), "\n")
+	}
+}
+
+func (pipeline *ParserPipeline) parseTimestamp(val interface{}) (time.Time, error) {
+	switch v := val.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return epochToTime(f), nil
+		}
+		return time.Time{}, fmt.Errorf("invalid epoch timestamp %v", v)
+	case float64:
+		return epochToTime(v), nil
+	case int64:
+		return epochToTime(float64(v)), nil
+	case string:
+		layouts := pipeline.timestampLayout
+		if len(layouts) == 0 {
+			layouts = defaultFieldTimestampLayouts
+		}
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, v, pipeline.timezone); err == nil {
+				return t, nil
+			}
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return epochToTime(f), nil
+		}
+		return time.Time{}, fmt.Errorf("timestamp %v does not match any layout %v", v, layouts)
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp type %T", val)
+}
+
+// epochToTime converts epoch seconds or milliseconds to a time.
+func epochToTime(epoch float64) time.Time {
+	if epoch > 1e11 {
+		return time.UnixMilli(int64(epoch))
+	}
+	sec := int64(epoch)
+	return time.Unix(sec, int64((epoch-float64(sec))*1e9))
+}
+
+func normalizeLevel(level string) string {
+	upper := strings.ToUpper(strings.TrimSpace(level))
+	if normalized, ok := levelAliases[upper]; ok {
+		return normalized
+	}
+	return upper
+}
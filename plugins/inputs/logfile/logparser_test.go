@@ -0,0 +1,230 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initParser(t *testing.T, parserType string, expression string, source string) *LogParser {
+	parser := &LogParser{Type: parserType, Expression: expression, Source: source}
+	require.NoError(t, parser.init())
+	return parser
+}
+
+func TestLogParserInit(t *testing.T) {
+	assert.Error(t, (&LogParser{Type: "xml"}).init())
+	assert.Error(t, (&LogParser{Type: regexParserType, Expression: "abc)"}).init())
+	assert.Error(t, (&LogParser{Type: regexParserType, Expression: "(\\w+) (\\w+)"}).init())
+	assert.NoError(t, (&LogParser{Type: regexParserType, Expression: "(?P<level>\\w+) (\\w+)"}).init())
+	for _, parserType := range validParserTypes {
+		if parserType != regexParserType {
+			assert.NoError(t, (&LogParser{Type: parserType}).init())
+		}
+	}
+}
+
+func TestLogParserParse(t *testing.T) {
+	testCases := map[string]struct {
+		parser *LogParser
+		msg    string
+		want   map[string]interface{}
+	}{
+		"json": {
+			parser: initParser(t, jsonParserType, "", ""),
+			msg:    `{"level":"info","count":3,"nested":{"a":"b"}}`,
+			want: map[string]interface{}{
+				"level":  "info",
+				"count":  json.Number("3"),
+				"nested": map[string]interface{}{"a": "b"},
+			},
+		},
+		"logfmt": {
+			parser: initParser(t, logfmtParserType, "", ""),
+			msg:    `level=warn msg="disk almost full" used=91`,
+			want:   map[string]interface{}{"level": "warn", "msg": "disk almost full", "used": "91"},
+		},
+		"regex": {
+			parser: initParser(t, regexParserType, `^(?P<time>\S+) (?P<level>\w+) (?P<msg>.*)$`, ""),
+			msg:    "2024-01-02T03:04:05Z ERROR connection refused",
+			want:   map[string]interface{}{"time": "2024-01-02T03:04:05Z", "level": "ERROR", "msg": "connection refused"},
+		},
+		"syslog_rfc5424": {
+			parser: initParser(t, syslogRFC5424ParserType, "", ""),
+			msg:    `<165>1 2024-01-02T03:04:05Z host1 app 1234 ID47 - started`,
+			want: map[string]interface{}{
+				"priority":  int64(165),
+				"facility":  "local4",
+				"severity":  "notice",
+				"version":   int64(1),
+				"timestamp": "2024-01-02T03:04:05Z",
+				"hostname":  "host1",
+				"appname":   "app",
+				"procid":    "1234",
+				"msgid":     "ID47",
+				"message":   "started",
+			},
+		},
+		"apache_combined": {
+			parser: initParser(t, apacheCombinedParserType, "", ""),
+			msg:    `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`,
+			want: map[string]interface{}{
+				"remote_addr":     "127.0.0.1",
+				"ident":           "-",
+				"remote_user":     "frank",
+				"time":            "10/Oct/2000:13:55:36 -0700",
+				"method":          "GET",
+				"path":            "/apache_pb.gif",
+				"protocol":        "HTTP/1.0",
+				"status":          int64(200),
+				"body_bytes_sent": int64(2326),
+				"referer":         "http://www.example.com/start.html",
+				"user_agent":      "Mozilla/4.08",
+			},
+		},
+		"nginx_common": {
+			parser: initParser(t, nginxCombinedParserType, "", ""),
+			msg:    `10.0.0.1 - - [10/Oct/2000:13:55:36 +0000] "POST /api HTTP/1.1" 404 -`,
+			want: map[string]interface{}{
+				"remote_addr":     "10.0.0.1",
+				"ident":           "-",
+				"remote_user":     "-",
+				"time":            "10/Oct/2000:13:55:36 +0000",
+				"method":          "POST",
+				"path":            "/api",
+				"protocol":        "HTTP/1.1",
+				"status":          int64(404),
+				"body_bytes_sent": "-",
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fields := map[string]interface{}{}
+			require.NoError(t, testCase.parser.Parse(testCase.msg, fields))
+			assert.Equal(t, testCase.want, fields)
+		})
+	}
+}
+
+func TestLogParserParseNoMatch(t *testing.T) {
+	for _, parser := range []*LogParser{
+		initParser(t, jsonParserType, "", ""),
+		initParser(t, logfmtParserType, "", ""),
+		initParser(t, regexParserType, `^(?P<level>[A-Z]+):`, ""),
+		initParser(t, syslogRFC5424ParserType, "", ""),
+		initParser(t, apacheCombinedParserType, "", ""),
+	} {
+		assert.Error(t, parser.Parse("", map[string]interface{}{}), parser.Type)
+		assert.Error(t, parser.Parse("[1,2,3]", map[string]interface{}{}), parser.Type)
+	}
+}
+
+func TestLogParserSyslogConcurrent(t *testing.T) {
+	parser := initParser(t, syslogRFC5424ParserType, "", "")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				fields := map[string]interface{}{}
+				msg := fmt.Sprintf("<165>1 2024-01-02T03:04:05Z host%d app - - - message %d", i, j)
+				if assert.NoError(t, parser.Parse(msg, fields)) {
+					assert.Equal(t, fmt.Sprintf("host%d", i), fields["hostname"])
+					assert.Equal(t, fmt.Sprintf("message %d", j), fields["message"])
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLogParserSource(t *testing.T) {
+	parser := initParser(t, jsonParserType, "", "payload")
+	fields := map[string]interface{}{"payload": `{"user":"bob"}`}
+	require.NoError(t, parser.Parse("ignored", fields))
+	assert.Equal(t, "bob", fields["user"])
+
+	assert.Error(t, parser.Parse("ignored", map[string]interface{}{}))
+	assert.Error(t, parser.Parse("ignored", map[string]interface{}{"payload": int64(1)}))
+}
+
+func TestParserPipelineProcess(t *testing.T) {
+	pipeline := &ParserPipeline{
+		parsers: []*LogParser{
+			initParser(t, regexParserType, `^(?P<time>\S+) (?P<severity>\w+) (?P<payload>.*)$`, ""),
+			initParser(t, jsonParserType, "", "payload"),
+		},
+		timestampField: "time",
+		timezone:       time.UTC,
+		levelField:     "severity",
+		renderJSON:     true,
+	}
+
+	e := &LogEvent{msg: `2024-01-02T03:04:05.5Z warning {"path":"/a?b=1&c=<2>"}`}
+	pipeline.Process(e)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 5e8, time.UTC), e.t.UTC())
+	assert.Equal(t, "WARN", e.Fields()[LevelFieldKey])
+	assert.NotContains(t, e.Fields(), "severity")
+	assert.Equal(t, `{"level":"WARN","path":"/a?b=1&c=<2>","payload":"{\"path\":\"/a?b=1&c=<2>\"}","time":"2024-01-02T03:04:05.5Z"}`, e.msg)
+
+	// the fields of the parsers which matched are kept
+	e = &LogEvent{msg: `2024-01-02T03:04:05Z info not json`}
+	pipeline.Process(e)
+	assert.Equal(t, "INFO", e.Fields()[LevelFieldKey])
+	assert.Equal(t, "not json", e.Fields()["payload"])
+
+	// the event is left untouched when no parser matches
+	ts := time.Now()
+	e = &LogEvent{msg: "unstructured", t: ts}
+	pipeline.Process(e)
+	assert.Equal(t, "unstructured", e.msg)
+	assert.Equal(t, ts, e.t)
+	assert.Nil(t, e.Fields())
+}
+
+func TestParserPipelineTimestamp(t *testing.T) {
+	pipeline := &ParserPipeline{timezone: time.UTC}
+	testCases := map[string]struct {
+		val  interface{}
+		want time.Time
+	}{
+		"epoch seconds":      {val: json.Number("1704164645"), want: time.Unix(1704164645, 0)},
+		"epoch milliseconds": {val: json.Number("1704164645123"), want: time.UnixMilli(1704164645123)},
+		"epoch string":       {val: "1704164645", want: time.Unix(1704164645, 0)},
+		"rfc3339":            {val: "2024-01-02T03:04:05Z", want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		"common log format":  {val: "02/Jan/2024:03:04:05 +0000", want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := pipeline.parseTimestamp(testCase.val)
+			require.NoError(t, err)
+			assert.True(t, testCase.want.Equal(got), "want %v, got %v", testCase.want, got)
+		})
+	}
+
+	_, err := pipeline.parseTimestamp("yesterday")
+	assert.Error(t, err)
+
+	pipeline.timestampLayout = []string{"Jan _2 15:04:05 2006"}
+	got, err := pipeline.parseTimestamp("Jan  2 03:04:05 2024")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), got)
+}
+
+func TestNormalizeLevel(t *testing.T) {
+	assert.Equal(t, "WARN", normalizeLevel("warning"))
+	assert.Equal(t, "ERROR", normalizeLevel(" err "))
+	assert.Equal(t, "FATAL", normalizeLevel("crit"))
+	assert.Equal(t, "INFO", normalizeLevel("Informational"))
+	assert.Equal(t, "CUSTOM", normalizeLevel("custom"))
+}
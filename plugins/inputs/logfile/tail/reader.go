@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package tail
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// TailReader reads the lines of a stream which can neither be seeked nor followed, e.g. the
+// decompressed content of a rotated archive. The stream is read once and Lines is closed when
+// the end of the stream is reached. A Location relative to the start of the stream is honored
+// by discarding the content before the offset. Follow, ReOpen, Poll and Pipe are ignored.
+func TailReader(name string, r io.ReadCloser, config Config) *Tail {
+	t := &Tail{
+		Filename:      name,
+		Lines:         make(chan *Line),
+		Config:        config,
+		FileDeletedCh: make(chan bool),
+	}
+
+	if t.Logger == nil {
+		t.Logger = models.NewLogger("inputs", "tail", "")
+	}
+
+	OpenFileCount.Add(1)
+	go t.tailReaderSync(r)
+
+	return t
+}
+
+func (tail *Tail) tailReaderSync(r io.ReadCloser) {
+	defer tail.Done()
+	defer tail.close()
+	defer func() {
+		r.Close()
+		OpenFileCount.Add(-1)
+	}()
+
+	tail.lk.Lock()
+	if tail.MaxLineSize > 0 {
+		tail.reader = bufio.NewReaderSize(r, tail.MaxLineSize+2)
+	} else {
+		tail.reader = bufio.NewReader(r)
+	}
+	tail.lk.Unlock()
+
+	if tail.Location != nil && tail.Location.Whence == io.SeekStart && tail.Location.Offset > 0 {
+		discarded, err := io.CopyN(io.Discard, tail.reader, tail.Location.Offset)
+		tail.curOffset = discarded
+		if err != nil {
+			if err != io.EOF {
+				tail.Killf("Error skipping to offset %d in %s: %s", tail.Location.Offset, tail.Filename, err)
+			}
+			return
+		}
+	}
+
+	for {
+		line, err := tail.readLine()
+		if err == io.EOF {
+			if line != "" {
+				tail.sendLine(line, tail.curOffset)
+			}
+			return
+		} else if err != nil {
+			tail.Killf("Error reading %s: %s", tail.Filename, err)
+			return
+		}
+		tail.sendLine(line, tail.curOffset)
+
+		select {
+		case <-tail.Dying():
+			if tail.Err() == errStopAtEOF {
+				continue
+			}
+			return
+		default:
+		}
+	}
+}
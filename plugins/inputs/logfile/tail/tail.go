@@ -56,7 +56,7 @@ type Config struct {
 	Location    *SeekInfo // Seek to this location before tailing
 	ReOpen      bool      // Reopen recreated files (tail -F)
 	MustExist   bool      // Fail early if the file does not exist
-	Poll        bool      // Poll for file changes instead of using inotify
+	Poll        bool      // Poll for file changes instead of using inotify, named pipes are always polled
 	Pipe        bool      // Is a named pipe (mkfifo)
 	RateLimiter limiter
 
@@ -111,7 +111,13 @@ func TailFile(filename string, config Config) (*Tail, error) {
 		t.Logger = models.NewLogger("inputs", "tail", "")
 	}
 
-	if t.Poll {
+	if !t.Poll && !t.Pipe {
+		if err := watch.InotifySupported(filename); err != nil {
+			t.Logger.Infof("Polling %s for changes, unable to use inotify: %v", filename, err)
+			t.Poll = true
+		}
+	}
+	if t.Poll || t.Pipe {
 		t.watcher = watch.NewPollingFileWatcher(filename)
 	} else {
 		t.watcher = watch.NewInotifyFileWatcher(filename)
@@ -435,6 +441,13 @@ func (tail *Tail) watchChanges() error {
 		return err
 	}
 	tail.changes, err = tail.watcher.ChangeEvents(&tail.Tomb, pos)
+	if err != nil && !tail.Poll {
+		// the number of inotify watches is limited by fs.inotify.max_user_watches
+		tail.Logger.Warnf("Polling %s for changes, unable to watch it with inotify: %v", tail.Filename, err)
+		tail.Poll = true
+		tail.watcher = watch.NewPollingFileWatcher(tail.Filename)
+		tail.changes, err = tail.watcher.ChangeEvents(&tail.Tomb, pos)
+	}
 	return err
 }
 
@@ -5,6 +5,7 @@ package tail
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -197,3 +198,19 @@ func tearDown(tmpfile *os.File) {
 	exitOnDeletionCheckDuration = time.Minute
 	exitOnDeletionWaitDuration = 5 * time.Minute
 }
+
+func TestTailReader(t *testing.T) {
+	r := io.NopCloser(strings.NewReader("line1\nline2\r\nline3"))
+	tail := TailReader("reader", r, Config{Location: &SeekInfo{Offset: 6, Whence: io.SeekStart}})
+
+	var lines []string
+	var offsets []int64
+	for line := range tail.Lines {
+		assert.NoError(t, line.Err)
+		lines = append(lines, line.Text)
+		offsets = append(offsets, line.Offset)
+	}
+	assert.Equal(t, []string{"line2", "line3"}, lines)
+	assert.Equal(t, []int64{13, 18}, offsets)
+	assert.NoError(t, tail.Wait())
+}
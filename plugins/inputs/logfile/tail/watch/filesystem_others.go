@@ -0,0 +1,19 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+// +build !linux
+
+package watch
+
+import (
+	"errors"
+)
+
+var errInotifyUnsupported = errors.New("inotify is only supported on Linux")
+
+// InotifySupported returns an error when the changes of the path cannot be watched with inotify
+// and should be polled instead.
+func InotifySupported(_ string) error {
+	return errInotifyUnsupported
+}
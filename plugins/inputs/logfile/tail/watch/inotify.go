@@ -27,6 +27,7 @@ func NewInotifyFileWatcher(filename string) *InotifyFileWatcher {
 func (fw *InotifyFileWatcher) BlockUntilExists(t *tomb.Tomb) error {
 	err := WatchCreate(fw.Filename)
 	if err != nil {
+		RemoveWatchCreate(fw.Filename)
 		return err
 	}
 	defer RemoveWatchCreate(fw.Filename)
@@ -66,6 +67,8 @@ func (fw *InotifyFileWatcher) BlockUntilExists(t *tomb.Tomb) error {
 func (fw *InotifyFileWatcher) ChangeEvents(t *tomb.Tomb, pos int64) (*FileChanges, error) {
 	err := Watch(fw.Filename)
 	if err != nil {
+		// release the events channel of the failed watch
+		RemoveWatch(fw.Filename)
 		return nil, err
 	}
 
@@ -120,6 +123,7 @@ func (fw *InotifyFileWatcher) ChangeEvents(t *tomb.Tomb, pos int64) (*FileChange
 						return
 					}
 					log.Printf("E! [logfile] Failed to stat file %v: %v", fw.Filename, err)
+					continue
 				}
 				fw.Size = fi.Size()
 
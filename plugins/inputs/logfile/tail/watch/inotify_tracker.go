@@ -4,6 +4,7 @@
 package watch
 
 import (
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
@@ -53,6 +54,8 @@ var (
 	}
 
 	logger = log.New(os.Stderr, "", log.LstdFlags)
+
+	errNoWatcher = errors.New("no inotify watcher")
 )
 
 // Watch signals the run goroutine to begin watching the input filename
@@ -133,6 +136,10 @@ func (shared *InotifyTracker) addWatch(winfo *watchInfo) error {
 	shared.mux.Lock()
 	defer shared.mux.Unlock()
 
+	if shared.watcher == nil {
+		return errNoWatcher
+	}
+
 	if shared.chans[winfo.fname] == nil {
 		shared.chans[winfo.fname] = make(chan fsnotify.Event)
 	}
@@ -173,8 +180,14 @@ func (shared *InotifyTracker) removeWatch(winfo *watchInfo) error {
 		// Watch for new files to be created in the parent directory.
 		fname = filepath.Dir(fname)
 	}
-	shared.watchNums[fname]--
-	watchNum := shared.watchNums[fname]
+	watchNum, ok := shared.watchNums[fname]
+	if !ok {
+		// the watch was not added
+		shared.mux.Unlock()
+		return nil
+	}
+	watchNum--
+	shared.watchNums[fname] = watchNum
 	if watchNum == 0 {
 		delete(shared.watchNums, fname)
 	}
@@ -214,7 +227,17 @@ func (shared *InotifyTracker) sendEvent(event fsnotify.Event) {
 func (shared *InotifyTracker) run() {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		log.Printf("E! [logfile] Failed to create Watcher")
+		// the number of inotify instances is limited by fs.inotify.max_user_instances, the tails
+		// poll the files when their watch cannot be added
+		log.Printf("E! [logfile] Failed to create Watcher: %v", err)
+		for {
+			select {
+			case <-shared.watch:
+				shared.error <- errNoWatcher
+			case <-shared.remove:
+				shared.error <- nil
+			}
+		}
 	}
 	shared.watcher = watcher
 
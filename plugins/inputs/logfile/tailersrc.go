@@ -7,21 +7,22 @@ import (
 	"bytes"
 	"log"
 	"os"
-	"strconv"
 	"sync"
 	"time"
 
 	"golang.org/x/text/encoding"
 
 	"github.com/aws/amazon-cloudwatch-agent/extension/entitystore"
+	"github.com/aws/amazon-cloudwatch-agent/internal/checkpoint"
+	"github.com/aws/amazon-cloudwatch-agent/internal/redact"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/plugins/inputs/logfile/tail"
+	"github.com/aws/amazon-cloudwatch-agent/profiler"
 	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatchlogs"
 )
 
 const (
-	stateFileMode = 0644
-	bufferLimit   = 50
+	bufferLimit = 50
 )
 
 var (
@@ -44,6 +45,7 @@ type LogEvent struct {
 	t      time.Time
 	offset fileOffset
 	src    *tailerSrc
+	fields map[string]interface{}
 }
 
 func (le LogEvent) Message() string {
@@ -54,6 +56,10 @@ func (le LogEvent) Time() time.Time {
 	return le.t
 }
 
+func (le LogEvent) Fields() map[string]interface{} {
+	return le.fields
+}
+
 func (le LogEvent) Done() {
 	le.src.Done(le.offset)
 }
@@ -64,7 +70,9 @@ type tailerSrc struct {
 	class           string
 	fileGlobPath    string
 	destination     string
-	stateFilePath   string
+	destinations    []string
+	stateKey        string
+	checkpoints     *checkpoint.Store
 	tailer          *tail.Tail
 	autoRemoval     bool
 	timestampFn     func(string) time.Time
@@ -73,24 +81,36 @@ type tailerSrc struct {
 	truncateSuffix  string
 	retentionInDays int
 
-	outputFn        func(logs.LogEvent)
-	isMLStart       func(string) bool
-	filters         []*LogFilter
-	offsetCh        chan fileOffset
-	done            chan struct{}
-	startTailerOnce sync.Once
-	cleanUpFns      []func()
+	outputFn    func(logs.LogEvent)
+	isMLStart   func(string) bool
+	filters     []*LogFilter
+	parsers     *ParserPipeline
+	redactor    *redact.Redactor
+	logMetrics  *logMetricRecorder
+	offsetCh    chan fileOffset
+	saveFailing bool
+	// keepRotatedState keeps the checkpoint of the rotated file, so its archive is only read from
+	// the offset the file was read up to.
+	keepRotatedState bool
+	fingerprint      string
+	fingerprintSize  int64
+	done             chan struct{}
+	startTailerOnce  sync.Once
+	cleanUpFns       []func()
 }
 
-// Verify tailerSrc implements LogSrc
-var _ logs.LogSrc = (*tailerSrc)(nil)
+// Verify tailerSrc implements MultiDestinationLogSrc
+var _ logs.MultiDestinationLogSrc = (*tailerSrc)(nil)
 
 func NewTailerSrc(
-	group, stream, destination, stateFilePath, logClass, fileGlobPath string,
+	group, stream, destination, stateKey, logClass, fileGlobPath string,
+	checkpoints *checkpoint.Store,
 	tailer *tail.Tail,
 	autoRemoval bool,
 	isMultilineStartFn func(string) bool,
 	filters []*LogFilter,
+	parsers *ParserPipeline,
+	redactor *redact.Redactor,
 	timestampFn func(string) time.Time,
 	enc encoding.Encoding,
 	maxEventSize int,
@@ -101,13 +121,16 @@ func NewTailerSrc(
 		group:           group,
 		stream:          stream,
 		destination:     destination,
-		stateFilePath:   stateFilePath,
+		stateKey:        stateKey,
+		checkpoints:     checkpoints,
 		class:           logClass,
 		fileGlobPath:    fileGlobPath,
 		tailer:          tailer,
 		autoRemoval:     autoRemoval,
 		isMLStart:       isMultilineStartFn,
 		filters:         filters,
+		parsers:         parsers,
+		redactor:        redactor,
 		timestampFn:     timestampFn,
 		enc:             enc,
 		maxEventSize:    maxEventSize,
@@ -117,7 +140,6 @@ func NewTailerSrc(
 		offsetCh: make(chan fileOffset, 2000),
 		done:     make(chan struct{}),
 	}
-	go ts.runSaveState()
 	return ts
 }
 
@@ -126,7 +148,15 @@ func (ts *tailerSrc) SetOutput(fn func(logs.LogEvent)) {
 		return
 	}
 	ts.outputFn = fn
-	ts.startTailerOnce.Do(func() { go ts.runTail() })
+	ts.startTailerOnce.Do(func() {
+		// Only the started sources retain the store, runSaveState releases it once the final
+		// offset has been saved
+		if ts.checkpoints != nil {
+			ts.checkpoints.Retain()
+		}
+		go ts.runSaveState()
+		go ts.runTail()
+	})
 }
 
 func (ts *tailerSrc) Group() string {
@@ -145,6 +175,10 @@ func (ts *tailerSrc) Destination() string {
 	return ts.destination
 }
 
+func (ts *tailerSrc) Destinations() []string {
+	return ts.destinations
+}
+
 func (ts *tailerSrc) Retention() int {
 	return ts.retentionInDays
 }
@@ -194,17 +228,7 @@ func (ts *tailerSrc) runTail() {
 		case line, ok := <-ts.tailer.Lines:
 			if !ok {
 				if msgBuf.Len() > 0 {
-					msg := msgBuf.String()
-					e := &LogEvent{
-						msg:    msg,
-						t:      ts.timestampFn(msg),
-						offset: *fo,
-						src:    ts,
-					}
-
-					if ShouldPublish(ts.group, ts.stream, ts.filters, e) {
-						ts.outputFn(e)
-					}
+					ts.publish(msgBuf.String(), *fo)
 				}
 				return
 			}
@@ -248,18 +272,9 @@ func (ts *tailerSrc) runTail() {
 			}
 
 			if msgBuf.Len() > 0 {
-				msg := msgBuf.String()
-				e := &LogEvent{
-					msg:    msg,
-					t:      ts.timestampFn(msg),
-					offset: *fo,
-					src:    ts,
-				}
 				// Note: This only checks against the truncated log message, so it is not necessary to load
 				//       the entire log message for filtering.
-				if ShouldPublish(ts.group, ts.stream, ts.filters, e) {
-					ts.outputFn(e)
-				}
+				ts.publish(msgBuf.String(), *fo)
 			}
 
 			msgBuf.Reset()
@@ -275,16 +290,7 @@ func (ts *tailerSrc) runTail() {
 				continue
 			}
 
-			msg := msgBuf.String()
-			e := &LogEvent{
-				msg:    msg,
-				t:      ts.timestampFn(msg),
-				offset: *fo,
-				src:    ts,
-			}
-			if ShouldPublish(ts.group, ts.stream, ts.filters, e) {
-				ts.outputFn(e)
-			}
+			ts.publish(msgBuf.String(), *fo)
 			msgBuf.Reset()
 			cnt = 0
 		case <-ts.done:
@@ -293,6 +299,111 @@ func (ts *tailerSrc) runTail() {
 	}
 }
 
+// publish outputs the log event unless it is dropped by the filters or the redactions. The log
+// metrics are recorded before the filters, so that dropped log events are still measured.
+func (ts *tailerSrc) publish(msg string, offset fileOffset) {
+	e := ts.newLogEvent(msg, offset)
+	if ts.logMetrics != nil {
+		ts.logMetrics.record(e)
+	}
+	if !ShouldPublish(ts.group, ts.stream, ts.filters, e) {
+		return
+	}
+	if ts.redactor != nil && !ts.redact(e) {
+		return
+	}
+	ts.outputFn(e)
+}
+
+func (ts *tailerSrc) newLogEvent(msg string, offset fileOffset) *LogEvent {
+	e := &LogEvent{
+		msg:    msg,
+		t:      ts.timestampFn(msg),
+		offset: offset,
+		src:    ts,
+	}
+	if ts.parsers != nil {
+		ts.parsers.Process(e)
+	}
+	return e
+}
+
+// redact removes the secrets from the log event message and from the string values of its parsed
+// fields, it returns false when the event must be dropped.
+func (ts *tailerSrc) redact(e *LogEvent) bool {
+	counts := map[string]int{}
+	drop := false
+	redactString := func(s string) string {
+		result := ts.redactor.Redact(s)
+		for name, count := range result.Counts {
+			counts[name] += count
+		}
+		drop = drop || result.Drop
+		return result.Message
+	}
+	msg := redactString(e.msg)
+	for k, v := range e.fields {
+		e.fields[k] = redactValue(v, redactString)
+	}
+	for name, count := range counts {
+		profiler.Profiler.AddStats([]string{"logfile", ts.group, ts.stream, "redactions", name}, float64(count))
+	}
+	if drop {
+		profiler.Profiler.AddStats([]string{"logfile", ts.group, ts.stream, "messages", "redaction_dropped"}, 1)
+		return false
+	}
+	e.msg = msg
+	return true
+}
+
+// redactValue applies redactString to the strings nested in the parsed field value.
+func redactValue(v interface{}, redactString func(string) string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return redactString(val)
+	case map[string]interface{}:
+		for k, nested := range val {
+			val[k] = redactValue(nested, redactString)
+		}
+	case []interface{}:
+		for i, nested := range val {
+			val[i] = redactValue(nested, redactString)
+		}
+	}
+	return v
+}
+
+// updateFingerprint hashes the beginning of the file, up to the offset read. The fingerprint is
+// updated while the file is read, since the file may be renamed by the time it is rotated.
+func (ts *tailerSrc) updateFingerprint(offset int64) {
+	f, err := os.Open(ts.tailer.Filename)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if fp, size, err := fingerprint(f, min(offset, fingerprintSize)); err == nil {
+		ts.fingerprint, ts.fingerprintSize = fp, size
+	}
+}
+
+// saveRotatedState keeps the offset the rotated file was read up to under the key of its
+// fingerprint, so the archive created by the rotation is only read from there.
+func (ts *tailerSrc) saveRotatedState(offset int64) {
+	if ts.checkpoints == nil || !ts.keepRotatedState || ts.fingerprint == "" || offset == 0 {
+		return
+	}
+	err := ts.checkpoints.Set(checkpoint.Checkpoint{
+		Key:             rotatedStateKey(ts.stateKey, ts.fingerprint),
+		File:            ts.tailer.Filename,
+		Offset:          offset,
+		Fingerprint:     ts.fingerprint,
+		FingerprintSize: ts.fingerprintSize,
+	})
+	if err != nil {
+		log.Printf("W! [logfile] Unable to save offset %d of rotated file %s, its archive may be read from the beginning: %v", offset, ts.tailer.Filename, err)
+	}
+}
+
 func (ts *tailerSrc) cleanUp() {
 	if ts.autoRemoval {
 		if err := os.Remove(ts.tailer.Filename); err != nil {
@@ -310,14 +421,29 @@ func (ts *tailerSrc) cleanUp() {
 	}
 }
 
+// runSaveState saves the offset of the file to the checkpoint store, and releases the store once the
+// final offset has been saved.
 func (ts *tailerSrc) runSaveState() {
 	t := time.NewTicker(100 * time.Millisecond)
 	defer t.Stop()
+	defer func() {
+		if ts.checkpoints == nil {
+			return
+		}
+		if err := ts.checkpoints.Close(); err != nil {
+			log.Printf("E! [logfile] Error happened during final checkpoint saving of logfile %s, duplicate log maybe sent at next start: %v", ts.tailer.Filename, err)
+		}
+	}()
 
 	var offset, lastSavedOffset fileOffset
 	for {
 		select {
 		case o := <-ts.offsetCh:
+			if o.seq > offset.seq {
+				// the file was truncated, e.g. by a copytruncate rotation
+				ts.saveRotatedState(offset.offset)
+				ts.fingerprint, ts.fingerprintSize = "", 0
+			}
 			if o.seq > offset.seq || (o.seq == offset.seq && o.offset > offset.offset) {
 				offset = o
 			}
@@ -325,34 +451,41 @@ func (ts *tailerSrc) runSaveState() {
 			if offset == lastSavedOffset {
 				continue
 			}
-			err := ts.saveState(offset.offset)
-			if err != nil {
-				log.Printf("E! [logfile] Error happened when saving file state %s to file state folder %s: %v", ts.tailer.Filename, ts.stateFilePath, err)
-				continue
-			}
+			ts.saveState(offset.offset)
 			lastSavedOffset = offset
 		case <-ts.tailer.FileDeletedCh:
-			log.Printf("W! [logfile] deleting state file %s", ts.stateFilePath)
-			err := os.Remove(ts.stateFilePath)
-			if err != nil {
-				log.Printf("W! [logfile] Error happened while deleting state file %s on cleanup: %v", ts.stateFilePath, err)
+			ts.saveRotatedState(offset.offset)
+			if ts.checkpoints != nil {
+				log.Printf("W! [logfile] deleting checkpoint %s", ts.stateKey)
+				if err := ts.checkpoints.Delete(ts.stateKey); err != nil {
+					log.Printf("W! [logfile] Unable to delete checkpoint %s of removed file %s: %v", ts.stateKey, ts.tailer.Filename, err)
+				}
 			}
 			return
 		case <-ts.done:
-			err := ts.saveState(offset.offset)
-			if err != nil {
-				log.Printf("E! [logfile] Error happened during final file state saving of logfile %s to file state folder %s, duplicate log maybe sent at next start: %v", ts.tailer.Filename, ts.stateFilePath, err)
-			}
+			ts.saveState(offset.offset)
 			return
 		}
 	}
 }
 
-func (ts *tailerSrc) saveState(offset int64) error {
-	if ts.stateFilePath == "" || offset == 0 {
-		return nil
+func (ts *tailerSrc) saveState(offset int64) {
+	if ts.checkpoints == nil || offset == 0 {
+		return
 	}
-
-	content := []byte(strconv.FormatInt(offset, 10) + "\n" + ts.tailer.Filename)
-	return os.WriteFile(ts.stateFilePath, content, stateFileMode)
+	if ts.keepRotatedState && ts.fingerprintSize < fingerprintSize && offset > ts.fingerprintSize {
+		ts.updateFingerprint(offset)
+	}
+	err := ts.checkpoints.Set(checkpoint.Checkpoint{
+		Key:             ts.stateKey,
+		File:            ts.tailer.Filename,
+		Offset:          offset,
+		Fingerprint:     ts.fingerprint,
+		FingerprintSize: ts.fingerprintSize,
+	})
+	// log once per failure instead of at every save while the store is failing
+	if err != nil && !ts.saveFailing {
+		log.Printf("W! [logfile] Unable to save offset %d of %s, duplicate log may be sent at next start: %v", offset, ts.tailer.Filename, err)
+	}
+	ts.saveFailing = err != nil
 }
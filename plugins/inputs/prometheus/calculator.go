@@ -9,7 +9,8 @@ import (
 )
 
 type Calculator struct {
-	deltaCalculator *DeltaCalculator
+	deltaCalculator     *DeltaCalculator
+	histogramCalculator *HistogramCalculator
 }
 
 func appendValidValue(pmb PrometheusMetricBatch, pm *PrometheusMetric) PrometheusMetricBatch {
@@ -25,6 +26,7 @@ func (c *Calculator) Calculate(pmb PrometheusMetricBatch) (result PrometheusMetr
 	var gauges PrometheusMetricBatch
 	var counters PrometheusMetricBatch
 	var summaries PrometheusMetricBatch
+	var histograms PrometheusMetricBatch
 
 	for _, pm := range pmb {
 		if pm.isGauge() {
@@ -43,17 +45,22 @@ func (c *Calculator) Calculate(pmb PrometheusMetricBatch) (result PrometheusMetr
 			} else {
 				summaries = appendValidValue(summaries, pm)
 			}
+		} else if pm.isHistogram() {
+			histograms = append(histograms, pm)
 		}
 	}
 
 	result = append(result, gauges...)
 	result = append(result, counters...)
 	result = append(result, summaries...)
+	// assemble the buckets, sum and count of the histograms into the distributions of their delta
+	result = append(result, c.histogramCalculator.calculate(histograms)...)
 	return
 }
 
 func NewCalculator() *Calculator {
 	return &Calculator{
-		deltaCalculator: NewDeltaCalculator(),
+		deltaCalculator:     NewDeltaCalculator(),
+		histogramCalculator: NewHistogramCalculator(),
 	}
 }
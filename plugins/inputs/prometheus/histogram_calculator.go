@@ -0,0 +1,310 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/mapWithExpiry"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution/regular"
+)
+
+const bucketBoundLabel = "le"
+
+// classicHistogram is a classic histogram assembled from its <basename>_bucket, <basename>_sum and
+// <basename>_count series. The buckets are sorted by bound and their counts are cumulative.
+type classicHistogram struct {
+	bounds   []float64
+	buckets  []float64
+	sum      float64
+	count    float64
+	timeInMS int64
+}
+
+// HistogramCalculator assembles the classic and native histograms of a batch per series and converts
+// their delta since the previous scrape to distributions, like DeltaCalculator does for the counters.
+type HistogramCalculator struct {
+	preHistograms       *mapWithExpiry.MapWithExpiry
+	lastCleanUpTimeInMs int64
+}
+
+func (hc *HistogramCalculator) calculate(pmb PrometheusMetricBatch) (result PrometheusMetricBatch) {
+	var keys []string
+	series := map[string]*PrometheusMetric{}
+	classics := map[string]*classicHistogram{}
+	for _, pm := range pmb {
+		if pm.nativeHistogram != nil {
+			if calculatedMetric := hc.calculateNative(pm); calculatedMetric != nil {
+				result = append(result, calculatedMetric)
+			}
+			continue
+		}
+		hpm, suffix := histogramSeriesOf(pm)
+		if hpm == nil {
+			log.Printf("D! HistogramCalculator.calculate: Drop histogram series without suffix: %v", pm.metricName)
+			continue
+		}
+		key := getUniqMetricKey(hpm)
+		h, ok := classics[key]
+		if !ok {
+			h = &classicHistogram{timeInMS: pm.timeInMS}
+			classics[key] = h
+			series[key] = hpm
+			keys = append(keys, key)
+		}
+		switch suffix {
+		case histogramBucketSuffix:
+			bound, err := strconv.ParseFloat(pm.tags[bucketBoundLabel], 64)
+			if err != nil {
+				log.Printf("D! HistogramCalculator.calculate: Drop bucket with invalid bound: %v", pm)
+				continue
+			}
+			h.bounds = append(h.bounds, bound)
+			h.buckets = append(h.buckets, pm.metricValue)
+		case histogramSummarySumSuffix:
+			h.sum = pm.metricValue
+		case histogramSummaryCountSuffix:
+			h.count = pm.metricValue
+		}
+	}
+	for _, key := range keys {
+		if calculatedMetric := hc.calculateClassic(key, series[key], classics[key]); calculatedMetric != nil {
+			result = append(result, calculatedMetric)
+		}
+	}
+	hc.cleanUp(pmb)
+	return
+}
+
+// histogramSeriesOf returns the metric of the histogram a classic histogram series belongs to, and
+// the suffix of the series.
+func histogramSeriesOf(pm *PrometheusMetric) (*PrometheusMetric, string) {
+	for _, suffix := range histogramSummarySuffixes {
+		if !strings.HasSuffix(pm.metricName, suffix) || pm.metricName == suffix {
+			continue
+		}
+		tags := make(map[string]string, len(pm.tags))
+		for k, v := range pm.tags {
+			if k != bucketBoundLabel {
+				tags[k] = v
+			}
+		}
+		return &PrometheusMetric{
+			tags:       tags,
+			metricName: strings.TrimSuffix(pm.metricName, suffix),
+			metricType: pm.metricType,
+			timeInMS:   pm.timeInMS,
+		}, suffix
+	}
+	return nil, ""
+}
+
+func (hc *HistogramCalculator) calculateClassic(key string, pm *PrometheusMetric, cur *classicHistogram) *PrometheusMetric {
+	if !cur.isValid() {
+		log.Printf("D! HistogramCalculator.calculate: Drop histogram with NaN or Inf value: %v", pm.metricName)
+		hc.preHistograms.Delete(key)
+		return nil
+	}
+	sort.Sort(cur)
+	v, ok := hc.preHistograms.Get(key)
+	hc.preHistograms.Set(key, cur)
+	if !ok {
+		return nil
+	}
+	pre := v.(*classicHistogram)
+	if cur.timeInMS <= pre.timeInMS || !cur.hasBounds(pre) {
+		// the delta is computed from the next scrape when the buckets changed
+		return nil
+	}
+	delta := cur
+	if !cur.isReset(pre) {
+		delta = &classicHistogram{
+			bounds:  cur.bounds,
+			buckets: make([]float64, len(cur.buckets)),
+			sum:     cur.sum - pre.sum,
+			count:   cur.count - pre.count,
+		}
+		for i := range cur.buckets {
+			delta.buckets[i] = cur.buckets[i] - pre.buckets[i]
+		}
+	}
+	if delta.count <= 0 {
+		return nil
+	}
+	var values, counts []float64
+	lower := 0.0
+	for i, bound := range delta.bounds {
+		count := delta.buckets[i]
+		if i > 0 {
+			count -= delta.buckets[i-1]
+		}
+		if count > 0 {
+			values = append(values, bucketValue(lower, bound))
+			counts = append(counts, count)
+		}
+		if !math.IsInf(bound, 1) {
+			lower = bound
+		}
+	}
+	pm.distribution = newHistogramDistribution(values, counts, delta.sum, delta.count)
+	return pm
+}
+
+func (hc *HistogramCalculator) calculateNative(pm *PrometheusMetric) *PrometheusMetric {
+	key := getUniqMetricKey(pm)
+	cur := pm.nativeHistogram
+	if math.IsNaN(cur.Sum) || math.IsInf(cur.Sum, 0) {
+		log.Printf("D! HistogramCalculator.calculate: Drop histogram with NaN or Inf value: %v", pm.metricName)
+		hc.preHistograms.Delete(key)
+		return nil
+	}
+	v, ok := hc.preHistograms.Get(key)
+	hc.preHistograms.Set(key, &nativeHistogram{histogram: cur, timeInMS: pm.timeInMS})
+	if !ok {
+		return nil
+	}
+	pre := v.(*nativeHistogram)
+	if pm.timeInMS <= pre.timeInMS {
+		return nil
+	}
+	delta := cur
+	if !cur.DetectReset(pre.histogram) {
+		delta = cur.Copy().Sub(pre.histogram)
+	}
+	if delta.Count <= 0 {
+		return nil
+	}
+	var values, counts []float64
+	if zero := delta.ZeroBucket(); zero.Count > 0 {
+		values = append(values, 0)
+		counts = append(counts, zero.Count)
+	}
+	// the bounds of the negative buckets are negative, so their samples are recorded at the negated midpoints
+	for _, it := range []histogram.BucketIterator[float64]{delta.NegativeBucketIterator(), delta.PositiveBucketIterator()} {
+		for it.Next() {
+			if bucket := it.At(); bucket.Count > 0 {
+				values = append(values, bucketValue(bucket.Lower, bucket.Upper))
+				counts = append(counts, bucket.Count)
+			}
+		}
+	}
+	pm.nativeHistogram = nil
+	pm.distribution = newHistogramDistribution(values, counts, delta.Sum, delta.Count)
+	return pm
+}
+
+// Clean up the stale cache periodically
+func (hc *HistogramCalculator) cleanUp(pmb PrometheusMetricBatch) {
+	if len(pmb) == 0 {
+		return
+	}
+	if curTimeInMS := pmb[0].timeInMS; curTimeInMS-hc.lastCleanUpTimeInMs >= CleanUpTimeThreshold {
+		hc.preHistograms.CleanUp(time.Now())
+		hc.lastCleanUpTimeInMs = curTimeInMS
+	}
+}
+
+// nativeHistogram is the previous value of a native histogram.
+type nativeHistogram struct {
+	histogram *histogram.FloatHistogram
+	timeInMS  int64
+}
+
+func (h *classicHistogram) Len() int {
+	return len(h.bounds)
+}
+
+func (h *classicHistogram) Less(i, j int) bool {
+	return h.bounds[i] < h.bounds[j]
+}
+
+func (h *classicHistogram) Swap(i, j int) {
+	h.bounds[i], h.bounds[j] = h.bounds[j], h.bounds[i]
+	h.buckets[i], h.buckets[j] = h.buckets[j], h.buckets[i]
+}
+
+func (h *classicHistogram) isValid() bool {
+	if len(h.bounds) == 0 || math.IsNaN(h.sum) || math.IsInf(h.sum, 0) || math.IsNaN(h.count) {
+		return false
+	}
+	for _, b := range h.buckets {
+		if math.IsNaN(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasBounds returns true when the histogram has the buckets of the previous one.
+func (h *classicHistogram) hasBounds(pre *classicHistogram) bool {
+	if len(h.bounds) != len(pre.bounds) {
+		return false
+	}
+	for i := range h.bounds {
+		if h.bounds[i] != pre.bounds[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isReset returns true when the histogram has been reset since the previous one.
+func (h *classicHistogram) isReset(pre *classicHistogram) bool {
+	if h.count < pre.count {
+		return true
+	}
+	for i := range h.buckets {
+		if h.buckets[i] < pre.buckets[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketValue returns the value the samples of a bucket are recorded at, its midpoint. The samples
+// above the highest bound are recorded at the bound, and the ones of a first bucket with a negative
+// bound, whose lower bound is unknown, at its upper bound.
+func bucketValue(lower, upper float64) float64 {
+	if math.IsInf(upper, 1) {
+		return lower
+	}
+	if lower > upper {
+		return upper
+	}
+	return (lower + upper) / 2
+}
+
+// newHistogramDistribution returns the distribution of the values and counts, with the exact sum
+// and count of the histogram. A regular distribution is used since the values are not rebucketed.
+func newHistogramDistribution(values, counts []float64, sum, count float64) distribution.Distribution {
+	dp := pmetric.NewHistogramDataPoint()
+	dp.SetSum(sum)
+	dp.SetCount(uint64(count))
+	for i, v := range values {
+		if i == 0 || v < dp.Min() {
+			dp.SetMin(v)
+		}
+		if i == 0 || v > dp.Max() {
+			dp.SetMax(v)
+		}
+		dp.ExplicitBounds().Append(v)
+		dp.BucketCounts().Append(uint64(math.Round(counts[i])))
+	}
+	d := regular.NewRegularDistribution()
+	d.ConvertFromOtel(dp, "")
+	return d
+}
+
+func NewHistogramCalculator() *HistogramCalculator {
+	return &HistogramCalculator{preHistograms: mapWithExpiry.NewMapWithExpiry(CacheTTL), lastCleanUpTimeInMs: 0}
+}
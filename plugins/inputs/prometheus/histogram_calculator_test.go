@@ -0,0 +1,136 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildClassicHistogram(timeInMS int64, sum float64, cumulativeCounts map[string]float64) (result PrometheusMetricBatch) {
+	newMetric := func(name string, value float64, tags map[string]string) *PrometheusMetric {
+		tags["job"] = "job1"
+		return &PrometheusMetric{
+			metricName:  name,
+			metricValue: value,
+			metricType:  "histogram",
+			timeInMS:    timeInMS,
+			tags:        tags,
+		}
+	}
+	for le, count := range cumulativeCounts {
+		result = append(result, newMetric("latency_bucket", count, map[string]string{"le": le}))
+	}
+	return append(result,
+		newMetric("latency_sum", sum, map[string]string{}),
+		newMetric("latency_count", cumulativeCounts["+Inf"], map[string]string{}),
+	)
+}
+
+func TestHistogramCalculator_Classic(t *testing.T) {
+	hc := NewHistogramCalculator()
+
+	// The first scrape only records the cumulative values.
+	result := hc.calculate(buildClassicHistogram(1000, 10, map[string]float64{"0.5": 2, "1": 3, "+Inf": 4}))
+	assert.Empty(t, result)
+
+	result = hc.calculate(buildClassicHistogram(2000, 17, map[string]float64{"0.5": 3, "1": 6, "2.5": 6, "+Inf": 8}))
+	assert.Empty(t, result, "the histogram should be skipped when its buckets changed")
+
+	result = hc.calculate(buildClassicHistogram(3000, 20, map[string]float64{"0.5": 3, "1": 7, "2.5": 7, "+Inf": 10}))
+	require.Len(t, result, 1)
+	assert.Equal(t, "latency", result[0].metricName)
+	assert.Equal(t, map[string]string{"job": "job1"}, result[0].tags)
+	d := result[0].distribution
+	require.NotNil(t, d)
+	assert.Equal(t, float64(2), d.SampleCount())
+	assert.Equal(t, float64(3), d.Sum())
+	assert.Equal(t, 0.75, d.Minimum())
+	assert.Equal(t, 2.5, d.Maximum())
+	values, counts := d.ValuesAndCounts()
+	assert.ElementsMatch(t, []float64{0.75, 2.5}, values)
+	assert.ElementsMatch(t, []float64{1, 1}, counts)
+
+	// The counter has been reset, the current value is the delta.
+	result = hc.calculate(buildClassicHistogram(4000, 1, map[string]float64{"0.5": 1, "1": 1, "2.5": 1, "+Inf": 1}))
+	require.Len(t, result, 1)
+	assert.Equal(t, float64(1), result[0].distribution.SampleCount())
+	assert.Equal(t, 0.25, result[0].distribution.Maximum())
+
+	// Nothing was observed since the previous scrape.
+	result = hc.calculate(buildClassicHistogram(5000, 1, map[string]float64{"0.5": 1, "1": 1, "2.5": 1, "+Inf": 1}))
+	assert.Empty(t, result)
+
+	result = hc.calculate(buildClassicHistogram(6000, math.NaN(), map[string]float64{"0.5": 1, "1": 1, "2.5": 1, "+Inf": 1}))
+	assert.Empty(t, result)
+	assert.Equal(t, 0, hc.preHistograms.Size(), "the previous value should be reset on invalid values")
+}
+
+func TestHistogramCalculator_Native(t *testing.T) {
+	newMetric := func(timeInMS int64, h *histogram.Histogram) *PrometheusMetric {
+		return &PrometheusMetric{
+			metricName:      "latency",
+			metricType:      "histogram",
+			timeInMS:        timeInMS,
+			tags:            map[string]string{"job": "job1"},
+			nativeHistogram: h.ToFloat(nil),
+		}
+	}
+	hc := NewHistogramCalculator()
+
+	// Schema 0 buckets of index 0 and 1 cover (0.5, 1] and (1, 2].
+	result := hc.calculate(PrometheusMetricBatch{newMetric(1000, &histogram.Histogram{
+		Count:           3,
+		ZeroCount:       1,
+		Sum:             2,
+		PositiveSpans:   []histogram.Span{{Offset: 0, Length: 2}},
+		PositiveBuckets: []int64{1, 0},
+	})})
+	assert.Empty(t, result)
+
+	// The negative bucket of index 1 covers [-2, -1).
+	result = hc.calculate(PrometheusMetricBatch{newMetric(2000, &histogram.Histogram{
+		Count:           8,
+		ZeroCount:       1,
+		Sum:             3.5,
+		PositiveSpans:   []histogram.Span{{Offset: 0, Length: 2}},
+		PositiveBuckets: []int64{2, 1},
+		NegativeSpans:   []histogram.Span{{Offset: 1, Length: 1}},
+		NegativeBuckets: []int64{2},
+	})})
+	require.Len(t, result, 1)
+	assert.Nil(t, result[0].nativeHistogram)
+	d := result[0].distribution
+	require.NotNil(t, d)
+	assert.Equal(t, float64(5), d.SampleCount())
+	assert.Equal(t, 1.5, d.Sum())
+	assert.Equal(t, -1.5, d.Minimum())
+	assert.Equal(t, 1.5, d.Maximum())
+	values, counts := d.ValuesAndCounts()
+	assert.ElementsMatch(t, []float64{-1.5, 0.75, 1.5}, values)
+	assert.ElementsMatch(t, []float64{2, 1, 2}, counts)
+}
+
+func TestCalculator_Histograms(t *testing.T) {
+	c := NewCalculator()
+	c.Calculate(buildClassicHistogram(1000, 10, map[string]float64{"1": 3, "+Inf": 4}))
+	result := c.Calculate(append(buildClassicHistogram(2000, 12, map[string]float64{"1": 4, "+Inf": 5}), &PrometheusMetric{
+		metricName:  "up",
+		metricValue: 1,
+		metricType:  "gauge",
+		timeInMS:    2000,
+		tags:        map[string]string{"job": "job1"},
+	}))
+	require.Len(t, result, 2)
+	values, distributions := splitDistributions(result)
+	require.Len(t, values, 1)
+	require.Len(t, distributions, 1)
+	mm := mergeMetrics(distributions)
+	require.Len(t, mm, 1)
+	assert.Equal(t, distributions[0].distribution, mm[0].fields["latency"])
+}
@@ -22,7 +22,7 @@ func buildTestData(pass int, drop int) (result PrometheusMetricBatch) {
 	for i := 0; i < drop; i++ {
 		pm := &PrometheusMetric{
 			metricName: fmt.Sprintf("dropped_id_%d", i),
-			metricType: "histogram",
+			metricType: "unknown",
 		}
 		result = append(result, pm)
 	}
@@ -22,6 +22,7 @@ type metricMaterial struct {
 
 type metricsHandler struct {
 	mbCh        <-chan PrometheusMetricBatch
+	pushedCh    <-chan PrometheusMetricBatch
 	acc         telegraf.Accumulator
 	calculator  *Calculator
 	filter      *MetricsFilter
@@ -35,6 +36,9 @@ func (mh *metricsHandler) start(shutDownChan chan interface{}, wg *sync.WaitGrou
 		case metricBatch := <-mh.mbCh:
 			log.Printf("D! receive metric batch with %v prometheus metrics\n", len(metricBatch))
 			mh.handle(metricBatch)
+		case metricBatch := <-mh.pushedCh:
+			log.Printf("D! receive pushed metric batch with %v prometheus metrics\n", len(metricBatch))
+			mh.handlePushed(metricBatch)
 		case <-shutDownChan:
 			wg.Done()
 			return
@@ -45,22 +49,42 @@ func (mh *metricsHandler) start(shutDownChan chan interface{}, wg *sync.WaitGrou
 func (mh *metricsHandler) handle(pmb PrometheusMetricBatch) {
 	// Add metric type info
 	pmb = mh.mtHandler.Handle(pmb)
+	mh.handleTyped(pmb)
+}
 
-	// Filter out Histogram and untyped Metrics and adding logging
+// handlePushed handles the batches of the remote write receiver, which are already typed. Their
+// samples are handled per timestamp like the scrapes, so the samples of a series at different
+// timestamps are not merged, and histograms are assembled from the series of the same timestamp.
+func (mh *metricsHandler) handlePushed(pmb PrometheusMetricBatch) {
+	for _, batch := range groupByTimestamp(pmb) {
+		mh.handleTyped(batch)
+	}
+}
+
+// handleTyped converts the metrics whose type is known to telegraf metrics.
+func (mh *metricsHandler) handleTyped(pmb PrometheusMetricBatch) {
+	// Filter out untyped Metrics and adding logging
 	pmb = mh.filter.Filter(pmb)
 
-	// do calculation: calculate delta for counter
+	// do calculation: calculate delta for counter and histogram
 	pmb = mh.calculator.Calculate(pmb)
 
-	// do merge: merge metrics which are sharing same tags
-	metricMaterials := mergeMetrics(pmb)
+	// do merge: merge metrics which are sharing same tags, the distributions of the histograms
+	// are merged separately since they are added as histograms
+	values, distributions := splitDistributions(pmb)
+	metricMaterials := mergeMetrics(values)
+	distributionMaterials := mergeMetrics(distributions)
 
 	// set emf
 	mh.setEmfMetadata(metricMaterials)
+	mh.setEmfMetadata(distributionMaterials)
 
 	for _, metricMaterial := range metricMaterials {
 		mh.acc.AddFields("prometheus", metricMaterial.fields, metricMaterial.tags, time.UnixMilli(metricMaterial.timeInMS))
 	}
+	for _, metricMaterial := range distributionMaterials {
+		mh.acc.AddHistogram("prometheus", metricMaterial.fields, metricMaterial.tags, time.UnixMilli(metricMaterial.timeInMS))
+	}
 }
 
 // set timestamp, version, logstream
@@ -11,6 +11,7 @@ import (
 	kitlog "github.com/go-kit/log"
 	"github.com/prometheus/common/promlog"
 	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/stretchr/testify/assert"
@@ -57,6 +58,30 @@ func Test_metricAppender_Add(t *testing.T) {
 	assert.Equal(t, expected, *mac.batch[0])
 }
 
+func Test_metricAppender_AppendHistogram(t *testing.T) {
+	mr := metricsReceiver{}
+	ma := mr.Appender(nil)
+	ls := []labels.Label{
+		{Name: "__name__", Value: "request_duration_seconds"},
+		{Name: "tag_a", Value: "a"},
+	}
+	h := &histogram.Histogram{
+		Count:           3,
+		Sum:             1.5,
+		PositiveSpans:   []histogram.Span{{Offset: 0, Length: 2}},
+		PositiveBuckets: []int64{1, 1},
+	}
+
+	ref, err := ma.AppendHistogram(0, ls, 10, h, nil)
+	assert.Equal(t, storage.SeriesRef(0), ref)
+	assert.NoError(t, err)
+	mac, _ := ma.(*metricAppender)
+	assert.Equal(t, 1, len(mac.batch))
+	assert.Equal(t, "request_duration_seconds", mac.batch[0].metricName)
+	assert.Equal(t, map[string]string{"tag_a": "a"}, mac.batch[0].tags)
+	assert.Equal(t, h.ToFloat(nil), mac.batch[0].nativeHistogram)
+}
+
 func Test_metricAppender_isValueStale(t *testing.T) {
 	nonStaleValue := PrometheusMetric{
 		metricValue: 10.0,
@@ -5,6 +5,7 @@ package prometheus
 
 import (
 	_ "embed"
+	"net"
 	"sync"
 
 	"github.com/amazon-contributing/opentelemetry-collector-contrib/extension/awsmiddleware"
@@ -24,7 +25,9 @@ type Prometheus struct {
 	PrometheusConfigPath string                                      `toml:"prometheus_config_path"`
 	ClusterName          string                                      `toml:"cluster_name"`
 	ECSSDConfig          *ecsservicediscovery.ServiceDiscoveryConfig `toml:"ecs_service_discovery"`
+	RemoteWriteReceiver  *RemoteWriteReceiverConfig                  `toml:"remote_write_receiver"`
 	mbCh                 chan PrometheusMetricBatch
+	pushedCh             chan PrometheusMetricBatch
 	shutDownChan         chan interface{}
 	wg                   sync.WaitGroup
 	middleware           awsmiddleware.Middleware
@@ -45,9 +48,21 @@ func (p *Prometheus) Gather(_ telegraf.Accumulator) error {
 func (p *Prometheus) Start(accIn telegraf.Accumulator) error {
 	mth := NewMetricsTypeHandler()
 
+	// Open the remote write endpoint first, so its errors fail the start before anything is launched
+	var rwReceiver *remoteWriteReceiver
+	var rwListener net.Listener
+	if p.RemoteWriteReceiver != nil {
+		rwReceiver = newRemoteWriteReceiver(p.RemoteWriteReceiver, p.pushedCh)
+		var err error
+		if rwListener, err = rwReceiver.listen(); err != nil {
+			return err
+		}
+	}
+
 	receiver := &metricsReceiver{pmbCh: p.mbCh}
 	handler := &metricsHandler{
 		mbCh:        p.mbCh,
+		pushedCh:    p.pushedCh,
 		acc:         accIn,
 		calculator:  NewCalculator(),
 		filter:      NewMetricsFilter(),
@@ -83,6 +98,12 @@ func (p *Prometheus) Start(accIn telegraf.Accumulator) error {
 	p.wg.Add(1)
 	go handler.start(p.shutDownChan, &p.wg)
 
+	// Accept the metrics pushed with the Prometheus remote write protocol
+	if rwReceiver != nil {
+		p.wg.Add(1)
+		go rwReceiver.serve(rwListener, p.shutDownChan, &p.wg)
+	}
+
 	return nil
 }
 
@@ -95,6 +116,7 @@ func init() {
 	inputs.Add("prometheus", func() telegraf.Input {
 		return &Prometheus{
 			mbCh:         make(chan PrometheusMetricBatch, 10000),
+			pushedCh:     make(chan PrometheusMetricBatch, 10000),
 			shutDownChan: make(chan interface{}),
 			middleware: agenthealth.NewAgentHealth(
 				zap.NewNop(),
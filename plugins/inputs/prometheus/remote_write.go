@@ -0,0 +1,313 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+
+	tlsint "github.com/aws/amazon-cloudwatch-agent/internal/tls"
+)
+
+const (
+	defaultRemoteWritePath   = "/api/v1/write"
+	defaultMaxRequestSize    = 32 * 1024 * 1024 // 32MB
+	remoteWriteShutdownLimit = 5 * time.Second
+	quantileLabel            = "quantile"
+)
+
+// RemoteWriteReceiverConfig is the config of the endpoint accepting Prometheus remote write requests.
+type RemoteWriteReceiverConfig struct {
+	ServiceAddress string `toml:"service_address"`
+	Path           string `toml:"path"`
+	// MaxRequestSize limits both the compressed and the decompressed size of the requests in bytes.
+	MaxRequestSize int64 `toml:"max_request_size"`
+	tlsint.ServerConfig
+}
+
+// remoteWriteReceiver accepts the snappy compressed protobuf requests of the Prometheus remote write
+// protocol and feeds their samples to the metricsHandler, like the scraped metrics.
+type remoteWriteReceiver struct {
+	config *RemoteWriteReceiverConfig
+	pmbCh  chan<- PrometheusMetricBatch
+	server *http.Server
+
+	// metricTypes are the types of the metric families, the senders only send the metadata periodically.
+	mu          sync.RWMutex
+	metricTypes map[string]string
+}
+
+var errRequestTooLarge = errors.New("remote write request too large")
+
+func newRemoteWriteReceiver(config *RemoteWriteReceiverConfig, pmbCh chan<- PrometheusMetricBatch) *remoteWriteReceiver {
+	return &remoteWriteReceiver{config: config, pmbCh: pmbCh, metricTypes: map[string]string{}}
+}
+
+func (rw *remoteWriteReceiver) maxRequestSize() int64 {
+	if rw.config.MaxRequestSize > 0 {
+		return rw.config.MaxRequestSize
+	}
+	return defaultMaxRequestSize
+}
+
+// listen opens the endpoint, so the configuration errors are returned before the plugin is started.
+func (rw *remoteWriteReceiver) listen() (net.Listener, error) {
+	tlsConfig, err := rw.config.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS config of the remote write receiver: %w", err)
+	}
+	path := rw.config.Path
+	if path == "" {
+		path = defaultRemoteWritePath
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, rw)
+	rw.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	listener, err := net.Listen("tcp", rw.config.ServiceAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %s for remote write requests: %w", rw.config.ServiceAddress, err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	log.Printf("I! Listening for Prometheus remote write requests on %s%s", listener.Addr(), path)
+	return listener, nil
+}
+
+func (rw *remoteWriteReceiver) serve(listener net.Listener, shutDownChan chan interface{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	go func() {
+		if err := rw.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! Prometheus remote write receiver stopped: %v", err)
+		}
+	}()
+	<-shutDownChan
+	ctx, cancel := context.WithTimeout(context.Background(), remoteWriteShutdownLimit)
+	defer cancel()
+	if err := rw.server.Shutdown(ctx); err != nil {
+		log.Printf("W! Failed to shut down the Prometheus remote write receiver: %v", err)
+	}
+}
+
+func (rw *remoteWriteReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	maxSize := rw.maxRequestSize()
+	req, err := decodeWriteRequest(http.MaxBytesReader(w, r.Body, maxSize), maxSize)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || errors.Is(err, errRequestTooLarge) {
+			log.Printf("W! Drop Prometheus remote write request larger than %d bytes", maxSize)
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		log.Printf("D! Drop invalid Prometheus remote write request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rw.updateMetricTypes(req.Metadata)
+	batch := rw.toBatch(req.Timeseries)
+	if len(batch) > 0 {
+		select {
+		case rw.pmbCh <- batch:
+		default:
+			// the sender retries the request on server errors
+			log.Println("W! metric batch drop due to channel full")
+			http.Error(w, "metric batch channel full", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rw *remoteWriteReceiver) updateMetricTypes(metadata []prompb.MetricMetadata) {
+	if len(metadata) == 0 {
+		return
+	}
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	for _, m := range metadata {
+		if m.Type != prompb.MetricMetadata_UNKNOWN {
+			rw.metricTypes[m.MetricFamilyName] = strings.ToLower(m.Type.String())
+		}
+	}
+}
+
+// toBatch converts the time series of a request to a batch, with the type of every metric since the
+// pushed metrics have no scrape target to look their metadata up from.
+func (rw *remoteWriteReceiver) toBatch(timeseries []prompb.TimeSeries) PrometheusMetricBatch {
+	var batch PrometheusMetricBatch
+	// the families with buckets or quantiles, to type their _sum and _count without metadata
+	inferred := map[string]string{}
+	b := labels.NewScratchBuilder(0)
+	for _, ts := range timeseries {
+		b.Reset()
+		for _, l := range ts.Labels {
+			b.Add(l.Name, l.Value)
+		}
+		b.Sort()
+		ls := b.Labels()
+		for _, s := range ts.Samples {
+			pm, err := newPrometheusMetric(ls, s.Timestamp)
+			if err != nil {
+				break
+			}
+			pm.metricValue = s.Value
+			batch = append(batch, pm)
+		}
+		for _, h := range ts.Histograms {
+			pm, err := newPrometheusMetric(ls, h.Timestamp)
+			if err != nil {
+				break
+			}
+			pm.nativeHistogram = toFloatHistogram(h)
+			batch = append(batch, pm)
+		}
+		name := ls.Get("__name__")
+		if strings.HasSuffix(name, histogramBucketSuffix) && ls.Has(bucketBoundLabel) {
+			inferred[strings.TrimSuffix(name, histogramBucketSuffix)] = string(v1.MetricTypeHistogram)
+		} else if ls.Has(quantileLabel) {
+			inferred[name] = string(v1.MetricTypeSummary)
+		}
+	}
+
+	rw.mu.RLock()
+	defer rw.mu.RUnlock()
+	for _, pm := range batch {
+		pm.metricType = rw.metricTypeOf(pm, inferred)
+		pm.tags[prometheusMetricTypeKey] = pm.metricType
+	}
+	return batch
+}
+
+// groupByTimestamp splits a batch into the batches of its timestamps, in ascending order.
+func groupByTimestamp(pmb PrometheusMetricBatch) []PrometheusMetricBatch {
+	batches := map[int64]PrometheusMetricBatch{}
+	var timestamps []int64
+	for _, pm := range pmb {
+		if _, ok := batches[pm.timeInMS]; !ok {
+			timestamps = append(timestamps, pm.timeInMS)
+		}
+		batches[pm.timeInMS] = append(batches[pm.timeInMS], pm)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	result := make([]PrometheusMetricBatch, len(timestamps))
+	for i, ts := range timestamps {
+		result[i] = batches[ts]
+	}
+	return result
+}
+
+// metricTypeOf returns the type of the metric from the metadata sent by the sender, or infers it
+// from the name and labels of the metric when the metadata has not been received.
+func (rw *remoteWriteReceiver) metricTypeOf(pm *PrometheusMetric, inferred map[string]string) string {
+	if t, ok := rw.metricTypes[pm.metricName]; ok {
+		return t
+	}
+	standardMetricName := normalizeMetricName(pm.metricName, histogramSummarySuffixes)
+	if t, ok := rw.metricTypes[standardMetricName]; ok {
+		return t
+	}
+	if t, ok := rw.metricTypes[normalizeMetricName(pm.metricName, counterSuffixes)]; ok {
+		return t
+	}
+	switch {
+	case pm.nativeHistogram != nil:
+		return string(v1.MetricTypeHistogram)
+	case inferred[standardMetricName] != "":
+		return inferred[standardMetricName]
+	case strings.HasSuffix(pm.metricName, counterSuffix):
+		return string(v1.MetricTypeCounter)
+	default:
+		return string(v1.MetricTypeGauge)
+	}
+}
+
+// decodeWriteRequest decodes the snappy compressed protobuf body of a remote write request. The
+// storage/remote package of Prometheus is not used since it registers feature gates the collector
+// translators already register. The decompressed length is checked before it is allocated.
+func decodeWriteRequest(r io.Reader, maxSize int64) (*prompb.WriteRequest, error) {
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	decodedLen, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, err
+	}
+	if int64(decodedLen) > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes decompressed", errRequestTooLarge, decodedLen)
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, err
+	}
+	var req prompb.WriteRequest
+	if err = req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// toFloatHistogram converts the integer and float native histograms of the requests to float histograms.
+func toFloatHistogram(hp prompb.Histogram) *histogram.FloatHistogram {
+	fh := &histogram.FloatHistogram{
+		CounterResetHint: histogram.CounterResetHint(hp.ResetHint),
+		Schema:           hp.Schema,
+		ZeroThreshold:    hp.ZeroThreshold,
+		Sum:              hp.Sum,
+		PositiveSpans:    toSpans(hp.GetPositiveSpans()),
+		NegativeSpans:    toSpans(hp.GetNegativeSpans()),
+	}
+	if hp.IsFloatHistogram() {
+		fh.ZeroCount = hp.GetZeroCountFloat()
+		fh.Count = hp.GetCountFloat()
+		fh.PositiveBuckets = hp.GetPositiveCounts()
+		fh.NegativeBuckets = hp.GetNegativeCounts()
+	} else {
+		fh.ZeroCount = float64(hp.GetZeroCountInt())
+		fh.Count = float64(hp.GetCountInt())
+		fh.PositiveBuckets = deltasToCounts(hp.GetPositiveDeltas())
+		fh.NegativeBuckets = deltasToCounts(hp.GetNegativeDeltas())
+	}
+	return fh
+}
+
+func toSpans(spans []prompb.BucketSpan) []histogram.Span {
+	result := make([]histogram.Span, len(spans))
+	for i, s := range spans {
+		result[i] = histogram.Span{Offset: s.Offset, Length: s.Length}
+	}
+	return result
+}
+
+// deltasToCounts converts the bucket counts of integer histograms, encoded as deltas to the previous bucket.
+func deltasToCounts(deltas []int64) []float64 {
+	counts := make([]float64, len(deltas))
+	var cur float64
+	for i, d := range deltas {
+		cur += float64(d)
+		counts[i] = cur
+	}
+	return counts
+}
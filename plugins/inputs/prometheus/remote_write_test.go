@@ -0,0 +1,239 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+)
+
+func encodeWriteRequest(t *testing.T, req *prompb.WriteRequest) []byte {
+	data, err := req.Marshal()
+	require.NoError(t, err)
+	return snappy.Encode(nil, data)
+}
+
+func newTimeSeries(name string, value float64, labelPairs ...string) prompb.TimeSeries {
+	ts := prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: name}, {Name: "job", Value: "k6"}},
+		Samples: []prompb.Sample{{Value: value, Timestamp: 1000}},
+	}
+	for i := 0; i+1 < len(labelPairs); i += 2 {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: labelPairs[i], Value: labelPairs[i+1]})
+	}
+	return ts
+}
+
+func TestRemoteWriteReceiver_ServeHTTP(t *testing.T) {
+	pmbCh := make(chan PrometheusMetricBatch, 1)
+	rw := newRemoteWriteReceiver(&RemoteWriteReceiverConfig{}, pmbCh)
+
+	native := prompb.TimeSeries{
+		Labels: []prompb.Label{{Name: "__name__", Value: "native_seconds"}, {Name: "job", Value: "k6"}},
+		Histograms: []prompb.Histogram{{
+			Count:          &prompb.Histogram_CountInt{CountInt: 3},
+			Sum:            3,
+			PositiveSpans:  []prompb.BucketSpan{{Offset: 0, Length: 2}},
+			PositiveDeltas: []int64{2, -1},
+			Timestamp:      2000,
+		}},
+	}
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			newTimeSeries("requests_total", 10),
+			newTimeSeries("memory_bytes", 20),
+			newTimeSeries("latency_seconds_bucket", 1, "le", "0.5"),
+			newTimeSeries("latency_seconds_sum", 0.2),
+			newTimeSeries("latency_seconds_count", 1),
+			newTimeSeries("rpc_seconds", 0.1, "quantile", "0.9"),
+			newTimeSeries("rpc_seconds_count", 5),
+			newTimeSeries("queue_length", 3),
+			native,
+		},
+		Metadata: []prompb.MetricMetadata{{MetricFamilyName: "queue_length", Type: prompb.MetricMetadata_COUNTER}},
+	}
+	recorder := httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(encodeWriteRequest(t, req))))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+	batch := <-pmbCh
+	got := map[string]string{}
+	for _, pm := range batch {
+		got[pm.metricName] = pm.metricType
+		assert.Equal(t, pm.metricType, pm.tags[prometheusMetricTypeKey])
+		assert.Equal(t, "k6", pm.tags["job"], pm.metricName)
+	}
+	delete(got, "native_seconds")
+	assert.Equal(t, map[string]string{
+		"requests_total":         "counter",
+		"memory_bytes":           "gauge",
+		"latency_seconds_bucket": "histogram",
+		"latency_seconds_sum":    "histogram",
+		"latency_seconds_count":  "histogram",
+		"rpc_seconds":            "summary",
+		"rpc_seconds_count":      "summary",
+		"queue_length":           "counter",
+	}, got)
+	last := batch[len(batch)-1]
+	assert.Equal(t, "histogram", last.metricType)
+	assert.Equal(t, int64(2000), last.timeInMS)
+	require.NotNil(t, last.nativeHistogram)
+	assert.Equal(t, 3.0, last.nativeHistogram.Count)
+	assert.Equal(t, []float64{2, 1}, last.nativeHistogram.PositiveBuckets)
+
+	// The metadata is kept for the following requests.
+	req = &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{newTimeSeries("queue_length", 4)}}
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(encodeWriteRequest(t, req))))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	batch = <-pmbCh
+	require.Len(t, batch, 1)
+	assert.Equal(t, "counter", batch[0].metricType)
+
+	// The sender is asked to retry when the batch cannot be queued.
+	pmbCh <- PrometheusMetricBatch{}
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(encodeWriteRequest(t, req))))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	<-pmbCh
+
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader([]byte("not snappy"))))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, defaultRemoteWritePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestRemoteWriteReceiver_MaxRequestSize(t *testing.T) {
+	pmbCh := make(chan PrometheusMetricBatch, 1)
+	rw := newRemoteWriteReceiver(&RemoteWriteReceiverConfig{MaxRequestSize: 1024}, pmbCh)
+
+	req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{newTimeSeries("queue_length", 4)}}
+	recorder := httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(encodeWriteRequest(t, req))))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	<-pmbCh
+
+	// The body is not read past the limit.
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(make([]byte, 2048))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+
+	// The decompressed length declared by the payload is checked before it is allocated.
+	huge := binary.AppendUvarint(nil, 1<<30)
+	huge = append(huge, 0x00)
+	recorder = httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(huge)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	assert.Empty(t, pmbCh)
+}
+
+func TestRemoteWriteReceiver_MultipleSamples(t *testing.T) {
+	pmbCh := make(chan PrometheusMetricBatch, 1)
+	rw := newRemoteWriteReceiver(&RemoteWriteReceiverConfig{}, pmbCh)
+
+	withSamples := func(ts prompb.TimeSeries, values ...float64) prompb.TimeSeries {
+		ts.Samples = nil
+		for i, value := range values {
+			ts.Samples = append(ts.Samples, prompb.Sample{Value: value, Timestamp: int64(i+1) * 1000})
+		}
+		return ts
+	}
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			withSamples(newTimeSeries("memory_bytes", 0), 1, 2, 3),
+			withSamples(newTimeSeries("latency_seconds_bucket", 0, "le", "0.5"), 1, 3, 4),
+			withSamples(newTimeSeries("latency_seconds_bucket", 0, "le", "+Inf"), 2, 5, 9),
+			withSamples(newTimeSeries("latency_seconds_sum", 0), 1, 2, 5),
+			withSamples(newTimeSeries("latency_seconds_count", 0), 2, 5, 9),
+		},
+	}
+	recorder := httptest.NewRecorder()
+	rw.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, defaultRemoteWritePath, bytes.NewReader(encodeWriteRequest(t, req))))
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	batch := <-pmbCh
+	batches := groupByTimestamp(batch)
+	require.Len(t, batches, 3)
+	for i, b := range batches {
+		require.Len(t, b, 5)
+		for _, pm := range b {
+			assert.Equal(t, int64(i+1)*1000, pm.timeInMS)
+		}
+	}
+
+	acc := &testutil.Accumulator{}
+	mh := &metricsHandler{acc: acc, calculator: NewCalculator(), filter: NewMetricsFilter()}
+	mh.handlePushed(batch)
+
+	gauges := map[int64]interface{}{}
+	histograms := map[int64]distribution.Distribution{}
+	for _, m := range acc.Metrics {
+		if value, ok := m.Fields["memory_bytes"]; ok {
+			gauges[m.Time.UnixMilli()] = value
+		}
+		if value, ok := m.Fields["latency_seconds"]; ok {
+			histograms[m.Time.UnixMilli()] = value.(distribution.Distribution)
+		}
+	}
+	assert.Equal(t, map[int64]interface{}{1000: 1.0, 2000: 2.0, 3000: 3.0}, gauges)
+	// The first histogram is the baseline of the deltas.
+	require.Len(t, histograms, 2)
+	assert.Equal(t, 3.0, histograms[2000].SampleCount())
+	assert.Equal(t, 1.0, histograms[2000].Sum())
+	assert.Equal(t, 4.0, histograms[3000].SampleCount())
+	assert.Equal(t, 3.0, histograms[3000].Sum())
+}
+
+func TestRemoteWriteReceiver_Serve(t *testing.T) {
+	pmbCh := make(chan PrometheusMetricBatch, 1)
+	rw := newRemoteWriteReceiver(&RemoteWriteReceiverConfig{ServiceAddress: "127.0.0.1:0", Path: "/receive"}, pmbCh)
+	listener, err := rw.listen()
+	require.NoError(t, err)
+	shutDownChan := make(chan interface{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go rw.serve(listener, shutDownChan, &wg)
+
+	req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{newTimeSeries("memory_bytes", 20)}}
+	resp, err := http.Post(fmt.Sprintf("http://%s/receive", listener.Addr()), "application/x-protobuf", bytes.NewReader(encodeWriteRequest(t, req)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	select {
+	case batch := <-pmbCh:
+		assert.Len(t, batch, 1)
+	case <-time.After(time.Second):
+		t.Fatal("the pushed batch was not received")
+	}
+
+	close(shutDownChan)
+	wg.Wait()
+	_, err = http.Post(fmt.Sprintf("http://%s/receive", listener.Addr()), "application/x-protobuf", nil)
+	assert.Error(t, err, "the endpoint should be closed on shutdown")
+}
+
+func TestRemoteWriteReceiver_InvalidTLS(t *testing.T) {
+	config := &RemoteWriteReceiverConfig{ServiceAddress: "127.0.0.1:0"}
+	config.TLSCert = "missing.crt"
+	config.TLSKey = "missing.key"
+	_, err := newRemoteWriteReceiver(config, nil).listen()
+	assert.ErrorContains(t, err, "invalid TLS config")
+}
@@ -46,6 +46,8 @@ import (
 	promRuntime "github.com/prometheus/prometheus/util/runtime"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/prometheustargets"
 )
 
 var (
@@ -129,6 +131,8 @@ func Start(configFilePath string, receiver storage.Appendable, shutDownChan chan
 	)
 
 	mth.SetScrapeManager(scrapeManager)
+	prometheustargets.SetManager(scrapeManager)
+	defer prometheustargets.SetManager(nil)
 
 	var reloaders = []func(cfg *config.Config) error{
 		// The Scrape and notifier managers need to reload before the Discovery manager as
@@ -312,8 +316,8 @@ func Start(configFilePath string, receiver storage.Appendable, shutDownChan chan
 }
 
 const (
-	savedScrapeJobLabel      = "cwagent_saved_scrape_job"
-	savedScrapeInstanceLabel = "cwagent_saved_scrape_instance"
+	savedScrapeJobLabel      = prometheustargets.JobLabel
+	savedScrapeInstanceLabel = prometheustargets.InstanceLabel
 	scrapeInstanceLabel      = "__address__"
 	savedScrapeNameLabel     = "cwagent_saved_scrape_name" // just arbitrary name that end user won't override in relabel config
 )
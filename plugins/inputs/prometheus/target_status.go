@@ -0,0 +1,24 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/internal/prometheustargets"
+)
+
+// recordScrape saves the number of samples of a scrape for the status of its target, without the
+// samples Prometheus adds itself.
+func recordScrape(pmb PrometheusMetricBatch) {
+	job, instance, err := getScrapeTargetInfo(pmb)
+	if err != nil {
+		return
+	}
+	count := 0
+	for _, pm := range pmb {
+		if !isInternalMetric(pm.metricNameBeforeRelabel) {
+			count++
+		}
+	}
+	prometheustargets.RecordScrape(job, instance, count)
+}
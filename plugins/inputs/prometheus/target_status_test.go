@@ -0,0 +1,49 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/prometheustargets"
+)
+
+type mockTargetsManager struct {
+	active map[string][]*scrape.Target
+}
+
+func (m *mockTargetsManager) TargetsActive() map[string][]*scrape.Target {
+	return m.active
+}
+
+func (m *mockTargetsManager) TargetsDroppedCounts() map[string]int {
+	return nil
+}
+
+func TestRecordScrape(t *testing.T) {
+	target := scrape.NewTarget(
+		labels.FromStrings("__address__", "10.0.0.1:9113", "__scheme__", "http", "__metrics_path__", "/metrics", "job", "nginx", savedScrapeJobLabel, "nginx", savedScrapeInstanceLabel, "10.0.0.1:9113"),
+		labels.FromStrings("__address__", "10.0.0.1:9113", "job", "nginx"),
+		nil,
+	)
+	prometheustargets.SetManager(&mockTargetsManager{active: map[string][]*scrape.Target{"nginx": {target}}})
+	defer prometheustargets.SetManager(nil)
+
+	recordScrape(PrometheusMetricBatch{
+		{metricNameBeforeRelabel: "nginx_requests_total", jobBeforeRelabel: "nginx", instanceBeforeRelabel: "10.0.0.1:9113"},
+		{metricNameBeforeRelabel: "nginx_connections", jobBeforeRelabel: "nginx", instanceBeforeRelabel: "10.0.0.1:9113"},
+		{metricNameBeforeRelabel: "up", jobBeforeRelabel: "nginx", instanceBeforeRelabel: "10.0.0.1:9113"},
+	})
+	// the batches of the staleness markers of removed targets have no target info
+	recordScrape(PrometheusMetricBatch{})
+
+	status := prometheustargets.Status()
+	require.Len(t, status.ActiveTargets, 1)
+	assert.Equal(t, 2, status.ActiveTargets[0].LastScrapeSampleCount)
+}
@@ -56,10 +56,26 @@ func mergePrometheusMetrics(mm *metricMaterial, pm *PrometheusMetric) *metricMat
 		mm = &metricMaterial{tags: pm.tags, fields: map[string]interface{}{}, timeInMS: pm.timeInMS}
 	}
 
-	mm.fields[pm.metricName] = pm.metricValue
+	if pm.distribution != nil {
+		mm.fields[pm.metricName] = pm.distribution
+	} else {
+		mm.fields[pm.metricName] = pm.metricValue
+	}
 	return mm
 }
 
+// splitDistributions splits the metrics with a value from the ones with a distribution.
+func splitDistributions(pmb PrometheusMetricBatch) (values PrometheusMetricBatch, distributions PrometheusMetricBatch) {
+	for _, pm := range pmb {
+		if pm.distribution != nil {
+			distributions = append(distributions, pm)
+		} else {
+			values = append(values, pm)
+		}
+	}
+	return
+}
+
 func isInternalMetric(metricName string) bool {
 	//For each endpoint, Prometheus produces a set of internal metrics. See https://prometheus.io/docs/concepts/jobs_instances/
 	return metricName == "up" || strings.HasPrefix(metricName, "scrape_")
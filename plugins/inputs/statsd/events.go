@@ -0,0 +1,274 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatchlogs"
+	"github.com/aws/amazon-cloudwatch-agent/tool/util"
+)
+
+const (
+	eventPrefix        = "_e{"
+	serviceCheckPrefix = "_sc|"
+
+	eventsDestination = "cloudwatchlogs"
+	// maxPendingEvents is the number of events buffered until the log agent publishes them.
+	maxPendingEvents = 1000
+)
+
+// event is a DogStatsD event published as a JSON log event, see
+// https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/#events
+type event struct {
+	Title          string            `json:"title"`
+	Text           string            `json:"text"`
+	Hostname       string            `json:"hostname,omitempty"`
+	AggregationKey string            `json:"aggregation_key,omitempty"`
+	Priority       string            `json:"priority,omitempty"`
+	SourceTypeName string            `json:"source_type_name,omitempty"`
+	AlertType      string            `json:"alert_type,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+
+	timestamp time.Time
+	message   string
+}
+
+var _ logs.LogEvent = (*event)(nil)
+
+func (e *event) Message() string {
+	return e.message
+}
+
+func (e *event) Time() time.Time {
+	return e.timestamp
+}
+
+func (e *event) Done() {}
+
+// eventSource is the log source of the events of the plugin. The log agent and the metrics pipeline
+// share the instance of the plugin, its parsers queue the events which the log agent publishes.
+type eventSource struct {
+	group  string
+	stream string
+
+	events     chan *event
+	done       chan struct{}
+	startOnce  sync.Once
+	stopOnce   sync.Once
+	dropLogged atomic.Bool
+}
+
+var _ logs.LogSrc = (*eventSource)(nil)
+
+func newEventSource(group, stream string) *eventSource {
+	return &eventSource{
+		group:  group,
+		stream: stream,
+		events: make(chan *event, maxPendingEvents),
+		done:   make(chan struct{}),
+	}
+}
+
+// add queues the event, or drops it when the log agent does not keep up or does not publish to
+// cloudwatchlogs. The drops are only logged once.
+func (src *eventSource) add(e *event) {
+	select {
+	case src.events <- e:
+	default:
+		if src.dropLogged.CompareAndSwap(false, true) {
+			log.Printf("W! Statsd event queue of %s/%s is full, dropping the events until it is published to cloudwatchlogs", src.group, src.stream)
+		}
+	}
+}
+
+func (src *eventSource) SetOutput(fn func(logs.LogEvent)) {
+	if fn == nil {
+		return
+	}
+	src.startOnce.Do(func() {
+		go func() {
+			for {
+				select {
+				case e := <-src.events:
+					fn(e)
+				case <-src.done:
+					fn(nil)
+					return
+				}
+			}
+		}()
+	})
+}
+
+func (src *eventSource) Group() string {
+	return src.group
+}
+
+func (src *eventSource) Stream() string {
+	return src.stream
+}
+
+func (src *eventSource) Destination() string {
+	return eventsDestination
+}
+
+func (src *eventSource) Description() string {
+	return "statsd events"
+}
+
+func (src *eventSource) Retention() int {
+	return -1
+}
+
+func (src *eventSource) Class() string {
+	return util.StandardLogGroupClass
+}
+
+func (src *eventSource) Entity() *cloudwatchlogs.Entity {
+	return nil
+}
+
+func (src *eventSource) Stop() {
+	src.stopOnce.Do(func() { close(src.done) })
+}
+
+// FindLogSrc returns the source of the events once, when the events are published to a log group.
+func (s *Statsd) FindLogSrc() []logs.LogSrc {
+	if s.EventsLogGroupName == "" || s.eventSourceFound {
+		return nil
+	}
+	s.eventSourceFound = true
+	return []logs.LogSrc{s.eventSource()}
+}
+
+// eventSource returns the source of the events, created on first use by the log agent or the parsers.
+func (s *Statsd) eventSource() *eventSource {
+	s.eventsOnce.Do(func() {
+		s.events = newEventSource(s.EventsLogGroupName, s.EventsLogStreamName)
+	})
+	return s.events
+}
+
+// parseEvent parses an event, _e{<title length>,<text length>}:<title>|<text>|d:<timestamp>|h:<hostname>|
+// k:<aggregation key>|p:<priority>|s:<source type>|t:<alert type>|#<tags>
+func (s *Statsd) parseEvent(line string) error {
+	lengths, rest, ok := strings.Cut(line[len(eventPrefix):], "}:")
+	if !ok {
+		return fmt.Errorf("invalid statsd event: %s", line)
+	}
+	titleLength, textLength, ok := strings.Cut(lengths, ",")
+	if !ok {
+		return fmt.Errorf("invalid statsd event lengths: %s", line)
+	}
+	tl, err := strconv.Atoi(titleLength)
+	if err != nil || tl <= 0 {
+		return fmt.Errorf("invalid statsd event title length: %s", line)
+	}
+	xl, err := strconv.Atoi(textLength)
+	if err != nil || xl < 0 || tl+1+xl > len(rest) || rest[tl] != '|' {
+		return fmt.Errorf("invalid statsd event text length: %s", line)
+	}
+	e := &event{
+		Title:     rest[:tl],
+		Text:      strings.ReplaceAll(rest[tl+1:tl+1+xl], "\\n", "\n"),
+		timestamp: time.Now(),
+	}
+	if rest = rest[tl+1+xl:]; rest != "" {
+		if rest[0] != '|' {
+			return fmt.Errorf("invalid statsd event text length: %s", line)
+		}
+		for _, field := range strings.Split(rest[1:], "|") {
+			switch {
+			case strings.HasPrefix(field, "d:"):
+				if seconds, err := strconv.ParseInt(field[2:], 10, 64); err == nil {
+					e.timestamp = time.Unix(seconds, 0)
+				}
+			case strings.HasPrefix(field, "h:"):
+				e.Hostname = field[2:]
+			case strings.HasPrefix(field, "k:"):
+				e.AggregationKey = field[2:]
+			case strings.HasPrefix(field, "p:"):
+				e.Priority = field[2:]
+			case strings.HasPrefix(field, "s:"):
+				e.SourceTypeName = field[2:]
+			case strings.HasPrefix(field, "t:"):
+				e.AlertType = field[2:]
+			case strings.HasPrefix(field, "#"):
+				e.Tags = parseDataDogTags(field[1:], e.Tags)
+			}
+		}
+	}
+	if s.EventsLogGroupName == "" {
+		if s.eventsDropLogged.CompareAndSwap(false, true) {
+			log.Printf("I! Statsd events are dropped, events_log_group_name is not set")
+		}
+		return nil
+	}
+	message, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	e.message = string(message)
+	s.eventSource().add(e)
+	return nil
+}
+
+// parseServiceCheck parses a service check, _sc|<name>|<status>|d:<timestamp>|h:<hostname>|#<tags>|m:<message>,
+// into a gauge of the status: 0 for OK, 1 for WARNING, 2 for CRITICAL and 3 for UNKNOWN.
+func (s *Statsd) parseServiceCheck(line string) error {
+	fields := strings.Split(line[len(serviceCheckPrefix):], "|")
+	if len(fields) < 2 || fields[0] == "" {
+		return fmt.Errorf("invalid statsd service check: %s", line)
+	}
+	status, err := strconv.Atoi(fields[1])
+	if err != nil || status < 0 || status > 3 {
+		return fmt.Errorf("invalid statsd service check status: %s", line)
+	}
+	var lineTags map[string]string
+	for _, field := range fields[2:] {
+		if strings.HasPrefix(field, "#") {
+			lineTags = parseDataDogTags(field[1:], lineTags)
+		}
+	}
+	m := metric{
+		bucket:     fields[0],
+		mtype:      "g",
+		floatvalue: float64(status),
+	}
+	m.name, m.field, m.tags = s.parseName(m.bucket)
+	m.tags["metric_type"] = "service_check"
+	for k, v := range lineTags {
+		m.tags[k] = v
+	}
+	m.hash = metricHash(m.name, m.tags)
+	s.aggregate(m)
+	return nil
+}
+
+// parseDataDogTags adds the comma separated tags to the map, tags without values get the value
+// <empty> since CloudWatch does not allow empty dimension values.
+func parseDataDogTags(tagstr string, tags map[string]string) map[string]string {
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+	for _, tag := range strings.Split(tagstr, ",") {
+		k, v, ok := strings.Cut(tag, ":")
+		if !ok {
+			v = "<empty>"
+		}
+		if k != "" {
+			tags[k] = v
+		}
+	}
+	return tags
+}
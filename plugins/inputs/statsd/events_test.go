@@ -0,0 +1,118 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+)
+
+func TestParse_Distributions(t *testing.T) {
+	s := NewTestStatsd()
+	s.ParseDataDogTags = true
+	acc := &testutil.Accumulator{}
+
+	for _, line := range []string{"request.latency:10|d|#env:prod", "request.latency:20|d|@0.5|#env:prod"} {
+		require.NoError(t, s.parseStatsdLine(line))
+	}
+	require.NoError(t, s.Gather(acc))
+
+	dist := distribution.NewDistribution()
+	assert.NoError(t, dist.AddEntry(10, 1))
+	assert.NoError(t, dist.AddEntry(20, 2))
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, "request_latency", acc.Metrics[0].Measurement)
+	assert.Equal(t, map[string]string{"metric_type": "distribution", "env": "prod"}, acc.Metrics[0].Tags)
+	assert.Equal(t, dist, acc.Metrics[0].Fields[defaultFieldName])
+}
+
+func TestParse_ServiceChecks(t *testing.T) {
+	s := NewTestStatsd()
+	acc := &testutil.Accumulator{}
+
+	require.NoError(t, s.parseStatsdLine("_sc|app.is_ok|2|d:1700000000|h:host-a|#env:prod,canary|m:connection refused"))
+	for _, line := range []string{"_sc|app.is_ok", "_sc||0", "_sc|app.is_ok|4", "_sc|app.is_ok|ok"} {
+		assert.Error(t, s.parseStatsdLine(line), line)
+	}
+	require.NoError(t, s.Gather(acc))
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, "app_is_ok", acc.Metrics[0].Measurement)
+	assert.Equal(t, map[string]string{"metric_type": "service_check", "env": "prod", "canary": "<empty>"}, acc.Metrics[0].Tags)
+	assert.Equal(t, float64(2), acc.Metrics[0].Fields[defaultFieldName])
+}
+
+func TestParse_Events(t *testing.T) {
+	s := NewTestStatsd()
+	s.EventsLogGroupName = "TestParse_Events"
+	src := s.eventSource()
+
+	require.NoError(t, s.parseStatsdLine("_e{6,13}:Deploy|line1\\nline 2|d:1700000000|h:host-a|p:low|t:warning|#env:prod,canary"))
+	require.NoError(t, s.parseStatsdLine("_e{7,0}:Restart|"))
+	for _, line := range []string{"_e{6,13}:Deploy", "_e{0,1}:|a", "_e{6,20}:Deploy|too short", "_e{a,1}:Deploy|a", "_e{6,1}:Deploy|ab"} {
+		assert.Error(t, s.parseStatsdLine(line), line)
+	}
+
+	require.Len(t, src.events, 2)
+	e := <-src.events
+	assert.Equal(t, time.Unix(1700000000, 0), e.Time())
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(e.Message()), &got))
+	assert.Equal(t, map[string]interface{}{
+		"title":      "Deploy",
+		"text":       "line1\nline 2",
+		"hostname":   "host-a",
+		"priority":   "low",
+		"alert_type": "warning",
+		"tags":       map[string]interface{}{"env": "prod", "canary": "<empty>"},
+	}, got)
+	e = <-src.events
+	assert.JSONEq(t, `{"title":"Restart","text":""}`, e.Message())
+
+	s.EventsLogGroupName = ""
+	require.NoError(t, s.parseStatsdLine("_e{7,0}:Dropped|"))
+	assert.Empty(t, src.events, "events should be dropped without a log group")
+}
+
+func TestEventSource(t *testing.T) {
+	// The log agent does not make the plugin listen, it publishes the events parsed by the metrics pipeline.
+	s := NewTestStatsd()
+	s.EventsLogGroupName = "TestEventSource"
+	require.NoError(t, s.Start(nil))
+	assert.Nil(t, s.listener)
+	srcs := s.FindLogSrc()
+	require.Len(t, srcs, 1)
+	assert.Empty(t, s.FindLogSrc(), "the source should only be found once")
+	assert.Equal(t, "TestEventSource", srcs[0].Group())
+	assert.Equal(t, eventsDestination, srcs[0].Destination())
+
+	received := make(chan logs.LogEvent, 2)
+	srcs[0].SetOutput(func(e logs.LogEvent) {
+		received <- e
+	})
+	require.NoError(t, s.parseStatsdLine("_e{5,4}:title|text"))
+	select {
+	case e := <-received:
+		assert.JSONEq(t, `{"title":"title","text":"text"}`, e.Message())
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the event has not been published")
+	}
+
+	srcs[0].Stop()
+	select {
+	case e := <-received:
+		assert.Nil(t, e, "the output should be closed once the source is stopped")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the output has not been closed")
+	}
+	assert.Empty(t, (&Statsd{}).FindLogSrc(), "there should be no source without a log group")
+}
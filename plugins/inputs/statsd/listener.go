@@ -0,0 +1,196 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+const defaultMaxTCPConnections = 250
+
+// parseServiceAddress splits the service address into the network and the address to listen on.
+// Addresses without a scheme, e.g. ":8125", are UDP addresses.
+func parseServiceAddress(serviceAddress string) (network string, address string, err error) {
+	scheme, address, ok := strings.Cut(serviceAddress, "://")
+	if !ok {
+		return "udp", serviceAddress, nil
+	}
+	switch scheme {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+		if address == "" {
+			return "", "", fmt.Errorf("statsd service address %q has no address", serviceAddress)
+		}
+		return scheme, address, nil
+	}
+	return "", "", fmt.Errorf("statsd service address %q has an unsupported scheme %q", serviceAddress, scheme)
+}
+
+// isStreamNetwork returns true for the networks whose messages are newline delimited over connections.
+func isStreamNetwork(network string) bool {
+	return strings.HasPrefix(network, "tcp") || network == "unix"
+}
+
+// listen opens the listener of the service address and starts reading from it.
+func (s *Statsd) listen() error {
+	network, address, err := parseServiceAddress(s.ServiceAddress)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(network, "unix") {
+		// A socket file left behind by a previous run would make the listen fail.
+		if err = os.Remove(address); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("unable to remove the statsd socket %s: %w", address, err)
+		}
+		s.socketPath = address
+	}
+	if isStreamNetwork(network) {
+		s.streamListener, err = net.Listen(network, address)
+		if err != nil {
+			return fmt.Errorf("unable to listen on %s://%s: %w", network, address, err)
+		}
+		if s.MaxTCPConnections <= 0 {
+			s.MaxTCPConnections = defaultMaxTCPConnections
+		}
+		s.connSlots = make(chan struct{}, s.MaxTCPConnections)
+		s.conns = make(map[net.Conn]struct{})
+		log.Printf("I! Statsd listener listening on: %s://%s", network, s.streamListener.Addr().String())
+		s.wg.Add(1)
+		go s.streamListen()
+		return nil
+	}
+	s.listener, err = net.ListenPacket(network, address)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s://%s: %w", network, address, err)
+	}
+	log.Printf("I! Statsd listener listening on: %s://%s", network, s.listener.LocalAddr().String())
+	s.wg.Add(1)
+	go s.packetListen()
+	return nil
+}
+
+// packetListen reads the datagrams of the udp or unixgram listener.
+func (s *Statsd) packetListen() {
+	defer s.wg.Done()
+	buf := make([]byte, UDP_MAX_PACKET_SIZE)
+	for {
+		n, _, err := s.listener.ReadFrom(buf)
+		if err != nil {
+			if s.isStopping() || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("E! Error READ: %s\n", err.Error())
+			continue
+		}
+		bufCopy := make([]byte, n)
+		copy(bufCopy, buf[:n])
+		s.enqueue(bufCopy)
+	}
+}
+
+// streamListen accepts the connections of the tcp or unix listener, up to MaxTCPConnections at a time.
+func (s *Statsd) streamListen() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.streamListener.Accept()
+		if err != nil {
+			if s.isStopping() || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("E! Error ACCEPT: %s\n", err.Error())
+			continue
+		}
+		select {
+		case s.connSlots <- struct{}{}:
+		default:
+			log.Printf("W! Statsd refused a connection from %s, the max of %d connections is reached", conn.RemoteAddr(), s.MaxTCPConnections)
+			conn.Close()
+			continue
+		}
+		// the connection is registered under the lock checked by closeListener, so a connection
+		// accepted while the listener is closed is either closed by it or closed here
+		s.connsMu.Lock()
+		if s.isStopping() {
+			s.connsMu.Unlock()
+			conn.Close()
+			<-s.connSlots
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.connsMu.Unlock()
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn reads the newline delimited messages of a connection until it is closed.
+func (s *Statsd) handleConn(conn net.Conn) {
+	defer func() {
+		s.connsMu.Lock()
+		delete(s.conns, conn)
+		s.connsMu.Unlock()
+		conn.Close()
+		<-s.connSlots
+		s.wg.Done()
+	}()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), UDP_MAX_PACKET_SIZE)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		lineCopy := make([]byte, len(line))
+		copy(lineCopy, line)
+		s.enqueue(lineCopy)
+	}
+	if err := scanner.Err(); err != nil && !s.isStopping() && !errors.Is(err, net.ErrClosed) {
+		log.Printf("E! Error READ from %s: %s\n", conn.RemoteAddr(), err.Error())
+	}
+}
+
+// enqueue hands the message over to the parser, or drops it when too many messages are pending.
+func (s *Statsd) enqueue(message []byte) {
+	s.received.Add(1)
+	select {
+	case s.in <- message:
+	default:
+		drops := s.drops.Add(1)
+		if drops == 1 || s.AllowedPendingMessages == 0 || drops%int64(s.AllowedPendingMessages) == 0 {
+			log.Printf(dropwarn, drops)
+		}
+	}
+}
+
+func (s *Statsd) isStopping() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeListener closes the listener and the open connections, which stops the goroutines reading them.
+func (s *Statsd) closeListener() {
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	if s.streamListener != nil {
+		s.streamListener.Close()
+		s.connsMu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.connsMu.Unlock()
+	}
+	if s.socketPath != "" {
+		os.Remove(s.socketPath)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServiceAddress(t *testing.T) {
+	testCases := map[string]struct {
+		input       string
+		wantNetwork string
+		wantAddress string
+		wantErr     bool
+	}{
+		"WithoutScheme":    {input: ":8125", wantNetwork: "udp", wantAddress: ":8125"},
+		"WithUDP":          {input: "udp://127.0.0.1:8125", wantNetwork: "udp", wantAddress: "127.0.0.1:8125"},
+		"WithTCP":          {input: "tcp://:8125", wantNetwork: "tcp", wantAddress: ":8125"},
+		"WithUnix":         {input: "unix:///var/run/statsd.sock", wantNetwork: "unix", wantAddress: "/var/run/statsd.sock"},
+		"WithUnixgram":     {input: "unixgram:///var/run/statsd.sock", wantNetwork: "unixgram", wantAddress: "/var/run/statsd.sock"},
+		"WithUnsupported":  {input: "http://:8125", wantErr: true},
+		"WithEmptyAddress": {input: "tcp://", wantErr: true},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			network, address, err := parseServiceAddress(testCase.input)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.wantNetwork, network)
+			assert.Equal(t, testCase.wantAddress, address)
+		})
+	}
+}
+
+func newListeningStatsd(t *testing.T, serviceAddress string) *Statsd {
+	s := &Statsd{
+		ServiceAddress:         serviceAddress,
+		AllowedPendingMessages: 100,
+		MaxTCPConnections:      1,
+	}
+	require.NoError(t, s.Start(&testutil.Accumulator{}))
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// assertCounter waits for the parser to cache the counter and checks it through Gather.
+func assertCounter(t *testing.T, s *Statsd, name string, want int64) {
+	assert.Eventually(t, func() bool {
+		acc := &testutil.Accumulator{}
+		require.NoError(t, s.Gather(acc))
+		got, ok := acc.Get(name)
+		return ok && got.Fields["value"] == want
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTCPListener(t *testing.T) {
+	s := newListeningStatsd(t, "tcp://127.0.0.1:0")
+
+	conn, err := net.Dial("tcp", s.streamListener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("requests:1|c\nrequests:2|c\n"))
+	require.NoError(t, err)
+	// Messages split over writes should be parsed once the line is complete.
+	_, err = conn.Write([]byte("requests:"))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("3|c\n"))
+	require.NoError(t, err)
+	assertCounter(t, s, "requests", 6)
+
+	// The second connection is beyond MaxTCPConnections.
+	refused, err := net.Dial("tcp", s.streamListener.Addr().String())
+	require.NoError(t, err)
+	defer refused.Close()
+	require.NoError(t, refused.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = refused.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestStopWhileConnecting(t *testing.T) {
+	s := &Statsd{
+		ServiceAddress:         "tcp://127.0.0.1:0",
+		AllowedPendingMessages: 100,
+		MaxTCPConnections:      100,
+	}
+	require.NoError(t, s.Start(&testutil.Accumulator{}))
+	addr := s.streamListener.Addr().String()
+
+	// The connections accepted while the listener is closed must not keep Stop waiting.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if conn, err := net.Dial("tcp", addr); err == nil {
+					defer conn.Close()
+				}
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestUnixListeners(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram sockets are not supported on windows")
+	}
+	for _, network := range []string{"unix", "unixgram"} {
+		t.Run(network, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "statsd.sock")
+			s := newListeningStatsd(t, network+"://"+path)
+
+			conn, err := net.Dial(network, path)
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = conn.Write([]byte("requests:1|c\n"))
+			require.NoError(t, err)
+			assertCounter(t, s, "requests", 1)
+		})
+	}
+}
+
+func TestStartWithInvalidServiceAddress(t *testing.T) {
+	s := &Statsd{ServiceAddress: "http://:8125"}
+	assert.Error(t, s.Start(&testutil.Accumulator{}))
+}
+
+func TestParserWorkers(t *testing.T) {
+	s := &Statsd{
+		ServiceAddress:         "tcp://127.0.0.1:0",
+		AllowedPendingMessages: 1000,
+		ParserWorkers:          4,
+		Templates:              []string{"measurement.measurement.field"},
+	}
+	require.NoError(t, s.Start(&testutil.Accumulator{}))
+	t.Cleanup(s.Stop)
+
+	conn, err := net.Dial("tcp", s.streamListener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	for i := 0; i < 100; i++ {
+		_, err = conn.Write([]byte("cpu.time.idle,host=a:1|c\n"))
+		require.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		acc := &testutil.Accumulator{}
+		require.NoError(t, s.Gather(acc))
+		got, ok := acc.Get("cpu_time")
+		return ok && got.Fields["idle"] == int64(100) && got.Tags["host"] == "a"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSelfMetrics(t *testing.T) {
+	s := NewTestStatsd()
+	s.ServiceAddress = ":8125"
+	s.AllowedPendingMessages = 2
+	s.SelfMetrics = true
+	s.in = make(chan []byte, s.AllowedPendingMessages)
+
+	s.enqueue([]byte("requests:1|c"))
+	s.enqueue([]byte("requests:1"))
+	s.enqueue([]byte("requests:1|c"))
+	s.parsePacket(<-s.in)
+	s.parsePacket(<-s.in)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+	got, ok := acc.Get(selfMeasurement)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"service_address": ":8125"}, got.Tags)
+	assert.Equal(t, map[string]interface{}{
+		"packets_received": int64(3),
+		"packets_dropped":  int64(1),
+		"parse_errors":     int64(1),
+		"unique_series":    int64(1),
+	}, got.Fields)
+
+	acc = &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+	got, ok = acc.Get(selfMeasurement)
+	require.True(t, ok)
+	assert.Equal(t, int64(0), got.Fields["packets_received"], "the counts should be reset on Gather")
+	assert.Equal(t, int64(0), got.Fields["packets_dropped"])
+}
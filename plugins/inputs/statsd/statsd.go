@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	//"github.com/influxdata/telegraf/plugins/parsers/graphite"
@@ -31,6 +32,10 @@ const (
 
 	defaultSeparator           = "_"
 	defaultAllowPendingMessage = 10000
+	defaultParserWorkers       = 1
+
+	// selfMeasurement is the measurement of the listener's own metrics.
+	selfMeasurement = "statsd"
 )
 
 var dropwarn = "E! Error: statsd message queue full. " +
@@ -38,9 +43,25 @@ var dropwarn = "E! Error: statsd message queue full. " +
 	"You may want to increase allowed_pending_messages in the config\n"
 
 type Statsd struct {
-	// Address & Port to serve from
+	// Address & Port to serve from. The address can be prefixed by the scheme of the network to listen on:
+	// udp:// (default), tcp://, unix:// or unixgram://.
 	ServiceAddress string
 
+	// ParserWorkers is the number of goroutines parsing the messages.
+	ParserWorkers int `toml:"parser_workers"`
+
+	// SelfMetrics enables the statsd measurement of the messages received, dropped and not parsed, and
+	// of the series cached since the last Gather.
+	SelfMetrics bool `toml:"self_metrics"`
+
+	// MaxTCPConnections is the max number of concurrent connections of the tcp and unix listeners.
+	MaxTCPConnections int `toml:"max_tcp_connections"`
+
+	// EventsLogGroupName is the log group the DogStatsD events are published to, they are dropped when
+	// it is empty. The log stream defaults to the one of the logs section.
+	EventsLogGroupName  string `toml:"events_log_group_name"`
+	EventsLogStreamName string `toml:"events_log_stream_name"`
+
 	// Number of messages allowed to queue up in between calls to Gather. If this
 	// fills up, packets will get dropped until the next Gather interval is ran.
 	AllowedPendingMessages int
@@ -65,7 +86,12 @@ type Statsd struct {
 	sync.Mutex
 	wg sync.WaitGroup
 	// drops tracks the number of dropped metrics.
-	drops int
+	drops atomic.Int64
+	// reportedDrops is the number of dropped metrics at the last Gather.
+	reportedDrops int64
+	// received and parseErrors track the number of messages received and not parsed since the last Gather.
+	received    atomic.Int64
+	parseErrors atomic.Int64
 
 	// Channel for all incoming statsd packets
 	in   chan []byte
@@ -82,9 +108,23 @@ type Statsd struct {
 	// bucket -> influx templates
 	Templates []string
 
-	listener *net.UDPConn
-
-	graphiteParser *graphite.GraphiteParser
+	// listener reads the udp and unixgram messages.
+	listener net.PacketConn
+	// streamListener accepts the tcp and unix connections.
+	streamListener net.Listener
+	connSlots      chan struct{}
+	connsMu        sync.Mutex
+	conns          map[net.Conn]struct{}
+	// socketPath is the file of the unix socket listened on.
+	socketPath string
+	// eventSourceFound is set once the log agent got the source of the events.
+	eventSourceFound bool
+	eventsOnce       sync.Once
+	events           *eventSource
+	eventsDropLogged atomic.Bool
+
+	graphiteParserMu sync.Mutex
+	graphiteParser   *graphite.GraphiteParser
 }
 
 // One statsd metric, form is <bucket>:<value>|<mtype>|@<samplerate>
@@ -131,9 +171,18 @@ func (_ *Statsd) Description() string {
 }
 
 const sampleConfig = `
-  ## Address and port to host UDP listener on
+  ## Address and port to host UDP listener on. Prefix it with tcp:// to accept
+  ## newline delimited messages over TCP, or use unix:///path/to/socket and
+  ## unixgram:///path/to/socket to listen on Unix domain sockets.
   service_address = ":8125"
 
+  ## Max number of concurrent TCP or Unix stream connections
+  max_tcp_connections = 250
+
+  ## Log group and stream the DogStatsD events are published to
+  # events_log_group_name = "statsd-events"
+  # events_log_stream_name = "{instance_id}"
+
   ## The following configuration options control when telegraf clears it's cache
   ## of previous values. If set to false, then telegraf will only clear it's
   ## cache when the daemon is restarted.
@@ -163,6 +212,13 @@ const sampleConfig = `
   ## the statsd server will start dropping packets
   allowed_pending_messages = 10000
 
+  ## Number of goroutines parsing the messages
+  parser_workers = 1
+
+  ## Adds the statsd measurement of the messages received, dropped and not
+  ## parsed, and of the series cached, during each interval
+  self_metrics = false
+
   ## The aggregation interval for the metrics
   metric_aggregation_interval = "60s"
 
@@ -177,6 +233,10 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 	defer s.Unlock()
 	now := time.Now()
 
+	if s.SelfMetrics {
+		s.gatherSelfMetrics(acc, now)
+	}
+
 	for _, metric := range s.timings {
 		acc.AddHistogram(metric.name, metric.fields, metric.tags, now)
 	}
@@ -212,7 +272,26 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (s *Statsd) Start(_ telegraf.Accumulator) error {
+// gatherSelfMetrics adds the number of messages received, dropped and not parsed since the last Gather,
+// and the number of series cached.
+func (s *Statsd) gatherSelfMetrics(acc telegraf.Accumulator, now time.Time) {
+	drops := s.drops.Load()
+	fields := map[string]interface{}{
+		"packets_received": s.received.Swap(0),
+		"packets_dropped":  drops - s.reportedDrops,
+		"parse_errors":     s.parseErrors.Swap(0),
+		"unique_series":    int64(len(s.gauges) + len(s.counters) + len(s.sets) + len(s.timings)),
+	}
+	s.reportedDrops = drops
+	acc.AddFields(selfMeasurement, fields, map[string]string{"service_address": s.ServiceAddress}, now)
+}
+
+func (s *Statsd) Start(acc telegraf.Accumulator) error {
+	// The log agent starts the log collections without an accumulator, it only publishes the events
+	// parsed once the metrics pipeline starts the plugin with one.
+	if acc == nil {
+		return nil
+	}
 	// Make data structures
 	s.done = make(chan struct{})
 	s.in = make(chan []byte, s.AllowedPendingMessages)
@@ -226,50 +305,20 @@ func (s *Statsd) Start(_ telegraf.Accumulator) error {
 		s.MetricSeparator = defaultSeparator
 	}
 
-	s.wg.Add(2)
-	// Start the UDP listener
-	go s.udpListen()
-	// Start the line parser
-	go s.parser()
-	log.Printf("I! Started the statsd service on %s\n", s.ServiceAddress)
-	return nil
-}
-
-// udpListen starts listening for udp packets on the configured port.
-func (s *Statsd) udpListen() error {
-	defer s.wg.Done()
-	var err error
-	address, _ := net.ResolveUDPAddr("udp", s.ServiceAddress)
-	s.listener, err = net.ListenUDP("udp", address)
-	if err != nil {
-		log.Fatalf("ERROR: ListenUDP - %s", err)
+	// Start the listener
+	if err := s.listen(); err != nil {
+		return err
 	}
-	log.Println("I! Statsd listener listening on: ", s.listener.LocalAddr().String())
-
-	buf := make([]byte, UDP_MAX_PACKET_SIZE)
-	for {
-		select {
-		case <-s.done:
-			return nil
-		default:
-			n, _, err := s.listener.ReadFromUDP(buf)
-			if err != nil && !strings.Contains(err.Error(), "closed network") {
-				log.Printf("E! Error READ: %s\n", err.Error())
-				continue
-			}
-			bufCopy := make([]byte, n)
-			copy(bufCopy, buf[:n])
-
-			select {
-			case s.in <- bufCopy:
-			default:
-				s.drops++
-				if s.drops == 1 || s.AllowedPendingMessages == 0 || s.drops%s.AllowedPendingMessages == 0 {
-					log.Printf(dropwarn, s.drops)
-				}
-			}
-		}
+	if s.ParserWorkers <= 0 {
+		s.ParserWorkers = defaultParserWorkers
 	}
+	// Start the line parsers
+	s.wg.Add(s.ParserWorkers)
+	for i := 0; i < s.ParserWorkers; i++ {
+		go s.parser()
+	}
+	log.Printf("I! Started the statsd service on %s\n", s.ServiceAddress)
+	return nil
 }
 
 // parser monitors the s.in channel, if there is a packet ready, it parses the
@@ -283,13 +332,18 @@ func (s *Statsd) parser() error {
 		case <-s.done:
 			return nil
 		case packet = <-s.in:
-			lines := strings.Split(string(packet), "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if line != "" {
-					s.parseStatsdLine(line)
-				}
-			}
+			s.parsePacket(packet)
+		}
+	}
+}
+
+// parsePacket parses the statsd lines of the packet and counts the lines which could not be parsed.
+func (s *Statsd) parsePacket(packet []byte) {
+	lines := strings.Split(string(packet), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && s.parseStatsdLine(line) != nil {
+			s.parseErrors.Add(1)
 		}
 	}
 }
@@ -297,6 +351,21 @@ func (s *Statsd) parser() error {
 // parseStatsdLine will parse the given statsd line, validating it as it goes.
 // If the line is valid, it will be cached for the next call to Gather()
 func (s *Statsd) parseStatsdLine(line string) error {
+	// DogStatsD events and service checks have their own format
+	if strings.HasPrefix(line, eventPrefix) {
+		if err := s.parseEvent(line); err != nil {
+			log.Printf("E! Error: %s\n", err)
+			return errors.New("Error Parsing statsd line")
+		}
+		return nil
+	}
+	if strings.HasPrefix(line, serviceCheckPrefix) {
+		if err := s.parseServiceCheck(line); err != nil {
+			log.Printf("E! Error: %s\n", err)
+			return errors.New("Error Parsing statsd line")
+		}
+		return nil
+	}
 
 	lineTags := make(map[string]string)
 	if s.ParseDataDogTags {
@@ -310,24 +379,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		for _, segment := range pipesplit {
 			if len(segment) > 0 && segment[0] == '#' {
 				// we have ourselves a tag; they are comma separated
-				tagstr := segment[1:]
-				tags := strings.Split(tagstr, ",")
-				for _, tag := range tags {
-					ts := strings.SplitN(tag, ":", 2)
-					var k, v string
-					switch len(ts) {
-					case 1:
-						// just a tag
-						k = ts[0]
-						v = "<empty>" //cloudwatch does not allow empty string
-					case 2:
-						k = ts[0]
-						v = ts[1]
-					}
-					if k != "" {
-						lineTags[k] = v
-					}
-				}
+				parseDataDogTags(segment[1:], lineTags)
 			} else {
 				recombinedSegments = append(recombinedSegments, segment)
 			}
@@ -375,7 +427,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 
 		// Validate metric type
 		switch pipesplit[1] {
-		case "g", "c", "s", "ms", "h":
+		case "g", "c", "s", "ms", "h", "d":
 			m.mtype = pipesplit[1]
 		default:
 			log.Printf("E! Error: Statsd Metric type %s unsupported", pipesplit[1])
@@ -392,7 +444,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		}
 
 		switch m.mtype {
-		case "g", "ms", "h":
+		case "g", "ms", "h", "d":
 			v, err := strconv.ParseFloat(pipesplit[0], 64)
 			if err != nil {
 				log.Printf("E! Error: parsing value to float64: %s\n", line)
@@ -432,6 +484,8 @@ func (s *Statsd) parseStatsdLine(line string) error {
 			m.tags["metric_type"] = "timing"
 		case "h":
 			m.tags["metric_type"] = "histogram"
+		case "d":
+			m.tags["metric_type"] = "distribution"
 		}
 
 		if len(lineTags) > 0 {
@@ -440,13 +494,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 			}
 		}
 
-		// Make a unique key for the measurement name/tags
-		var tg []string
-		for k, v := range m.tags {
-			tg = append(tg, fmt.Sprintf("%s=%s", k, v))
-		}
-		sort.Strings(tg)
-		m.hash = fmt.Sprintf("%s%s", strings.Join(tg, ""), m.name)
+		m.hash = metricHash(m.name, m.tags)
 
 		s.aggregate(m)
 	}
@@ -454,6 +502,16 @@ func (s *Statsd) parseStatsdLine(line string) error {
 	return nil
 }
 
+// metricHash makes a unique key for the measurement name/tags
+func metricHash(name string, tags map[string]string) string {
+	var tg []string
+	for k, v := range tags {
+		tg = append(tg, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(tg)
+	return fmt.Sprintf("%s%s", strings.Join(tg, ""), name)
+}
+
 // parseName parses the given bucket name with the list of bucket maps in the
 // config file. If there is a match, it will parse the name of the metric and
 // map of tags.
@@ -475,17 +533,20 @@ func (s *Statsd) parseName(bucket string) (string, string, map[string]string) {
 	var field string
 	name := bucketparts[0]
 
-	p := s.graphiteParser
-	var err error
-
-	if p == nil || s.graphiteParser.Separator != s.MetricSeparator {
-		p, err = graphite.NewGraphiteParser(s.MetricSeparator, s.Templates, nil)
-		s.graphiteParser = p
-	}
-
-	if err == nil {
-		p.DefaultTags = tags
-		name, tags, field, _ = p.ApplyTemplateForMetricName(name)
+	if p, err := s.templateParser(); err == nil {
+		// The parser is shared by the parser workers, so the bucket tags are merged here rather than
+		// set as its default tags.
+		var templateTags map[string]string
+		name, templateTags, field, _ = p.ApplyTemplateForMetricName(name)
+		if templateTags == nil {
+			templateTags = make(map[string]string)
+		}
+		for k, v := range tags {
+			if _, ok := templateTags[k]; !ok {
+				templateTags[k] = v
+			}
+		}
+		tags = templateTags
 	}
 
 	if field == "" {
@@ -495,6 +556,20 @@ func (s *Statsd) parseName(bucket string) (string, string, map[string]string) {
 	return name, field, tags
 }
 
+// templateParser returns the parser of the bucket templates, it is created on first use.
+func (s *Statsd) templateParser() (*graphite.GraphiteParser, error) {
+	s.graphiteParserMu.Lock()
+	defer s.graphiteParserMu.Unlock()
+	if s.graphiteParser == nil || s.graphiteParser.Separator != s.MetricSeparator {
+		p, err := graphite.NewGraphiteParser(s.MetricSeparator, s.Templates, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.graphiteParser = p
+	}
+	return s.graphiteParser, nil
+}
+
 // Parse the key,value out of a string that looks like "key=value"
 func parseKeyValue(keyvalue string) (string, string) {
 	var key, val string
@@ -519,7 +594,7 @@ func (s *Statsd) aggregate(m metric) {
 	defer s.Unlock()
 
 	switch m.mtype {
-	case "ms", "h":
+	case "ms", "h", "d":
 		// Check if the measurement exists
 		cached, ok := s.timings[m.hash]
 		if !ok {
@@ -604,9 +679,13 @@ func (s *Statsd) aggregate(m metric) {
 }
 
 func (s *Statsd) Stop() {
+	if s.done == nil {
+		// The instance of the log agent has not been started.
+		return
+	}
 	log.Println("D! Stopping the statsd service")
 	close(s.done)
-	s.listener.Close()
+	s.closeListener()
 	s.wg.Wait()
 	close(s.in)
 	log.Println("D! Stopped the statsd service")
@@ -616,6 +695,8 @@ func init() {
 	inputs.Add("statsd", func() telegraf.Input {
 		return &Statsd{
 			ServiceAddress:         ":8125",
+			MaxTCPConnections:      defaultMaxTCPConnections,
+			ParserWorkers:          defaultParserWorkers,
 			MetricSeparator:        "_",
 			AllowedPendingMessages: defaultAllowPendingMessage,
 			DeleteCounters:         true,
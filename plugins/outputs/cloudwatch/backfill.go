@@ -0,0 +1,80 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"log"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+)
+
+const (
+	// maxDatumAge is how old a datum CloudWatch still accepts.
+	maxDatumAge                         = 14 * 24 * time.Hour
+	defaultBackfillMaxRequestsPerSecond = 10
+	defaultBackfillBucket               = time.Minute
+)
+
+// batchKey identifies a batch of datums, every namespace is published in its own requests and, in
+// backfill mode, so is every time bucket.
+type batchKey struct {
+	namespace string
+	// bucket is the start of the time bucket of the datums in unix seconds, 0 outside of backfill mode.
+	bucket int64
+}
+
+func (c *CloudWatch) backfillBucket() time.Duration {
+	if c.config.BackfillBucket > 0 {
+		return c.config.BackfillBucket
+	}
+	return defaultBackfillBucket
+}
+
+func (c *CloudWatch) backfillInterval() time.Duration {
+	maxRequestsPerSecond := c.config.BackfillMaxRequestsPerSecond
+	if maxRequestsPerSecond <= 0 {
+		maxRequestsPerSecond = defaultBackfillMaxRequestsPerSecond
+	}
+	return time.Duration(float64(time.Second) / maxRequestsPerSecond)
+}
+
+// batchKeyOf returns the key of the batch the datum is added to.
+func (c *CloudWatch) batchKeyOf(namespace string, datum *cloudwatch.MetricDatum) batchKey {
+	key := batchKey{namespace: namespace}
+	if c.config.Backfill && datum.Timestamp != nil {
+		key.bucket = datum.Timestamp.Truncate(c.backfillBucket()).Unix()
+	}
+	return key
+}
+
+// rejectExpired drops the datums CloudWatch would reject for being older than two weeks, and counts them.
+func (c *CloudWatch) rejectExpired(datums []*aggregationDatum) []*aggregationDatum {
+	cutoff := time.Now().Add(-maxDatumAge)
+	kept := datums[:0]
+	var rejected int64
+	for _, d := range datums {
+		if d.Timestamp != nil && d.Timestamp.Before(cutoff) {
+			rejected++
+			continue
+		}
+		kept = append(kept, d)
+	}
+	if rejected > 0 {
+		total := c.backfillRejected.Add(rejected)
+		log.Printf("E! cloudwatch: rejected %d datapoints older than the %v CloudWatch accepts, %d rejected since the start", rejected, maxDatumAge, total)
+	}
+	return kept
+}
+
+// waitForBackfillRate blocks until the next PutMetricData call is allowed in backfill mode.
+func (c *CloudWatch) waitForBackfillRate() {
+	if c.backfillTicker == nil {
+		return
+	}
+	select {
+	case <-c.backfillTicker.C:
+	case <-c.shutdownChan:
+	}
+}
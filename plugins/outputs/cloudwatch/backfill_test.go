@@ -0,0 +1,72 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+)
+
+func TestRejectExpired(t *testing.T) {
+	now := time.Now()
+	newDatum := func(name string, timestamp time.Time) *aggregationDatum {
+		return &aggregationDatum{MetricDatum: cloudwatch.MetricDatum{MetricName: aws.String(name), Timestamp: aws.Time(timestamp)}}
+	}
+	cw := &CloudWatch{config: &Config{Backfill: true}}
+	recent := newDatum("recent", now.Add(-13*24*time.Hour))
+	got := cw.rejectExpired([]*aggregationDatum{newDatum("expired", now.Add(-15*24*time.Hour)), recent})
+	assert.Equal(t, []*aggregationDatum{recent}, got)
+	assert.Equal(t, int64(1), cw.backfillRejected.Load())
+
+	cw.rejectExpired([]*aggregationDatum{newDatum("expired", now.Add(-30*24*time.Hour)), newDatum("expired", now.Add(-15*24*time.Hour))})
+	assert.Equal(t, int64(3), cw.backfillRejected.Load(), "the rejected datapoints should be counted since the start")
+}
+
+func TestBatchKeyOf(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	datum := &cloudwatch.MetricDatum{MetricName: aws.String("a"), Timestamp: aws.Time(timestamp)}
+
+	cw := &CloudWatch{config: &Config{}}
+	assert.Equal(t, batchKey{namespace: "namespace"}, cw.batchKeyOf("namespace", datum), "the datums should only be grouped by namespace outside of backfill mode")
+
+	cw.config.Backfill = true
+	assert.Equal(t, batchKey{namespace: "namespace", bucket: timestamp.Truncate(time.Minute).Unix()}, cw.batchKeyOf("namespace", datum))
+	cw.config.BackfillBucket = time.Hour
+	assert.Equal(t, batchKey{namespace: "namespace", bucket: timestamp.Truncate(time.Hour).Unix()}, cw.batchKeyOf("namespace", datum))
+}
+
+func TestBackfillRate(t *testing.T) {
+	cw := &CloudWatch{config: &Config{}}
+	assert.Equal(t, 100*time.Millisecond, cw.backfillInterval())
+	cw.config.BackfillMaxRequestsPerSecond = 0.5
+	assert.Equal(t, 2*time.Second, cw.backfillInterval())
+
+	// Outside of backfill mode the calls are not paced.
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		cw.waitForBackfillRate()
+	}
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	cw.config.BackfillMaxRequestsPerSecond = 20
+	cw.shutdownChan = make(chan struct{})
+	cw.backfillTicker = time.NewTicker(cw.backfillInterval())
+	defer cw.backfillTicker.Stop()
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		cw.waitForBackfillRate()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	close(cw.shutdownChan)
+	cw.backfillTicker.Reset(time.Hour)
+	start = time.Now()
+	cw.waitForBackfillRate()
+	assert.Less(t, time.Since(start), time.Second, "the shutdown should not wait for the rate limit")
+}
@@ -0,0 +1,23 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/internal/capture"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch/cloudwatchiface"
+)
+
+// captureClient writes the PutMetricData requests to the dry run file instead of sending them.
+type captureClient struct {
+	cloudwatchiface.CloudWatchAPI
+	writer *capture.Writer
+}
+
+func (c *captureClient) PutMetricData(input *cloudwatch.PutMetricDataInput) (*cloudwatch.PutMetricDataOutput, error) {
+	if err := c.writer.Write(opPutMetricData, input); err != nil {
+		return nil, err
+	}
+	return &cloudwatch.PutMetricDataOutput{}, nil
+}
@@ -0,0 +1,52 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/capture"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+)
+
+func TestWriteToCloudWatchDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	writer, err := capture.Open(path)
+	require.NoError(t, err)
+	cw := newCloudWatchClient(&captureClient{writer: writer}, time.Second)
+	cw.config.Namespace = "CWAgent"
+
+	cw.WriteToCloudWatch(&metricDatumRequest{
+		Partition: map[string][]*cloudwatch.MetricDatum{
+			"": {{
+				MetricName: aws.String("cpu_usage_idle"),
+				Dimensions: []*cloudwatch.Dimension{{Name: aws.String("host"), Value: aws.String("a")}},
+				Value:      aws.Float64(1),
+				Timestamp:  aws.Time(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+			}},
+		},
+	})
+	require.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got struct {
+		Operation string
+		Request   cloudwatch.PutMetricDataInput
+	}
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, opPutMetricData, got.Operation)
+	assert.Equal(t, "CWAgent", *got.Request.Namespace)
+	require.Len(t, got.Request.MetricData, 1)
+	assert.Equal(t, "cpu_usage_idle", *got.Request.MetricData[0].MetricName)
+	assert.Equal(t, "a", *got.Request.MetricData[0].Dimensions[0].Value)
+}
@@ -5,15 +5,20 @@ package cloudwatch
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"reflect"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/amazon-contributing/opentelemetry-collector-contrib/extension/awsmiddleware"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -26,6 +31,7 @@ import (
 
 	configaws "github.com/aws/amazon-cloudwatch-agent/cfg/aws"
 	"github.com/aws/amazon-cloudwatch-agent/handlers"
+	"github.com/aws/amazon-cloudwatch-agent/internal/capture"
 	"github.com/aws/amazon-cloudwatch-agent/internal/publisher"
 	"github.com/aws/amazon-cloudwatch-agent/internal/retryer"
 	"github.com/aws/amazon-cloudwatch-agent/internal/util/collections"
@@ -61,17 +67,27 @@ type CloudWatch struct {
 	// 1 telegraf Metric could have many Fields.
 	// Each field corresponds to a MetricDatum.
 	metricChan             chan *aggregationDatum
-	datumBatchChan         chan map[string][]*cloudwatch.MetricDatum
-	metricDatumBatch       *MetricDatumBatch
+	datumBatchChan         chan *metricDatumRequest
+	metricDatumBatches     map[batchKey]*MetricDatumBatch
+	summaries              *summaryDeltas
+	namespaceMatchers      []namespaceMatcher
+	statisticsMatchers     []statisticsMatcher
 	shutdownChan           chan struct{}
 	retries                int
 	publisher              *publisher.Publisher
+	diskQueue              *publisher.DiskQueue
 	retryer                *retryer.LogThrottleRetryer
 	droppingOriginMetrics  collections.Set[string]
 	aggregator             Aggregator
 	aggregatorShutdownChan chan struct{}
 	aggregatorWaitGroup    sync.WaitGroup
 	lastRequestBytes       int
+	// backfillTicker paces the PutMetricData calls in backfill mode.
+	backfillTicker *time.Ticker
+	// backfillRejected counts the datapoints rejected for being too old in backfill mode.
+	backfillRejected atomic.Int64
+	// captureWriter receives the requests instead of CloudWatch in dry run mode.
+	captureWriter *capture.Writer
 }
 
 // Compile time interface check.
@@ -82,11 +98,42 @@ func (c *CloudWatch) Capabilities() consumer.Capabilities {
 }
 
 func (c *CloudWatch) Start(_ context.Context, host component.Host) error {
+	var queue publisher.Queue = publisher.NewNonBlockingFifoQueue(metricChanBufferSize)
+	if c.config.DiskQueueDirectory != "" {
+		diskQueue, err := newDiskQueue(c.config)
+		if err != nil {
+			return fmt.Errorf("unable to open the disk queue: %w", err)
+		}
+		c.diskQueue = diskQueue
+		queue = diskQueue
+	}
 	c.publisher, _ = publisher.NewPublisher(
-		publisher.NewNonBlockingFifoQueue(metricChanBufferSize),
+		queue,
 		maxConcurrentPublisher,
 		2*time.Second,
 		c.WriteToCloudWatch)
+	logger := models.NewLogger("outputs", "cloudwatch", "")
+	logThrottleRetryer := retryer.NewLogThrottleRetryer(logger)
+	if c.config.DryRunFile != "" {
+		writer, err := capture.Open(c.config.DryRunFile)
+		if err != nil {
+			return err
+		}
+		log.Printf("I! cloudwatch: dry run, the requests are written to %s instead of being sent", c.config.DryRunFile)
+		c.captureWriter = writer
+		c.svc = &captureClient{writer: writer}
+	} else {
+		c.svc = c.newService(host, logThrottleRetryer)
+	}
+	//Format unique roll up list
+	c.config.RollupDimensions = GetUniqueRollupList(c.config.RollupDimensions)
+	c.retryer = logThrottleRetryer
+	c.startRoutines()
+	return nil
+}
+
+// newService returns the CloudWatch client of the credentials and endpoint of the config.
+func (c *CloudWatch) newService(host component.Host, logThrottleRetryer *retryer.LogThrottleRetryer) cloudwatchiface.CloudWatchAPI {
 	credentialConfig := &configaws.CredentialConfig{
 		Region:    c.config.Region,
 		AccessKey: c.config.AccessKey,
@@ -96,11 +143,8 @@ func (c *CloudWatch) Start(_ context.Context, host component.Host) error {
 		Filename:  c.config.SharedCredentialFilename,
 		Token:     c.config.Token,
 	}
-	configProvider := credentialConfig.Credentials()
-	logger := models.NewLogger("outputs", "cloudwatch", "")
-	logThrottleRetryer := retryer.NewLogThrottleRetryer(logger)
 	svc := cloudwatch.New(
-		configProvider,
+		credentialConfig.Credentials(),
 		&aws.Config{
 			Endpoint: aws.String(c.config.EndpointOverride),
 			Retryer:  logThrottleRetryer,
@@ -111,23 +155,28 @@ func (c *CloudWatch) Start(_ context.Context, host component.Host) error {
 	if c.config.MiddlewareID != nil {
 		awsmiddleware.TryConfigure(c.logger, host, *c.config.MiddlewareID, awsmiddleware.SDKv1(&svc.Handlers))
 	}
-	//Format unique roll up list
-	c.config.RollupDimensions = GetUniqueRollupList(c.config.RollupDimensions)
-	c.svc = svc
-	c.retryer = logThrottleRetryer
-	c.startRoutines()
-	return nil
+	return svc
 }
 
 func (c *CloudWatch) startRoutines() {
 	setNewDistributionFunc(c.config.MaxValuesPerDatum)
 	c.metricChan = make(chan *aggregationDatum, metricChanBufferSize)
-	c.datumBatchChan = make(chan map[string][]*cloudwatch.MetricDatum, datumBatchChanBufferSize)
+	c.datumBatchChan = make(chan *metricDatumRequest, datumBatchChanBufferSize)
 	c.shutdownChan = make(chan struct{})
 	c.aggregatorShutdownChan = make(chan struct{})
 	c.aggregator = NewAggregator(c.metricChan, c.aggregatorShutdownChan, &c.aggregatorWaitGroup)
-	perRequestConstSize := overallConstPerRequestSize + len(c.config.Namespace) + namespaceOverheads
-	c.metricDatumBatch = newMetricDatumBatch(c.config.MaxDatumsPerCall, perRequestConstSize)
+	c.metricDatumBatches = map[batchKey]*MetricDatumBatch{}
+	c.summaries = newSummaryDeltas()
+	if c.config.Backfill {
+		c.backfillTicker = time.NewTicker(c.backfillInterval())
+	}
+	var err error
+	if c.namespaceMatchers, err = compileNamespaceRules(c.config.NamespaceRules); err != nil {
+		log.Printf("E! cloudwatch: ignoring the namespace rules: %v", err)
+	}
+	if c.statisticsMatchers, err = compileStatisticsRules(c.config.StatisticsRules); err != nil {
+		log.Printf("E! cloudwatch: ignoring the statistics rules: %v", err)
+	}
 	go c.pushMetricDatum()
 	go c.publish()
 }
@@ -147,7 +196,17 @@ func (c *CloudWatch) Shutdown(ctx context.Context) error {
 	}
 	close(c.shutdownChan)
 	c.publisher.Close()
+	if c.diskQueue != nil {
+		// Keep the requests not drained yet for the next start.
+		c.diskQueue.Close()
+	}
 	c.retryer.Stop()
+	if c.backfillTicker != nil {
+		c.backfillTicker.Stop()
+	}
+	if c.captureWriter != nil {
+		c.captureWriter.Close()
+	}
 	log.Println("D! Stopped the CloudWatch output plugin")
 	return nil
 }
@@ -156,7 +215,10 @@ func (c *CloudWatch) Shutdown(ctx context.Context) error {
 // The actual publishing will occur in a long running goroutine.
 // This method can block when publishing is backed up.
 func (c *CloudWatch) ConsumeMetrics(ctx context.Context, metrics pmetric.Metrics) error {
-	datums := ConvertOtelMetrics(metrics)
+	datums := ConvertOtelMetrics(metrics, c.summaries, c.config.PublishSummaryQuantiles)
+	if c.config.Backfill {
+		datums = c.rejectExpired(datums)
+	}
 	for _, d := range datums {
 		c.aggregator.AddMetric(d)
 	}
@@ -172,33 +234,57 @@ func (c *CloudWatch) pushMetricDatum() {
 	for {
 		select {
 		case metric := <-c.metricChan:
+			namespace := c.routeNamespace(metric)
 			entity, datums := c.BuildMetricDatum(metric)
 			numberOfPartitions := len(datums)
 			for i := 0; i < numberOfPartitions; i++ {
+				batch := c.batchOf(c.batchKeyOf(namespace, datums[i]))
 				entityStr := entityToString(entity)
-				c.metricDatumBatch.Partition[entityStr] = append(c.metricDatumBatch.Partition[entityStr], datums[i])
-				c.metricDatumBatch.Size += payload(datums[i])
-				c.metricDatumBatch.Count++
-				if c.metricDatumBatch.isFull() {
+				batch.Partition[entityStr] = append(batch.Partition[entityStr], datums[i])
+				batch.Size += payload(datums[i])
+				batch.Count++
+				if batch.isFull() {
 					// if batch is full
-					c.datumBatchChan <- c.metricDatumBatch.Partition
-					c.metricDatumBatch.clear()
+					c.datumBatchChan <- batch.request()
+					batch.clear()
 				}
 			}
 		case <-ticker.C:
-			if c.timeToPublish(c.metricDatumBatch) {
-				// if the time to publish comes
-				c.lastRequestBytes = c.metricDatumBatch.Size
-				c.datumBatchChan <- c.metricDatumBatch.Partition
-				c.metricDatumBatch.clear()
-			}
+			c.flushBatches()
 		case <-c.shutdownChan:
 			return
 		}
 	}
 }
 
+// flushBatches queues the batches whose time to publish has come. The batches which are still
+// empty a flush interval after they were flushed are dropped, so the namespaces the metrics are
+// no longer routed to do not accumulate.
+func (c *CloudWatch) flushBatches() {
+	for key, batch := range c.metricDatumBatches {
+		if c.timeToPublish(batch) {
+			// if the time to publish comes
+			c.lastRequestBytes = batch.Size
+			c.datumBatchChan <- batch.request()
+			batch.clear()
+			if key.bucket != 0 {
+				// The past buckets are rarely written to again.
+				delete(c.metricDatumBatches, key)
+			}
+		} else if len(batch.Partition) == 0 && time.Since(batch.BeginTime) >= c.config.ForceFlushInterval {
+			delete(c.metricDatumBatches, key)
+		}
+	}
+}
+
+// metricDatumRequest is the datums published in a single PutMetricData call, keyed by their entity.
+type metricDatumRequest struct {
+	Namespace string                               `json:"namespace"`
+	Partition map[string][]*cloudwatch.MetricDatum `json:"partition"`
+}
+
 type MetricDatumBatch struct {
+	Namespace           string
 	MaxDatumsPerCall    int
 	Partition           map[string][]*cloudwatch.MetricDatum
 	BeginTime           time.Time
@@ -218,6 +304,22 @@ func newMetricDatumBatch(maxDatumsPerCall, perRequestConstSize int) *MetricDatum
 	}
 }
 
+// batchOf returns the batch of the key, creating it if needed.
+func (c *CloudWatch) batchOf(key batchKey) *MetricDatumBatch {
+	batch, ok := c.metricDatumBatches[key]
+	if !ok {
+		perRequestConstSize := overallConstPerRequestSize + len(key.namespace) + namespaceOverheads
+		batch = newMetricDatumBatch(c.config.MaxDatumsPerCall, perRequestConstSize)
+		batch.Namespace = key.namespace
+		c.metricDatumBatches[key] = batch
+	}
+	return batch
+}
+
+func (b *MetricDatumBatch) request() *metricDatumRequest {
+	return &metricDatumRequest{Namespace: b.Namespace, Partition: b.Partition}
+}
+
 func (b *MetricDatumBatch) clear() {
 	b.Partition = map[string][]*cloudwatch.MetricDatum{}
 	b.BeginTime = time.Now()
@@ -336,6 +438,14 @@ func (c *CloudWatch) backoffSleep() {
 	log.Printf("W! cloudwatch: %v retries, going to sleep %v ms before retrying.",
 		c.retries, d.Milliseconds())
 	c.retries++
+	if c.diskQueue != nil {
+		// The failed requests are queued again on disk, so do not hold the shutdown on the backoff.
+		select {
+		case <-time.After(d):
+		case <-c.shutdownChan:
+		}
+		return
+	}
 	time.Sleep(d)
 }
 
@@ -355,7 +465,16 @@ func createEntityMetricData(entityToMetrics map[string][]*cloudwatch.MetricDatum
 }
 
 func (c *CloudWatch) WriteToCloudWatch(req interface{}) {
-	entityToMetricDatum := req.(map[string][]*cloudwatch.MetricDatum)
+	request := req.(*metricDatumRequest)
+	if c.diskQueue != nil {
+		// The request is removed from the disk queue once sent, dropped or queued again.
+		defer c.diskQueue.Ack(request)
+	}
+	entityToMetricDatum := request.Partition
+	namespace := request.Namespace
+	if namespace == "" {
+		namespace = c.config.Namespace
+	}
 
 	// PMD requires PutMetricData to have MetricData
 	metricData := entityToMetricDatum[""]
@@ -365,13 +484,17 @@ func (c *CloudWatch) WriteToCloudWatch(req interface{}) {
 
 	params := &cloudwatch.PutMetricDataInput{
 		MetricData:             metricData,
-		Namespace:              aws.String(c.config.Namespace),
+		Namespace:              aws.String(namespace),
 		EntityMetricData:       createEntityMetricData(entityToMetricDatum),
 		StrictEntityValidation: aws.Bool(false),
 	}
 
 	var err error
 	for i := 0; i < defaultRetryCount; i++ {
+		if i > 0 && c.diskQueue != nil && c.isShuttingDown() {
+			break
+		}
+		c.waitForBackfillRate()
 		_, err = c.svc.PutMetricData(params)
 		if err != nil {
 			awsErr, ok := err.(awserr.Error)
@@ -398,10 +521,48 @@ func (c *CloudWatch) WriteToCloudWatch(req interface{}) {
 		break
 	}
 	if err != nil {
+		if c.diskQueue != nil && isRetryableError(err) {
+			log.Printf("W! cloudwatch: WriteToCloudWatch failure, queueing the request on disk to retry it later, err: %v", err)
+			c.diskQueue.Enqueue(request)
+			return
+		}
 		log.Println("E! cloudwatch: WriteToCloudWatch failure, err: ", err)
 	}
 }
 
+func (c *CloudWatch) isShuttingDown() bool {
+	select {
+	case <-c.shutdownChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableError returns true for the network, throttling and service errors, which the same
+// request may not fail with later. Any other error is not retried.
+func isRetryableError(err error) bool {
+	if request.IsErrorThrottle(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	switch awsErr.Code() {
+	case request.ErrCodeRequestError, request.ErrCodeResponseTimeout, cloudwatch.ErrCodeLimitExceededFault, cloudwatch.ErrCodeInternalServiceFault:
+		return true
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() >= 500
+	}
+	return false
+}
+
 // BuildMetricDatum may just return the datum as-is.
 // Or it might expand it into many datums due to dimension aggregation.
 // There may also be more datums due to resize() on a distribution.
@@ -417,6 +578,9 @@ func (c *CloudWatch) BuildMetricDatum(metric *aggregationDatum) (cloudwatch.Enti
 		if metric.distribution.Unit() != "" {
 			metric.SetUnit(metric.distribution.Unit())
 		}
+		if statistics := c.statisticsOf(*metric.MetricName); statistics != nil {
+			return metric.entity, c.buildStatisticDatums(metric, statistics)
+		}
 		distList = resize(metric.distribution, c.config.MaxValuesPerDatum)
 	}
 
@@ -427,7 +591,16 @@ func (c *CloudWatch) BuildMetricDatum(metric *aggregationDatum) (cloudwatch.Enti
 		if index == 0 && c.IsDropping(*metric.MetricDatum.MetricName) {
 			continue
 		}
-		if len(distList) == 0 {
+		if len(distList) == 0 && metric.StatisticValues != nil {
+			datums = append(datums, &cloudwatch.MetricDatum{
+				MetricName:        metric.MetricName,
+				Dimensions:        dimensions,
+				Timestamp:         metric.Timestamp,
+				Unit:              metric.Unit,
+				StorageResolution: metric.StorageResolution,
+				StatisticValues:   metric.StatisticValues,
+			})
+		} else if len(distList) == 0 {
 			if !distribution.IsSupportedValue(*metric.Value, distribution.MinValue, distribution.MaxValue) {
 				log.Printf("E! metric (%s) has an unsupported value: %v, dropping it", *metric.MetricName, *metric.Value)
 				continue
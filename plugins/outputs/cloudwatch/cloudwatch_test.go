@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/zap"
 
 	"github.com/aws/amazon-cloudwatch-agent/internal/publisher"
@@ -213,6 +214,63 @@ func TestBuildMetricDatumDropUnsupported(t *testing.T) {
 	}
 }
 
+func TestBuildMetricDatumStatisticSet(t *testing.T) {
+	svc := new(mockCloudWatchClient)
+	cw := newCloudWatchClient(svc, time.Second)
+	cw.config.RollupDimensions = [][]string{{}}
+	s := &cloudwatch.StatisticSet{
+		Maximum:     aws.Float64(5),
+		Minimum:     aws.Float64(1),
+		SampleCount: aws.Float64(4),
+		Sum:         aws.Float64(10),
+	}
+	_, datums := cw.BuildMetricDatum(&aggregationDatum{
+		MetricDatum: cloudwatch.MetricDatum{
+			MetricName:      aws.String("test"),
+			Dimensions:      []*cloudwatch.Dimension{{Name: aws.String("host"), Value: aws.String("a")}},
+			Unit:            aws.String("Seconds"),
+			StatisticValues: s,
+		},
+	})
+	require.Len(t, datums, 2)
+	for _, datum := range datums {
+		assert.Nil(t, datum.Value)
+		assert.Equal(t, s, datum.StatisticValues)
+	}
+	assert.Empty(t, datums[1].Dimensions)
+}
+
+func TestBuildMetricDatumExponentialHistogramResize(t *testing.T) {
+	svc := new(mockCloudWatchClient)
+	cw := newCloudWatchClient(svc, time.Second)
+	cw.config.MaxValuesPerDatum = 10
+	setNewDistributionFunc(cw.config.MaxValuesPerDatum)
+	defer setNewDistributionFunc(defaultMaxValuesPerDatum)
+
+	metrics := pmetric.NewMetrics()
+	m := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("latency")
+	dp := m.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	dp.SetScale(3)
+	dp.Positive().SetOffset(-20)
+	counts := make([]uint64, 40)
+	for i := range counts {
+		counts[i] = uint64(i + 1)
+	}
+	dp.Positive().BucketCounts().FromRaw(counts)
+	aggregations := ConvertOtelMetrics(metrics, newSummaryDeltas(), false)
+	require.Len(t, aggregations, 1)
+
+	_, datums := cw.BuildMetricDatum(aggregations[0])
+	require.Greater(t, len(datums), 1)
+	var total float64
+	for _, datum := range datums {
+		assert.LessOrEqual(t, len(datum.Values), 10)
+		total += *datum.StatisticValues.SampleCount
+	}
+	assert.Equal(t, float64(40*41/2), total)
+}
+
 func TestGetUniqueRollupList(t *testing.T) {
 	testCases := map[string]struct {
 		input [][]string
@@ -581,11 +639,11 @@ func TestBackoffRetries(t *testing.T) {
 // Take 1 item out of the channel and verify it is no longer full.
 func TestCloudWatch_metricDatumBatchFull(t *testing.T) {
 	c := &CloudWatch{
-		datumBatchChan: make(chan map[string][]*cloudwatch.MetricDatum, datumBatchChanBufferSize),
+		datumBatchChan: make(chan *metricDatumRequest, datumBatchChanBufferSize),
 	}
 	assert.False(t, c.metricDatumBatchFull())
 	for i := 0; i < datumBatchChanBufferSize; i++ {
-		c.datumBatchChan <- map[string][]*cloudwatch.MetricDatum{}
+		c.datumBatchChan <- &metricDatumRequest{}
 	}
 	assert.True(t, c.metricDatumBatchFull())
 	<-c.datumBatchChan
@@ -609,7 +667,7 @@ func TestCreateEntityMetricData(t *testing.T) {
 	}
 	metrics := createTestMetrics(1, 1, 1, "s")
 	assert.Equal(t, 7, metrics.ResourceMetrics().At(0).Resource().Attributes().Len())
-	aggregations := ConvertOtelMetrics(metrics)
+	aggregations := ConvertOtelMetrics(metrics, newSummaryDeltas(), false)
 	assert.Equal(t, 0, metrics.ResourceMetrics().At(0).Resource().Attributes().Len())
 	entity, metricDatum := cw.BuildMetricDatum(aggregations[0])
 
@@ -673,7 +731,7 @@ func TestWriteToCloudWatchEntity(t *testing.T) {
 	}).Return(&cloudwatch.PutMetricDataOutput{}, nil)
 
 	cw := newCloudWatchClient(svc, time.Second)
-	cw.WriteToCloudWatch(map[string][]*cloudwatch.MetricDatum{
+	cw.WriteToCloudWatch(&metricDatumRequest{Partition: map[string][]*cloudwatch.MetricDatum{
 		"": {
 			{
 				MetricName: aws.String("TestMetricNoEntity"),
@@ -696,7 +754,7 @@ func TestWriteToCloudWatchEntity(t *testing.T) {
 				},
 			},
 		},
-	})
+	}})
 
 	assert.Equal(t, expectedPMDInput, input)
 }
@@ -27,6 +27,32 @@ type Config struct {
 	RollupDimensions         [][]string      `mapstructure:"rollup_dimensions,omitempty"`
 	DropOriginalConfigs      map[string]bool `mapstructure:"drop_original_metrics,omitempty"`
 	Namespace                string          `mapstructure:"namespace"`
+	// NamespaceRules route the metrics to other namespaces than Namespace, the first matching rule wins.
+	// The "aws:Namespace" attribute of a datapoint has precedence over the rules.
+	NamespaceRules []NamespaceRule `mapstructure:"namespace_rules,omitempty"`
+	// PublishSummaryQuantiles publishes a metric per quantile of the summaries, in addition to
+	// their statistic set.
+	PublishSummaryQuantiles bool `mapstructure:"publish_summary_quantiles,omitempty"`
+	// StatisticsRules publish statistics instead of the distributions of the matching metrics, the
+	// first matching rule wins.
+	StatisticsRules []StatisticsRule `mapstructure:"statistics_rules,omitempty"`
+	// DiskQueueDirectory enables persisting the requests which could not be published to this
+	// directory, they are retried later on and replayed after a restart.
+	DiskQueueDirectory string `mapstructure:"disk_queue_directory,omitempty"`
+	// DiskQueueMaxSizeMB bounds the size of the disk queue, the oldest requests are dropped beyond it.
+	DiskQueueMaxSizeMB int `mapstructure:"disk_queue_max_size_mb,omitempty"`
+
+	// Backfill enables publishing historical datapoints: the datums are batched by time bucket,
+	// the PutMetricData calls are rate limited and the datums older than two weeks are rejected.
+	Backfill bool `mapstructure:"backfill,omitempty"`
+	// BackfillMaxRequestsPerSecond bounds the rate of the PutMetricData calls in backfill mode.
+	BackfillMaxRequestsPerSecond float64 `mapstructure:"backfill_max_requests_per_second,omitempty"`
+	// BackfillBucket is the time interval the datums of a batch are grouped by in backfill mode.
+	BackfillBucket time.Duration `mapstructure:"backfill_bucket,omitempty"`
+
+	// DryRunFile enables the dry run mode, the PutMetricData requests are written to this file
+	// as JSON lines instead of being sent to CloudWatch.
+	DryRunFile string `mapstructure:"dry_run_file,omitempty"`
 
 	// ResourceToTelemetrySettings is the option for converting resource
 	// attributes to telemetry attributes.
@@ -50,5 +76,20 @@ func (c *Config) Validate() error {
 	if c.ForceFlushInterval < time.Millisecond {
 		return errors.New("'force_flush_interval' must be at least 1 millisecond")
 	}
+	if _, err := compileNamespaceRules(c.NamespaceRules); err != nil {
+		return err
+	}
+	if _, err := compileStatisticsRules(c.StatisticsRules); err != nil {
+		return err
+	}
+	if c.DiskQueueMaxSizeMB < 0 {
+		return errors.New("'disk_queue_max_size_mb' must not be negative")
+	}
+	if c.BackfillMaxRequestsPerSecond < 0 {
+		return errors.New("'backfill_max_requests_per_second' must not be negative")
+	}
+	if c.BackfillBucket < 0 {
+		return errors.New("'backfill_bucket' must not be negative")
+	}
 	return nil
 }
@@ -5,7 +5,10 @@ package cloudwatch
 
 import (
 	"log"
+	"math"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -18,6 +21,10 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
 )
 
+// negativeBucketsWarned holds the names of the exponential histograms the negative buckets were
+// reported for, so they are only warned about once.
+var negativeBucketsWarned sync.Map
+
 // ConvertOtelDimensions will returns a sorted list of dimensions.
 func ConvertOtelDimensions(attributes pcommon.Map) []*cloudwatch.Dimension {
 	// Loop through map, similar to EMF exporter createLabels().
@@ -144,11 +151,172 @@ func ConvertOtelHistogramDataPoints(
 	return datums
 }
 
+// ConvertOtelExponentialHistogramDataPoints converts each datapoint in the given slice to
+// Distribution. The buckets are added at their midpoint, so the statistics of the distribution
+// are approximated. Negative buckets are dropped since the distributions do not support negative
+// values, which is warned about once per metric name.
+func ConvertOtelExponentialHistogramDataPoints(
+	dataPoints pmetric.ExponentialHistogramDataPointSlice,
+	name string,
+	unit string,
+	scale float64,
+	entity cloudwatch.Entity,
+) []*aggregationDatum {
+	datums := make([]*aggregationDatum, 0, dataPoints.Len())
+	for i := 0; i < dataPoints.Len(); i++ {
+		dp := dataPoints.At(i)
+		attrs := dp.Attributes()
+		storageResolution := checkHighResolution(&attrs)
+		aggregationInterval := getAggregationInterval(&attrs)
+		dimensions := ConvertOtelDimensions(attrs)
+		ad := aggregationDatum{
+			MetricDatum: cloudwatch.MetricDatum{
+				Dimensions:        dimensions,
+				MetricName:        aws.String(name),
+				Unit:              aws.String(unit),
+				Timestamp:         aws.Time(dp.Timestamp().AsTime()),
+				StorageResolution: aws.Int64(storageResolution),
+			},
+			aggregationInterval: aggregationInterval,
+			entity:              entity,
+		}
+		ad.distribution = distribution.NewDistribution()
+		values, counts := exponentialHistogramValuesAndCounts(dp)
+		for j := range values {
+			if err := ad.distribution.AddEntryWithUnit(values[j]*scale, counts[j], unit); err != nil {
+				log.Printf("D! cloudwatch: metricname %q has %v", name, err)
+			}
+		}
+		if hasNegativeCounts(dp.Negative()) {
+			if _, warned := negativeBucketsWarned.LoadOrStore(name, true); !warned {
+				log.Printf("W! cloudwatch: metricname %q has negative buckets which are dropped, negative values are not supported", name)
+			}
+		}
+		// Nothing was recorded in the interval.
+		if ad.distribution.Size() == 0 {
+			continue
+		}
+		datums = append(datums, &ad)
+	}
+	return datums
+}
+
+// exponentialHistogramValuesAndCounts returns the midpoints of the zero and positive buckets with
+// their counts. The bucket of index i covers (base^i, base^(i+1)] with base = 2^(2^-scale).
+func exponentialHistogramValuesAndCounts(dp pmetric.ExponentialHistogramDataPoint) ([]float64, []float64) {
+	buckets := dp.Positive()
+	values := make([]float64, 0, buckets.BucketCounts().Len()+1)
+	counts := make([]float64, 0, buckets.BucketCounts().Len()+1)
+	if dp.ZeroCount() > 0 {
+		values = append(values, 0)
+		counts = append(counts, float64(dp.ZeroCount()))
+	}
+	for i := 0; i < buckets.BucketCounts().Len(); i++ {
+		count := buckets.BucketCounts().At(i)
+		if count == 0 {
+			continue
+		}
+		index := int(buckets.Offset()) + i
+		lower := math.Exp2(math.Ldexp(float64(index), -int(dp.Scale())))
+		upper := math.Exp2(math.Ldexp(float64(index+1), -int(dp.Scale())))
+		values = append(values, (lower+upper)/2)
+		counts = append(counts, float64(count))
+	}
+	return values, counts
+}
+
+func hasNegativeCounts(buckets pmetric.ExponentialHistogramDataPointBuckets) bool {
+	for i := 0; i < buckets.BucketCounts().Len(); i++ {
+		if buckets.BucketCounts().At(i) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertOtelSummaryDataPoints converts each datapoint in the given slice to a StatisticSet of the
+// count and sum since the previous datapoint of the series, and to one metric per quantile named
+// <name>_p<quantile*100> when publishQuantiles is set. The StatisticSet is skipped on the first
+// datapoint of a series and after a reset. The minimum and maximum are the 0 and 1 quantiles when
+// they are reported, widened to the average, and the average otherwise. Statistic sets are not
+// aggregated.
+func ConvertOtelSummaryDataPoints(
+	dataPoints pmetric.SummaryDataPointSlice,
+	name string,
+	unit string,
+	scale float64,
+	entity cloudwatch.Entity,
+	summaries *summaryDeltas,
+	publishQuantiles bool,
+) []*aggregationDatum {
+	datums := make([]*aggregationDatum, 0, dataPoints.Len())
+	entityStr := entityToString(entity)
+	for i := 0; i < dataPoints.Len(); i++ {
+		dp := dataPoints.At(i)
+		attrs := dp.Attributes()
+		storageResolution := checkHighResolution(&attrs)
+		getAggregationInterval(&attrs)
+		dimensions := ConvertOtelDimensions(attrs)
+		timestamp := dp.Timestamp().AsTime()
+		quantiles := dp.QuantileValues()
+
+		if count, sum, ok := summaries.delta(name, entityStr, dp); ok && count > 0 {
+			sum *= scale
+			average := sum / float64(count)
+			minimum, maximum := average, average
+			for j := 0; j < quantiles.Len(); j++ {
+				q := quantiles.At(j)
+				switch q.Quantile() {
+				case 0:
+					minimum = math.Min(q.Value()*scale, average)
+				case 1:
+					maximum = math.Max(q.Value()*scale, average)
+				}
+			}
+			s := cloudwatch.StatisticSet{}
+			s.SetMaximum(maximum)
+			s.SetMinimum(minimum)
+			s.SetSampleCount(float64(count))
+			s.SetSum(sum)
+			datums = append(datums, &aggregationDatum{
+				MetricDatum: cloudwatch.MetricDatum{
+					Dimensions:        dimensions,
+					MetricName:        aws.String(name),
+					Unit:              aws.String(unit),
+					Timestamp:         aws.Time(timestamp),
+					StorageResolution: aws.Int64(storageResolution),
+					StatisticValues:   &s,
+				},
+				entity: entity,
+			})
+		}
+
+		if !publishQuantiles || dp.Count() == 0 {
+			continue
+		}
+		for j := 0; j < quantiles.Len(); j++ {
+			q := quantiles.At(j)
+			datums = append(datums, &aggregationDatum{
+				MetricDatum: cloudwatch.MetricDatum{
+					Dimensions:        dimensions,
+					MetricName:        aws.String(name + "_p" + strconv.FormatFloat(q.Quantile()*100, 'f', -1, 64)),
+					Unit:              aws.String(unit),
+					Timestamp:         aws.Time(timestamp),
+					Value:             aws.Float64(q.Value() * scale),
+					StorageResolution: aws.Int64(storageResolution),
+				},
+				entity: entity,
+			})
+		}
+	}
+	return datums
+}
+
 // ConvertOtelMetric creates a list of datums from the datapoints in the given
 // metric and returns it. Only supports the metric DataTypes that we plan to use.
 // Intentionally not caching previous values and converting cumulative to delta.
 // Instead use cumulativetodeltaprocessor which supports monotonic cumulative sums.
-func ConvertOtelMetric(m pmetric.Metric, entity cloudwatch.Entity) []*aggregationDatum {
+func ConvertOtelMetric(m pmetric.Metric, entity cloudwatch.Entity, summaries *summaryDeltas, publishSummaryQuantiles bool) []*aggregationDatum {
 	name := m.Name()
 	unit, scale, err := cloudwatchutil.ToStandardUnit(m.Unit())
 	if err != nil {
@@ -161,13 +329,17 @@ func ConvertOtelMetric(m pmetric.Metric, entity cloudwatch.Entity) []*aggregatio
 		return ConvertOtelNumberDataPoints(m.Sum().DataPoints(), name, unit, scale, entity)
 	case pmetric.MetricTypeHistogram:
 		return ConvertOtelHistogramDataPoints(m.Histogram().DataPoints(), name, unit, scale, entity)
+	case pmetric.MetricTypeExponentialHistogram:
+		return ConvertOtelExponentialHistogramDataPoints(m.ExponentialHistogram().DataPoints(), name, unit, scale, entity)
+	case pmetric.MetricTypeSummary:
+		return ConvertOtelSummaryDataPoints(m.Summary().DataPoints(), name, unit, scale, entity, summaries, publishSummaryQuantiles)
 	default:
 		log.Printf("E! cloudwatch: Unsupported type, %s", m.Type())
 	}
 	return []*aggregationDatum{}
 }
 
-func ConvertOtelMetrics(m pmetric.Metrics) []*aggregationDatum {
+func ConvertOtelMetrics(m pmetric.Metrics, summaries *summaryDeltas, publishSummaryQuantiles bool) []*aggregationDatum {
 	datums := make([]*aggregationDatum, 0, m.DataPointCount())
 	for i := 0; i < m.ResourceMetrics().Len(); i++ {
 		entity := entityattributes.CreateCloudWatchEntityFromAttributes(m.ResourceMetrics().At(i).Resource().Attributes())
@@ -176,7 +348,7 @@ func ConvertOtelMetrics(m pmetric.Metrics) []*aggregationDatum {
 			metrics := scopeMetrics.At(j).Metrics()
 			for k := 0; k < metrics.Len(); k++ {
 				metric := metrics.At(k)
-				newDatums := ConvertOtelMetric(metric, entity)
+				newDatums := ConvertOtelMetric(metric, entity, summaries, publishSummaryQuantiles)
 				datums = append(datums, newDatums...)
 
 			}
@@ -4,6 +4,7 @@
 package cloudwatch
 
 import (
+	"math"
 	"strconv"
 	"strings"
 	"testing"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 
@@ -168,7 +170,7 @@ func checkDatum(
 func TestConvertOtelMetrics_NoDimensions(t *testing.T) {
 	for i := 0; i < 100; i++ {
 		metrics := createTestMetrics(i, i, 0, "Bytes")
-		datums := ConvertOtelMetrics(metrics)
+		datums := ConvertOtelMetrics(metrics, newSummaryDeltas(), false)
 		// Expect nummetrics * numDatapointsPerMetric
 		assert.Equal(t, i*i, len(datums))
 
@@ -189,7 +191,7 @@ func TestConvertOtelMetrics_Histogram(t *testing.T) {
 			distribution.NewDistribution = regular.NewRegularDistribution
 		}
 		metrics := createTestHistogram(i, i, 0, "Bytes")
-		datums := ConvertOtelMetrics(metrics)
+		datums := ConvertOtelMetrics(metrics, newSummaryDeltas(), false)
 		// Expect nummetrics * numDatapointsPerMetric
 		assert.Equal(t, i*i, len(datums))
 
@@ -205,7 +207,7 @@ func TestConvertOtelMetrics_Dimensions(t *testing.T) {
 	for i := 0; i < 100; i++ {
 		// 1 data point per metric, but vary the number dimensions.
 		metrics := createTestMetrics(i, 1, i, "s")
-		datums := ConvertOtelMetrics(metrics)
+		datums := ConvertOtelMetrics(metrics, newSummaryDeltas(), false)
 		// Expect nummetrics * numDatapointsPerMetric
 		assert.Equal(t, i, len(datums))
 
@@ -223,7 +225,7 @@ func TestConvertOtelMetrics_Dimensions(t *testing.T) {
 
 func TestConvertOtelMetrics_Entity(t *testing.T) {
 	metrics := createTestMetrics(1, 1, 1, "s")
-	datums := ConvertOtelMetrics(metrics)
+	datums := ConvertOtelMetrics(metrics, newSummaryDeltas(), false)
 	expectedEntity := cloudwatch.Entity{
 		KeyAttributes: map[string]*string{
 			"Type":         aws.String("Service"),
@@ -246,5 +248,127 @@ func TestInvalidMetric(t *testing.T) {
 	m := pmetric.NewMetric()
 	m.SetName("name")
 	m.SetUnit("unit")
-	assert.Empty(t, ConvertOtelMetric(m, cloudwatch.Entity{}))
+	assert.Empty(t, ConvertOtelMetric(m, cloudwatch.Entity{}, newSummaryDeltas(), false))
+}
+
+func TestConvertOtelMetrics_ExponentialHistogram(t *testing.T) {
+	distribution.NewDistribution = regular.NewRegularDistribution
+	metrics := pmetric.NewMetrics()
+	m := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("latency")
+	m.SetUnit("ms")
+	dp := m.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	dp.Attributes().PutStr("service", "checkout")
+	// Scale 0 has a base of 2, the buckets are (1, 2], (2, 4] and (4, 8].
+	dp.SetScale(0)
+	dp.SetZeroCount(1)
+	dp.Positive().SetOffset(0)
+	dp.Positive().BucketCounts().FromRaw([]uint64{2, 0, 3})
+	dp.Negative().BucketCounts().FromRaw([]uint64{4})
+	dp.SetCount(10)
+	dp.SetSum(25)
+	empty := m.ExponentialHistogram().DataPoints().AppendEmpty()
+	empty.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+
+	datums := ConvertOtelMetrics(metrics, newSummaryDeltas(), false)
+	require.Len(t, datums, 1, "data points without any count should be skipped")
+	d := datums[0]
+	assert.Equal(t, "latency", *d.MetricName)
+	assert.Equal(t, "Milliseconds", *d.Unit)
+	assert.Equal(t, []*cloudwatch.Dimension{{Name: aws.String("service"), Value: aws.String("checkout")}}, d.Dimensions)
+	require.NotNil(t, d.distribution)
+	values, counts := d.distribution.ValuesAndCounts()
+	got := map[float64]float64{}
+	for i := range values {
+		got[values[i]] = counts[i]
+	}
+	assert.Equal(t, map[float64]float64{0: 1, 1.5: 2, 6: 3}, got)
+	assert.Equal(t, float64(6), d.distribution.SampleCount())
+	_, warned := negativeBucketsWarned.Load("latency")
+	assert.True(t, warned, "the dropped negative buckets should be warned about")
+}
+
+func TestExponentialHistogramValuesAndCounts(t *testing.T) {
+	dp := pmetric.NewExponentialHistogramDataPoint()
+	// Scale 1 has a base of sqrt(2), the bucket of index -2 is (0.5, 0.707].
+	dp.SetScale(1)
+	dp.Positive().SetOffset(-2)
+	dp.Positive().BucketCounts().FromRaw([]uint64{1, 1})
+	values, counts := exponentialHistogramValuesAndCounts(dp)
+	require.Len(t, values, 2)
+	assert.InDelta(t, (0.5+math.Sqrt2/2)/2, values[0], 1e-9)
+	assert.InDelta(t, (math.Sqrt2/2+1)/2, values[1], 1e-9)
+	assert.Equal(t, []float64{1, 1}, counts)
+}
+
+func TestConvertOtelMetrics_Summary(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	createSummary := func(count uint64, sum float64, quantiles map[float64]float64) pmetric.Metrics {
+		metrics := pmetric.NewMetrics()
+		m := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		m.SetName("latency")
+		m.SetUnit("s")
+		dp := m.SetEmptySummary().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+		dp.Attributes().PutStr(aggregationIntervalTagKey, "60s")
+		dp.SetCount(count)
+		dp.SetSum(sum)
+		for q, v := range quantiles {
+			qv := dp.QuantileValues().AppendEmpty()
+			qv.SetQuantile(q)
+			qv.SetValue(v)
+		}
+		return metrics
+	}
+	summaries := newSummaryDeltas()
+
+	assert.Empty(t, ConvertOtelMetrics(createSummary(4, 10, map[float64]float64{0: 1, 1: 5}), summaries, false), "the first cumulative datapoint should be dropped")
+
+	datums := ConvertOtelMetrics(createSummary(8, 30, map[float64]float64{0.5: 2, 0.99: 4.5, 0: 1, 1: 6}), summaries, false)
+	require.Len(t, datums, 1)
+	d := datums[0]
+	assert.Equal(t, "latency", *d.MetricName)
+	assert.Equal(t, "Seconds", *d.Unit)
+	assert.Nil(t, d.Value)
+	assert.Nil(t, d.distribution)
+	assert.Empty(t, d.Dimensions)
+	assert.Zero(t, d.aggregationInterval, "statistic sets should not be aggregated")
+	assert.Equal(t, &cloudwatch.StatisticSet{
+		Maximum:     aws.Float64(6),
+		Minimum:     aws.Float64(1),
+		SampleCount: aws.Float64(4),
+		Sum:         aws.Float64(20),
+	}, d.StatisticValues, "only the delta since the previous datapoint should be published")
+
+	datums = ConvertOtelMetrics(createSummary(10, 50, map[float64]float64{0.5: 2, 0.99: 4.5}), summaries, true)
+	require.Len(t, datums, 3)
+	assert.Equal(t, &cloudwatch.StatisticSet{
+		Maximum:     aws.Float64(10),
+		Minimum:     aws.Float64(10),
+		SampleCount: aws.Float64(2),
+		Sum:         aws.Float64(20),
+	}, datums[0].StatisticValues, "the minimum and maximum should be the average without the 0 and 1 quantiles")
+	quantiles := map[string]float64{}
+	for _, d := range datums[1:] {
+		quantiles[*d.MetricName] = *d.Value
+	}
+	assert.Equal(t, map[string]float64{"latency_p50": 2, "latency_p99": 4.5}, quantiles)
+
+	assert.Empty(t, ConvertOtelMetrics(createSummary(3, 5, nil), summaries, false), "the datapoint after a reset should be dropped")
+	datums = ConvertOtelMetrics(createSummary(5, 9, nil), summaries, false)
+	require.Len(t, datums, 1)
+	assert.Equal(t, float64(2), *datums[0].StatisticValues.SampleCount)
+	assert.Equal(t, float64(4), *datums[0].StatisticValues.Sum)
+}
+
+func TestSummaryDeltasPrune(t *testing.T) {
+	summaries := newSummaryDeltas()
+	dp := pmetric.NewSummaryDataPoint()
+	dp.SetCount(1)
+	_, _, ok := summaries.delta("latency", "", dp)
+	assert.False(t, ok)
+	summaries.prune(time.Now().Add(summaryStaleness))
+	assert.Empty(t, summaries.previous, "the stale series should be dropped")
 }
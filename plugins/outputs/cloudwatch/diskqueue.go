@@ -0,0 +1,55 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/publisher"
+)
+
+const defaultDiskQueueMaxSizeMB = 100
+
+// requestCodec persists the requests of the publisher.
+type requestCodec struct{}
+
+var _ publisher.DiskQueueCodec = requestCodec{}
+
+func (requestCodec) Marshal(req interface{}) ([]byte, error) {
+	return json.Marshal(req)
+}
+
+func (requestCodec) Unmarshal(data []byte) (interface{}, error) {
+	var req metricDatumRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// Timestamp returns the timestamp of the oldest datum, since CloudWatch rejects the whole request
+// once any of its datums is too old.
+func (requestCodec) Timestamp(req interface{}) time.Time {
+	var oldest time.Time
+	for _, datums := range req.(*metricDatumRequest).Partition {
+		for _, datum := range datums {
+			if datum.Timestamp != nil && (oldest.IsZero() || datum.Timestamp.Before(oldest)) {
+				oldest = *datum.Timestamp
+			}
+		}
+	}
+	if oldest.IsZero() {
+		return time.Now()
+	}
+	return oldest
+}
+
+func newDiskQueue(config *Config) (*publisher.DiskQueue, error) {
+	maxSizeMB := config.DiskQueueMaxSizeMB
+	if maxSizeMB == 0 {
+		maxSizeMB = defaultDiskQueueMaxSizeMB
+	}
+	return publisher.NewDiskQueue(config.DiskQueueDirectory, int64(maxSizeMB)*1024*1024, maxDatumAge, requestCodec{})
+}
@@ -0,0 +1,108 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+)
+
+func TestRequestCodec(t *testing.T) {
+	oldest := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	req := &metricDatumRequest{
+		Namespace: "namespace",
+		Partition: map[string][]*cloudwatch.MetricDatum{
+			"": {
+				{MetricName: aws.String("a"), Value: aws.Float64(1), Timestamp: aws.Time(oldest.Add(time.Minute))},
+			},
+			"entity": {
+				{MetricName: aws.String("b"), Values: aws.Float64Slice([]float64{1, 2}), Counts: aws.Float64Slice([]float64{3, 4}), Timestamp: aws.Time(oldest)},
+			},
+		},
+	}
+	codec := requestCodec{}
+	data, err := codec.Marshal(req)
+	require.NoError(t, err)
+	got, err := codec.Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, req, got)
+	assert.Equal(t, oldest, codec.Timestamp(got))
+}
+
+func TestWriteToCloudWatchDiskQueue(t *testing.T) {
+	req := &metricDatumRequest{
+		Namespace: "namespace",
+		Partition: map[string][]*cloudwatch.MetricDatum{
+			"": {{MetricName: aws.String("a"), Value: aws.Float64(1), Timestamp: aws.Time(time.Now().UTC().Truncate(time.Second))}},
+		},
+	}
+	testCases := map[string]struct {
+		err        error
+		wantQueued bool
+	}{
+		"WithConnectionError": {
+			err:        awserr.New(request.ErrCodeRequestError, "send request failed", nil),
+			wantQueued: true,
+		},
+		"WithNetworkError": {
+			err:        &net.OpError{Op: "dial", Err: errors.New("no such host")},
+			wantQueued: true,
+		},
+		"WithThrottlingError": {
+			err:        awserr.NewRequestFailure(awserr.New("Throttling", "", nil), 400, ""),
+			wantQueued: true,
+		},
+		"WithServerError": {
+			err:        awserr.NewRequestFailure(awserr.New("ServiceUnavailable", "", nil), 503, ""),
+			wantQueued: true,
+		},
+		"WithSuccess": {},
+		"WithInvalidRequest": {
+			err: awserr.NewRequestFailure(awserr.New(cloudwatch.ErrCodeInvalidParameterValueException, "", nil), 400, ""),
+		},
+		"WithUnknownError": {
+			err: errors.New("unknown"),
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			svc := new(mockCloudWatchClient)
+			svc.On("PutMetricData", mock.Anything).Return(&cloudwatch.PutMetricDataOutput{}, testCase.err)
+			cw := newCloudWatchClient(svc, time.Second)
+			cw.config.DiskQueueDirectory = t.TempDir()
+			var err error
+			cw.diskQueue, err = newDiskQueue(cw.config)
+			require.NoError(t, err)
+
+			cw.diskQueue.Enqueue(req)
+			dequeued, ok := cw.diskQueue.Dequeue()
+			require.True(t, ok)
+
+			cw.WriteToCloudWatch(dequeued)
+			files, err := os.ReadDir(cw.config.DiskQueueDirectory)
+			require.NoError(t, err)
+			if !testCase.wantQueued {
+				assert.Equal(t, 0, cw.diskQueue.Len())
+				assert.Empty(t, files, "the sent or dropped request should be removed from the disk")
+				return
+			}
+			assert.Len(t, files, 1)
+			got, ok := cw.diskQueue.Dequeue()
+			require.True(t, ok)
+			assert.Equal(t, req, got)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"fmt"
+
+	"github.com/gobwas/glob"
+
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+)
+
+// namespaceTagKey is the special attribute overriding the namespace a datapoint is published to.
+const namespaceTagKey = "aws:Namespace"
+
+// NamespaceRule routes the metrics matching all of its patterns to Namespace.
+type NamespaceRule struct {
+	Namespace string `mapstructure:"namespace"`
+	// MetricName is a glob pattern the metric name has to match.
+	MetricName string `mapstructure:"metric_name,omitempty"`
+	// Dimensions are glob patterns the values of the dimensions have to match, keyed by the dimension name.
+	Dimensions map[string]string `mapstructure:"dimensions,omitempty"`
+}
+
+type namespaceMatcher struct {
+	namespace  string
+	metricName glob.Glob
+	dimensions map[string]glob.Glob
+}
+
+func compileNamespaceRules(rules []NamespaceRule) ([]namespaceMatcher, error) {
+	matchers := make([]namespaceMatcher, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Namespace == "" {
+			return nil, fmt.Errorf("namespace rule %d has no namespace", i)
+		}
+		if rule.MetricName == "" && len(rule.Dimensions) == 0 {
+			return nil, fmt.Errorf("namespace rule %d matches no metric name nor dimension", i)
+		}
+		m := namespaceMatcher{namespace: rule.Namespace, dimensions: make(map[string]glob.Glob, len(rule.Dimensions))}
+		var err error
+		if rule.MetricName != "" {
+			if m.metricName, err = glob.Compile(rule.MetricName); err != nil {
+				return nil, fmt.Errorf("namespace rule %d has an invalid metric name pattern: %w", i, err)
+			}
+		}
+		for name, pattern := range rule.Dimensions {
+			if m.dimensions[name], err = glob.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("namespace rule %d has an invalid pattern for dimension %s: %w", i, name, err)
+			}
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+func (m *namespaceMatcher) matches(datum *aggregationDatum) bool {
+	if m.metricName != nil && (datum.MetricName == nil || !m.metricName.Match(*datum.MetricName)) {
+		return false
+	}
+	for name, pattern := range m.dimensions {
+		value, ok := dimensionValue(datum.Dimensions, name)
+		if !ok || !pattern.Match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func dimensionValue(dimensions []*cloudwatch.Dimension, name string) (string, bool) {
+	for _, d := range dimensions {
+		if d.Name != nil && d.Value != nil && *d.Name == name {
+			return *d.Value, true
+		}
+	}
+	return "", false
+}
+
+// routeNamespace removes the namespace dimension of the datum and returns the namespace it is published
+// to. The namespace dimension has precedence over the rules, which are evaluated in order, and the
+// configured namespace is used when nothing matches.
+func (c *CloudWatch) routeNamespace(datum *aggregationDatum) string {
+	for i, d := range datum.Dimensions {
+		if d.Name != nil && *d.Name == namespaceTagKey {
+			datum.Dimensions = append(datum.Dimensions[:i:i], datum.Dimensions[i+1:]...)
+			if d.Value != nil && *d.Value != "" {
+				return *d.Value
+			}
+			break
+		}
+	}
+	for i := range c.namespaceMatchers {
+		if c.namespaceMatchers[i].matches(datum) {
+			return c.namespaceMatchers[i].namespace
+		}
+	}
+	return c.config.Namespace
+}
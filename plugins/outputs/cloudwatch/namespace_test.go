@@ -0,0 +1,160 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/publisher"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+)
+
+func newTestDatum(name string, dimensions map[string]string) *aggregationDatum {
+	return &aggregationDatum{
+		MetricDatum: cloudwatch.MetricDatum{
+			MetricName: aws.String(name),
+			Dimensions: BuildDimensions(dimensions),
+			Timestamp:  aws.Time(time.Now()),
+			Value:      aws.Float64(1),
+		},
+	}
+}
+
+func TestRouteNamespace(t *testing.T) {
+	cw := &CloudWatch{config: &Config{Namespace: "CWAgent"}}
+	var err error
+	cw.namespaceMatchers, err = compileNamespaceRules([]NamespaceRule{
+		{Namespace: "TeamA", MetricName: "team_a_*"},
+		{Namespace: "TeamB", Dimensions: map[string]string{"service": "checkout-*", "env": "prod"}},
+		{Namespace: "Fallback", MetricName: "team_*"},
+	})
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		datum          *aggregationDatum
+		want           string
+		wantDimensions int
+	}{
+		"WithMetricName": {
+			datum: newTestDatum("team_a_latency", nil),
+			want:  "TeamA",
+		},
+		"WithDimensions": {
+			datum:          newTestDatum("latency", map[string]string{"service": "checkout-api", "env": "prod"}),
+			want:           "TeamB",
+			wantDimensions: 2,
+		},
+		"WithPartialDimensions": {
+			datum:          newTestDatum("latency", map[string]string{"service": "checkout-api"}),
+			want:           "CWAgent",
+			wantDimensions: 1,
+		},
+		"WithRuleOrder": {
+			datum:          newTestDatum("team_c_latency", map[string]string{"service": "checkout-api", "env": "prod"}),
+			want:           "TeamB",
+			wantDimensions: 2,
+		},
+		"WithAttribute": {
+			datum:          newTestDatum("team_a_latency", map[string]string{namespaceTagKey: "Override", "env": "prod"}),
+			want:           "Override",
+			wantDimensions: 1,
+		},
+		"WithEmptyAttribute": {
+			datum: newTestDatum("team_a_latency", map[string]string{namespaceTagKey: ""}),
+			want:  "TeamA",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, cw.routeNamespace(testCase.datum))
+			assert.Len(t, testCase.datum.Dimensions, testCase.wantDimensions)
+			_, ok := dimensionValue(testCase.datum.Dimensions, namespaceTagKey)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCompileNamespaceRulesErrors(t *testing.T) {
+	for name, rule := range map[string]NamespaceRule{
+		"WithoutNamespace":      {MetricName: "a"},
+		"WithoutPatterns":       {Namespace: "a"},
+		"WithInvalidMetricName": {Namespace: "a", MetricName: "[a"},
+		"WithInvalidDimension":  {Namespace: "a", Dimensions: map[string]string{"d": "[a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := compileNamespaceRules([]NamespaceRule{rule})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPublishPerNamespace(t *testing.T) {
+	var mu sync.Mutex
+	namespaces := map[string][]string{}
+	svc := new(mockCloudWatchClient)
+	svc.On("PutMetricData", mock.Anything).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*cloudwatch.PutMetricDataInput)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, datum := range input.MetricData {
+			namespaces[*input.Namespace] = append(namespaces[*input.Namespace], *datum.MetricName)
+		}
+	}).Return(&cloudwatch.PutMetricDataOutput{}, nil)
+	cw := &CloudWatch{
+		svc: svc,
+		config: &Config{
+			Namespace:          "CWAgent",
+			NamespaceRules:     []NamespaceRule{{Namespace: "TeamA", MetricName: "team_a_*"}},
+			ForceFlushInterval: time.Second,
+			MaxDatumsPerCall:   defaultMaxDatumsPerCall,
+			MaxValuesPerDatum:  defaultMaxValuesPerDatum,
+		},
+	}
+	cw.startRoutines()
+	cw.publisher, _ = publisher.NewPublisher(publisher.NewNonBlockingFifoQueue(10), 10, 2*time.Second, cw.WriteToCloudWatch)
+
+	cw.aggregator.AddMetric(newTestDatum("team_a_latency", nil))
+	cw.aggregator.AddMetric(newTestDatum("cpu", nil))
+	cw.aggregator.AddMetric(newTestDatum("memory", map[string]string{namespaceTagKey: "TeamB"}))
+	time.Sleep(2*time.Second + 2*cw.config.ForceFlushInterval)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string][]string{
+		"TeamA":   {"team_a_latency"},
+		"CWAgent": {"cpu"},
+		"TeamB":   {"memory"},
+	}, namespaces)
+	svc.AssertNumberOfCalls(t, "PutMetricData", 3)
+}
+
+func TestFlushBatchesDropsEmptyNamespaces(t *testing.T) {
+	cw := &CloudWatch{
+		config:             &Config{ForceFlushInterval: time.Minute, MaxDatumsPerCall: defaultMaxDatumsPerCall},
+		datumBatchChan:     make(chan *metricDatumRequest, 10),
+		metricDatumBatches: map[batchKey]*MetricDatumBatch{},
+	}
+	teamA := cw.batchOf(batchKey{namespace: "TeamA"})
+	teamA.Partition[""] = []*cloudwatch.MetricDatum{&newTestDatum("team_a_latency", nil).MetricDatum}
+	teamA.BeginTime = time.Now().Add(-2 * time.Minute)
+	cw.batchOf(batchKey{namespace: "TeamB"})
+
+	cw.flushBatches()
+	require.Len(t, cw.datumBatchChan, 1)
+	assert.Equal(t, "TeamA", (<-cw.datumBatchChan).Namespace)
+	assert.Len(t, cw.metricDatumBatches, 2, "the batches should be kept until a flush interval passes without datums")
+
+	teamA.BeginTime = time.Now().Add(-2 * time.Minute)
+	cw.flushBatches()
+	assert.Len(t, cw.metricDatumBatches, 1)
+	assert.Contains(t, cw.metricDatumBatches, batchKey{namespace: "TeamB"})
+	assert.Empty(t, cw.datumBatchChan)
+}
@@ -0,0 +1,130 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/gobwas/glob"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+)
+
+// StatisticsRule publishes statistics computed from the distributions of the metrics matching MetricName,
+// as separate metrics named <metric>_<statistic>, instead of the distributions.
+type StatisticsRule struct {
+	// MetricName is a glob pattern the metric name has to match.
+	MetricName string `mapstructure:"metric_name"`
+	// Statistics are min, max, sum, avg, count, or pNN for a percentile, e.g. p99 or p99.9.
+	Statistics []string `mapstructure:"statistics"`
+}
+
+type statistic struct {
+	name       string
+	percentile float64
+}
+
+type statisticsMatcher struct {
+	metricName glob.Glob
+	statistics []statistic
+}
+
+func parseStatistic(s string) (statistic, error) {
+	switch s {
+	case "min", "max", "sum", "avg", "count":
+		return statistic{name: s}, nil
+	}
+	if p, ok := strings.CutPrefix(s, "p"); ok {
+		percentile, err := strconv.ParseFloat(p, 64)
+		if err == nil && percentile >= 0 && percentile <= 100 {
+			return statistic{name: s, percentile: percentile}, nil
+		}
+	}
+	return statistic{}, fmt.Errorf("unsupported statistic %q", s)
+}
+
+func compileStatisticsRules(rules []StatisticsRule) ([]statisticsMatcher, error) {
+	matchers := make([]statisticsMatcher, 0, len(rules))
+	for i, rule := range rules {
+		if len(rule.Statistics) == 0 {
+			return nil, fmt.Errorf("statistics rule %d has no statistic", i)
+		}
+		metricName, err := glob.Compile(rule.MetricName)
+		if err != nil {
+			return nil, fmt.Errorf("statistics rule %d has an invalid metric name pattern: %w", i, err)
+		}
+		m := statisticsMatcher{metricName: metricName}
+		for _, s := range rule.Statistics {
+			stat, err := parseStatistic(s)
+			if err != nil {
+				return nil, fmt.Errorf("statistics rule %d: %w", i, err)
+			}
+			m.statistics = append(m.statistics, stat)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+func (s statistic) value(d distribution.Distribution) float64 {
+	switch s.name {
+	case "min":
+		return d.Minimum()
+	case "max":
+		return d.Maximum()
+	case "sum":
+		return d.Sum()
+	case "avg":
+		return d.Sum() / d.SampleCount()
+	case "count":
+		return d.SampleCount()
+	}
+	return distribution.Percentile(d, s.percentile)
+}
+
+// statisticsOf returns the statistics published instead of the distributions of the metric, the first
+// matching rule wins.
+func (c *CloudWatch) statisticsOf(metricName string) []statistic {
+	for _, m := range c.statisticsMatchers {
+		if m.metricName.Match(metricName) {
+			return m.statistics
+		}
+	}
+	return nil
+}
+
+// buildStatisticDatums builds a datum per statistic of the distribution and rollup of the metric.
+func (c *CloudWatch) buildStatisticDatums(metric *aggregationDatum, statistics []statistic) []*cloudwatch.MetricDatum {
+	var datums []*cloudwatch.MetricDatum
+	for index, dimensions := range c.ProcessRollup(metric.Dimensions) {
+		if index == 0 && c.IsDropping(*metric.MetricName) {
+			continue
+		}
+		for _, stat := range statistics {
+			value := stat.value(metric.distribution)
+			if !distribution.IsSupportedValue(value, distribution.MinValue, distribution.MaxValue) {
+				log.Printf("E! metric (%s) has an unsupported %s: %v, dropping it", *metric.MetricName, stat.name, value)
+				continue
+			}
+			unit := metric.Unit
+			if stat.name == "count" {
+				unit = aws.String(cloudwatch.StandardUnitCount)
+			}
+			datums = append(datums, &cloudwatch.MetricDatum{
+				MetricName:        aws.String(*metric.MetricName + "_" + stat.name),
+				Dimensions:        dimensions,
+				Timestamp:         metric.Timestamp,
+				Unit:              unit,
+				StorageResolution: metric.StorageResolution,
+				Value:             aws.Float64(value),
+			})
+		}
+	}
+	return datums
+}
@@ -0,0 +1,80 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/metric/distribution"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatch"
+)
+
+func TestCompileStatisticsRulesErrors(t *testing.T) {
+	for name, rule := range map[string]StatisticsRule{
+		"WithoutStatistics":     {MetricName: "a"},
+		"WithUnknownStatistic":  {MetricName: "a", Statistics: []string{"median"}},
+		"WithInvalidPercentile": {MetricName: "a", Statistics: []string{"p101"}},
+		"WithInvalidMetricName": {MetricName: "[a", Statistics: []string{"max"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := compileStatisticsRules([]StatisticsRule{rule})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBuildMetricDatumStatistics(t *testing.T) {
+	svc := new(mockCloudWatchClient)
+	cw := newCloudWatchClient(svc, time.Second)
+	cw.config.RollupDimensions = [][]string{{}}
+	var err error
+	cw.statisticsMatchers, err = compileStatisticsRules([]StatisticsRule{
+		{MetricName: "latency*", Statistics: []string{"p50", "p99.9", "max", "avg", "count"}},
+	})
+	require.NoError(t, err)
+
+	newDatum := func(name string) *aggregationDatum {
+		dist := distribution.NewDistribution()
+		for i := 1; i <= 100; i++ {
+			require.NoError(t, dist.AddEntryWithUnit(float64(i), 1, "Milliseconds"))
+		}
+		return &aggregationDatum{
+			MetricDatum: cloudwatch.MetricDatum{
+				MetricName: aws.String(name),
+				Dimensions: []*cloudwatch.Dimension{{Name: aws.String("host"), Value: aws.String("a")}},
+				Timestamp:  aws.Time(time.Now()),
+			},
+			distribution: dist,
+		}
+	}
+
+	_, datums := cw.BuildMetricDatum(newDatum("latency_api"))
+	require.Len(t, datums, 10, "one datum per statistic and rollup")
+	got := map[string]float64{}
+	for _, datum := range datums[:5] {
+		assert.Nil(t, datum.Values)
+		assert.Nil(t, datum.StatisticValues)
+		assert.Len(t, datum.Dimensions, 1)
+		got[*datum.MetricName] = *datum.Value
+	}
+	assert.Equal(t, map[string]float64{
+		"latency_api_p50":   50,
+		"latency_api_p99.9": 100,
+		"latency_api_max":   100,
+		"latency_api_avg":   50.5,
+		"latency_api_count": 100,
+	}, got)
+	assert.Equal(t, "Milliseconds", *datums[0].Unit)
+	assert.Equal(t, cloudwatch.StandardUnitCount, *datums[4].Unit)
+	assert.Empty(t, datums[5].Dimensions)
+
+	_, datums = cw.BuildMetricDatum(newDatum("requests"))
+	require.Len(t, datums, 2, "metrics without a rule should keep their distribution")
+	assert.NotNil(t, datums[0].Values)
+}
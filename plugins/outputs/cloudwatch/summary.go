@@ -0,0 +1,82 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// summaryStaleness is how long the totals of a summary series are kept without a new datapoint.
+const summaryStaleness = 10 * time.Minute
+
+type summaryTotals struct {
+	count    uint64
+	sum      float64
+	lastSeen time.Time
+}
+
+// summaryDeltas converts the cumulative count and sum of the summary datapoints to the count and
+// sum since the previous datapoint of the same series, since the summaries are always cumulative and
+// the cumulativetodelta processor does not handle them.
+type summaryDeltas struct {
+	mu        sync.Mutex
+	previous  map[string]summaryTotals
+	lastPrune time.Time
+}
+
+func newSummaryDeltas() *summaryDeltas {
+	return &summaryDeltas{previous: map[string]summaryTotals{}, lastPrune: time.Now()}
+}
+
+// delta returns the count and sum since the previous datapoint of the series. It returns false on the
+// first datapoint of the series and when the count went down, i.e. the series was reset.
+func (s *summaryDeltas) delta(name string, entity string, dp pmetric.SummaryDataPoint) (uint64, float64, bool) {
+	key := summarySeriesKey(name, entity, dp)
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	previous, ok := s.previous[key]
+	s.previous[key] = summaryTotals{count: dp.Count(), sum: dp.Sum(), lastSeen: now}
+	if !ok || dp.Count() < previous.count {
+		return 0, 0, false
+	}
+	return dp.Count() - previous.count, dp.Sum() - previous.sum, true
+}
+
+// prune drops the series which have not been seen for summaryStaleness.
+func (s *summaryDeltas) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < summaryStaleness {
+		return
+	}
+	s.lastPrune = now
+	for key, totals := range s.previous {
+		if now.Sub(totals.lastSeen) >= summaryStaleness {
+			delete(s.previous, key)
+		}
+	}
+}
+
+// summarySeriesKey identifies the series of the datapoint by the metric name, the entity, the
+// attributes and the start timestamp.
+func summarySeriesKey(name string, entity string, dp pmetric.SummaryDataPoint) string {
+	attributes := dp.Attributes().AsRaw()
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\x00%s\x00%d", name, entity, dp.StartTimestamp())
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\x00%s=%v", k, attributes[k])
+	}
+	return b.String()
+}
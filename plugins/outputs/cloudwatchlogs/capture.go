@@ -0,0 +1,44 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatchlogs
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/internal/capture"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatchlogs"
+)
+
+// captureClient writes the requests of the pusher to the dry run file instead of sending them.
+type captureClient struct {
+	writer *capture.Writer
+}
+
+var _ CloudWatchLogsService = (*captureClient)(nil)
+
+func (c *captureClient) PutLogEvents(input *cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	if err := c.writer.Write("PutLogEvents", input); err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.PutLogEventsOutput{}, nil
+}
+
+func (c *captureClient) CreateLogStream(input *cloudwatchlogs.CreateLogStreamInput) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	if err := c.writer.Write("CreateLogStream", input); err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func (c *captureClient) CreateLogGroup(input *cloudwatchlogs.CreateLogGroupInput) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	if err := c.writer.Write("CreateLogGroup", input); err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.CreateLogGroupOutput{}, nil
+}
+
+func (c *captureClient) PutRetentionPolicy(input *cloudwatchlogs.PutRetentionPolicyInput) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+	if err := c.writer.Write("PutRetentionPolicy", input); err != nil {
+		return nil, err
+	}
+	return &cloudwatchlogs.PutRetentionPolicyOutput{}, nil
+}
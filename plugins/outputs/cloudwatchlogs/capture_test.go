@@ -0,0 +1,51 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatchlogs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal"
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+func TestDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	c := &CloudWatchLogs{
+		Region:             "us-east-1",
+		DryRunFile:         path,
+		ForceFlushInterval: internal.Duration{Duration: 10 * time.Millisecond},
+		Log:                models.NewLogger("outputs", "cloudwatchlogs", ""),
+		cwDests:            make(map[Target]*cwDest),
+		pusherStopChan:     make(chan struct{}),
+	}
+	require.NoError(t, c.Init())
+	d := c.CreateDest("G", "S", 7, "", nil)
+	now := time.UnixMilli(time.Now().UnixMilli())
+	d.Publish([]logs.LogEvent{evtMock{"message", now, nil}})
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(path)
+		return err == nil && strings.Contains(string(data), "PutLogEvents")
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var putLogEvents string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.Contains(line, `"operation":"PutLogEvents"`) {
+			putLogEvents = line
+		}
+	}
+	assert.JSONEq(t, fmt.Sprintf(`{"operation":"PutLogEvents","request":{"Entity":null,"SequenceToken":null,"LogGroupName":"G","LogStreamName":"S","LogEvents":[{"Message":"message","Timestamp":%d}]}}`, now.UnixMilli()), putLogEvents)
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/extension/agenthealth/handler/useragent"
 	"github.com/aws/amazon-cloudwatch-agent/handlers"
 	"github.com/aws/amazon-cloudwatch-agent/internal"
+	"github.com/aws/amazon-cloudwatch-agent/internal/capture"
+	"github.com/aws/amazon-cloudwatch-agent/internal/redact"
 	"github.com/aws/amazon-cloudwatch-agent/internal/retryer"
 	"github.com/aws/amazon-cloudwatch-agent/logs"
 	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatchlogs"
@@ -74,12 +76,40 @@ type CloudWatchLogs struct {
 
 	ForceFlushInterval internal.Duration `toml:"force_flush_interval"` // unit is second
 
+	// Disk spool for log events which could not be delivered, disabled when the directory is empty
+	SpoolDirectory string `toml:"spool_directory"`
+	SpoolMaxSizeMB int    `toml:"spool_max_size_mb"` // per log stream
+
+	// Redactions applied to every log event before it is pushed, including structured logs and EMF
+	Redactions       []*redact.Rule `toml:"redactions"`
+	RedactionHashKey string         `toml:"redaction_hash_key"`
+
+	// Dry run file the requests are written to as JSON lines instead of being sent, disabled when empty
+	DryRunFile string `toml:"dry_run_file"`
+
 	Log telegraf.Logger `toml:"-"`
 
 	pusherStopChan  chan struct{}
 	pusherWaitGroup sync.WaitGroup
 	cwDests         map[Target]*cwDest
+	spools          map[string]*spool
+	redactor        *redact.Redactor
 	middleware      awsmiddleware.Middleware
+	captureWriter   *capture.Writer
+}
+
+func (c *CloudWatchLogs) Init() error {
+	var err error
+	if c.redactor, err = redact.New(c.Redactions, c.RedactionHashKey); err != nil {
+		return err
+	}
+	if c.DryRunFile != "" {
+		if c.captureWriter, err = capture.Open(c.DryRunFile); err != nil {
+			return err
+		}
+		c.Log.Infof("Dry run, the requests are written to %s instead of being sent", c.DryRunFile)
+	}
+	return nil
 }
 
 func (c *CloudWatchLogs) Connect() error {
@@ -93,6 +123,9 @@ func (c *CloudWatchLogs) Close() error {
 	for _, d := range c.cwDests {
 		d.Stop()
 	}
+	if c.captureWriter != nil {
+		return c.captureWriter.Close()
+	}
 
 	return nil
 }
@@ -128,7 +161,6 @@ func (c *CloudWatchLogs) getDest(t Target, logSrc logs.LogSrc) *cwDest {
 	if cwd, ok := c.cwDests[t]; ok {
 		return cwd
 	}
-
 	credentialConfig := &configaws.CredentialConfig{
 		Region:    c.Region,
 		AccessKey: c.AccessKey,
@@ -162,12 +194,40 @@ func (c *CloudWatchLogs) getDest(t Target, logSrc logs.LogSrc) *cwDest {
 			c.Log.Info("Configured middleware on AWS client")
 		}
 	}
-	pusher := NewPusher(c.Region, t, client, c.ForceFlushInterval.Duration, maxRetryTimeout, c.Log, c.pusherStopChan, &c.pusherWaitGroup, logSrc)
-	cwd := &cwDest{pusher: pusher, retryer: logThrottleRetryer}
+	var service CloudWatchLogsService = client
+	if c.captureWriter != nil {
+		// Dry run: the requests are written to the file instead of being sent.
+		service = &captureClient{writer: c.captureWriter}
+	}
+	pusher := NewPusher(c.Region, t, service, c.ForceFlushInterval.Duration, maxRetryTimeout, c.Log, c.pusherStopChan, &c.pusherWaitGroup, logSrc, c.getSpool(t))
+	cwd := &cwDest{pusher: pusher, retryer: logThrottleRetryer, redactor: c.redactor}
 	c.cwDests[t] = cwd
 	return cwd
 }
 
+// getSpool returns the spool for the target's group and stream. Targets which only differ
+// in retention or class share the same spool.
+func (c *CloudWatchLogs) getSpool(t Target) *spool {
+	if c.SpoolDirectory == "" {
+		return nil
+	}
+	dir := spoolDirectory(c.SpoolDirectory, t.Group, t.Stream)
+	if s, ok := c.spools[dir]; ok {
+		return s
+	}
+	maxSizeMB := c.SpoolMaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultSpoolMaxSizeMB
+	}
+	s, err := newSpool(dir, int64(maxSizeMB)*1024*1024, c.Log)
+	if err != nil {
+		c.Log.Errorf("Unable to create log spool for %v/%v in %v, undeliverable events will be dropped: %v", t.Group, t.Stream, dir, err)
+		return nil
+	}
+	c.spools[dir] = s
+	return s
+}
+
 func (c *CloudWatchLogs) writeMetricAsStructuredLog(m telegraf.Metric) {
 	t, err := c.getTargetFromMetric(m)
 	if err != nil {
@@ -301,9 +361,10 @@ func (e *structuredLogEvent) Done() {}
 type cwDest struct {
 	*pusher
 	sync.Mutex
-	isEMF   bool
-	stopped bool
-	retryer *retryer.LogThrottleRetryer
+	isEMF    bool
+	stopped  bool
+	retryer  *retryer.LogThrottleRetryer
+	redactor *redact.Redactor
 }
 
 func (cd *cwDest) Publish(events []logs.LogEvent) error {
@@ -328,6 +389,11 @@ func (cd *cwDest) Stop() {
 }
 
 func (cd *cwDest) AddEvent(e logs.LogEvent) {
+	if cd.redactor != nil {
+		if e = cd.redact(e); e == nil {
+			return
+		}
+	}
 	// Drop events for metric path logs when queue is full
 	if cd.isEMF {
 		cd.pusher.AddEventNonBlocking(e)
@@ -336,6 +402,24 @@ func (cd *cwDest) AddEvent(e logs.LogEvent) {
 	}
 }
 
+// redact returns the event with the secrets removed from its message, or nil when the event
+// is dropped. Dropped events are acknowledged so that the source does not resend them.
+func (cd *cwDest) redact(e logs.LogEvent) logs.LogEvent {
+	result := cd.redactor.Redact(e.Message())
+	for name, count := range result.Counts {
+		cd.addStats("redacted_"+name, float64(count))
+	}
+	if result.Drop {
+		cd.addStats("redactionDropped", 1)
+		e.Done()
+		return nil
+	}
+	if result.Counts == nil {
+		return e
+	}
+	return &redactedLogEvent{LogEvent: e, msg: result.Message}
+}
+
 func (cd *cwDest) switchToEMF() {
 	cd.Lock()
 	defer cd.Unlock()
@@ -355,6 +439,15 @@ func (cd *cwDest) setRetryer(r request.Retryer) {
 	}
 }
 
+type redactedLogEvent struct {
+	logs.LogEvent
+	msg string
+}
+
+func (e *redactedLogEvent) Message() string {
+	return e.msg
+}
+
 type Target struct {
 	Group, Stream, Class string
 	Retention            int
@@ -386,6 +479,25 @@ var sampleConfig = `
 
   # The log stream name.
   log_stream_name = "<log_stream_name>"
+
+  ## Directory to spool log events to when they cannot be delivered, spooled
+  ## events are replayed in order once CloudWatch Logs is reachable again.
+  #spool_directory = ""
+  ## Max size of the spool per log stream, the oldest events are evicted first.
+  #spool_max_size_mb = 100
+
+  ## Redact secrets from the log events, including structured logs and EMF. The built-in
+  ## detectors are credit_card, aws_access_key, aws_secret_key, email and bearer_token,
+  ## custom ones are defined by a name and an expression. Matches are masked by default,
+  ## hashed with HMAC-SHA256 and redaction_hash_key, or the whole event is dropped.
+  #redaction_hash_key = ""
+  #[[outputs.cloudwatchlogs.redactions]]
+  #  name = "credit_card"
+  #  action = "mask"
+  #[[outputs.cloudwatchlogs.redactions]]
+  #  name = "session_id"
+  #  expression = "session=(?P<secret>\\w+)"
+  #  action = "hash"
 `
 
 // SampleConfig returns the default configuration of the Output
@@ -399,6 +511,7 @@ func init() {
 			ForceFlushInterval: internal.Duration{Duration: defaultFlushTimeout},
 			pusherStopChan:     make(chan struct{}),
 			cwDests:            make(map[Target]*cwDest),
+			spools:             make(map[string]*spool),
 			middleware: agenthealth.NewAgentHealth(
 				zap.NewNop(),
 				&agenthealth.Config{
@@ -4,6 +4,8 @@
 package cloudwatchlogs
 
 import (
+	"errors"
+	"fmt"
 	"math/rand"
 	"sort"
 	"sync"
@@ -23,10 +25,15 @@ const (
 	reqEventsLimit              = 10000
 	warnOldTimeStamp            = 1 * 24 * time.Hour
 	warnOldTimeStampLogInterval = 1 * 5 * time.Minute
+
+	// spoolRetryDuration is how long a batch is retried in memory before it is spooled to disk
+	spoolRetryDuration = 1 * time.Minute
 )
 
 var (
 	seededRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	errUnretryable = errors.New("request will not be retried")
 )
 
 type CloudWatchLogsService interface {
@@ -59,13 +66,16 @@ type pusher struct {
 	needSort            bool
 	stop                <-chan struct{}
 	lastSentTime        time.Time
+	spool               *spool
+	nextReplay          time.Time
+	replayRetryCount    int
 
 	initNonBlockingChOnce sync.Once
 	startNonBlockCh       chan struct{}
 	wg                    *sync.WaitGroup
 }
 
-func NewPusher(region string, target Target, service CloudWatchLogsService, flushTimeout time.Duration, retryDuration time.Duration, logger telegraf.Logger, stop <-chan struct{}, wg *sync.WaitGroup, logSrc logs.LogSrc) *pusher {
+func NewPusher(region string, target Target, service CloudWatchLogsService, flushTimeout time.Duration, retryDuration time.Duration, logger telegraf.Logger, stop <-chan struct{}, wg *sync.WaitGroup, logSrc logs.LogSrc, spool *spool) *pusher {
 	p := &pusher{
 		Target:          target,
 		Service:         service,
@@ -80,6 +90,7 @@ func NewPusher(region string, target Target, service CloudWatchLogsService, flus
 		stop:            stop,
 		startNonBlockCh: make(chan struct{}),
 		wg:              wg,
+		spool:           spool,
 	}
 	p.putRetentionPolicy()
 	p.wg.Add(1)
@@ -122,14 +133,12 @@ func hasValidTime(e logs.LogEvent) bool {
 	//http://docs.aws.amazon.com/goto/SdkForGoV1/logs-2014-03-28/PutLogEvents
 	//* None of the log events in the batch can be more than 2 hours in the future.
 	//* None of the log events in the batch can be older than 14 days or the retention period of the log group.
-	if !e.Time().IsZero() {
-		now := time.Now()
-		dt := now.Sub(e.Time()).Hours()
-		if dt > 24*14 || dt < -2 {
-			return false
-		}
-	}
-	return true
+	return e.Time().IsZero() || isValidTime(e.Time())
+}
+
+func isValidTime(t time.Time) bool {
+	dt := time.Since(t).Hours()
+	return dt <= 24*14 && dt >= -2
 }
 
 func (p *pusher) start() {
@@ -191,6 +200,9 @@ func (p *pusher) start() {
 			if time.Since(p.lastSentTime) >= p.FlushTimeout && len(p.events) > 0 {
 				p.send()
 			} else {
+				if p.spool != nil && len(p.events) == 0 {
+					p.replaySpool()
+				}
 				p.resetFlushTimer()
 			}
 		case <-p.stop:
@@ -222,39 +234,21 @@ func (p *pusher) send() {
 	if p.needSort {
 		sort.Stable(ByTimestamp(p.events))
 	}
-	input := &cloudwatchlogs.PutLogEventsInput{
-		LogEvents:     p.events,
-		LogGroupName:  &p.Group,
-		LogStreamName: &p.Stream,
-		SequenceToken: p.sequenceToken,
-	}
-	if p.logSrc != nil {
-		input.Entity = p.logSrc.Entity()
+
+	// Spooled batches are older than the current one, so they need to go out first.
+	if p.spool != nil && !p.replaySpool() {
+		p.spoolEvents()
+		return
 	}
 
+	input := p.newPutLogEventsInput(p.events)
 	startTime := time.Now()
 
 	retryCountShort := 0
 	retryCountLong := 0
 	for {
-		input.SequenceToken = p.sequenceToken
-		output, err := p.Service.PutLogEvents(input)
+		err := p.putLogEvents(input)
 		if err == nil {
-			if output.NextSequenceToken != nil {
-				p.sequenceToken = output.NextSequenceToken
-			}
-			if output.RejectedLogEventsInfo != nil {
-				info := output.RejectedLogEventsInfo
-				if info.TooOldLogEventEndIndex != nil {
-					p.Log.Warnf("%d log events for log '%s/%s' are too old", *info.TooOldLogEventEndIndex, p.Group, p.Stream)
-				}
-				if info.TooNewLogEventStartIndex != nil {
-					p.Log.Warnf("%d log events for log '%s/%s' are too new", *info.TooNewLogEventStartIndex, p.Group, p.Stream)
-				}
-				if info.ExpiredLogEventEndIndex != nil {
-					p.Log.Warnf("%d log events for log '%s/%s' are expired", *info.ExpiredLogEventEndIndex, p.Group, p.Stream)
-				}
-			}
 			for i := len(p.doneCallbacks) - 1; i >= 0; i-- {
 				done := p.doneCallbacks[i]
 				done()
@@ -269,41 +263,12 @@ func (p *pusher) send() {
 			return
 		}
 
-		awsErr, ok := err.(awserr.Error)
-		if !ok {
-			p.Log.Errorf("Non aws error received when sending logs to %v/%v: %v. CloudWatch agent will not retry and logs will be missing!", p.Group, p.Stream, err)
+		if errors.Is(err, errUnretryable) {
 			// Messages will be discarded but done callbacks not called
 			p.reset()
 			return
 		}
 
-		switch e := awsErr.(type) {
-		case *cloudwatchlogs.ResourceNotFoundException:
-			err := p.createLogGroupAndStream()
-			if err != nil {
-				p.Log.Errorf("Unable to create log stream %v/%v: %v", p.Group, p.Stream, e.Message())
-				break
-			}
-			p.putRetentionPolicy()
-		case *cloudwatchlogs.InvalidSequenceTokenException:
-			if p.sequenceToken == nil {
-				p.Log.Infof("First time sending logs to %v/%v since startup so sequenceToken is nil, learned new token:(%v): %v", p.Group, p.Stream, e.ExpectedSequenceToken, e.Message())
-			} else {
-				p.Log.Warnf("Invalid SequenceToken used (%v) while sending logs to %v/%v, will use new token and retry: %v", p.sequenceToken, p.Group, p.Stream, e.Message())
-			}
-			if e.ExpectedSequenceToken == nil {
-				p.Log.Errorf("Failed to find sequence token from aws response while sending logs to %v/%v: %v", p.Group, p.Stream, e.Message())
-			}
-			p.sequenceToken = e.ExpectedSequenceToken
-		case *cloudwatchlogs.InvalidParameterException,
-			*cloudwatchlogs.DataAlreadyAcceptedException:
-			p.Log.Errorf("%v, will not retry the request", e)
-			p.reset()
-			return
-		default:
-			p.Log.Errorf("Aws error received when sending logs to %v/%v: %v", p.Group, p.Stream, awsErr)
-		}
-
 		// retry wait strategy depends on the type of error returned
 		var wait time.Duration
 		if chooseRetryWaitStrategy(err) == retryLong {
@@ -314,6 +279,12 @@ func (p *pusher) send() {
 			retryCountShort++
 		}
 
+		if p.spool != nil && time.Since(startTime)+wait > spoolRetryDuration {
+			p.Log.Warnf("%v retries to %v/%v failed for PutLogEvents, spooling request to disk.", retryCountShort+retryCountLong-1, p.Group, p.Stream)
+			p.spoolEvents()
+			return
+		}
+
 		if time.Since(startTime)+wait > p.RetryDuration {
 			p.Log.Errorf("All %v retries to %v/%v failed for PutLogEvents, request dropped.", retryCountShort+retryCountLong-1, p.Group, p.Stream)
 			p.reset()
@@ -324,6 +295,11 @@ func (p *pusher) send() {
 
 		select {
 		case <-p.stop:
+			if p.spool != nil {
+				p.Log.Warnf("Stop requested after %v retries to %v/%v failed for PutLogEvents, spooling request to disk.", retryCountShort+retryCountLong-1, p.Group, p.Stream)
+				p.spoolEvents()
+				return
+			}
 			p.Log.Errorf("Stop requested after %v retries to %v/%v failed for PutLogEvents, request dropped.", retryCountShort+retryCountLong-1, p.Group, p.Stream)
 			p.reset()
 			return
@@ -334,6 +310,153 @@ func (p *pusher) send() {
 
 }
 
+func (p *pusher) newPutLogEventsInput(events []*cloudwatchlogs.InputLogEvent) *cloudwatchlogs.PutLogEventsInput {
+	input := &cloudwatchlogs.PutLogEventsInput{
+		LogEvents:     events,
+		LogGroupName:  &p.Group,
+		LogStreamName: &p.Stream,
+		SequenceToken: p.sequenceToken,
+	}
+	if p.logSrc != nil {
+		input.Entity = p.logSrc.Entity()
+	}
+	return input
+}
+
+// putLogEvents makes a single PutLogEvents attempt and handles the side effects of the
+// response. Errors wrapping errUnretryable mean the request will never be accepted.
+func (p *pusher) putLogEvents(input *cloudwatchlogs.PutLogEventsInput) error {
+	input.SequenceToken = p.sequenceToken
+	output, err := p.Service.PutLogEvents(input)
+	if err == nil {
+		if output.NextSequenceToken != nil {
+			p.sequenceToken = output.NextSequenceToken
+		}
+		if output.RejectedLogEventsInfo != nil {
+			info := output.RejectedLogEventsInfo
+			if info.TooOldLogEventEndIndex != nil {
+				p.Log.Warnf("%d log events for log '%s/%s' are too old", *info.TooOldLogEventEndIndex, p.Group, p.Stream)
+			}
+			if info.TooNewLogEventStartIndex != nil {
+				p.Log.Warnf("%d log events for log '%s/%s' are too new", *info.TooNewLogEventStartIndex, p.Group, p.Stream)
+			}
+			if info.ExpiredLogEventEndIndex != nil {
+				p.Log.Warnf("%d log events for log '%s/%s' are expired", *info.ExpiredLogEventEndIndex, p.Group, p.Stream)
+			}
+		}
+		return nil
+	}
+
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		p.Log.Errorf("Non aws error received when sending logs to %v/%v: %v. CloudWatch agent will not retry and logs will be missing!", p.Group, p.Stream, err)
+		return fmt.Errorf("%w: %v", errUnretryable, err)
+	}
+
+	switch e := awsErr.(type) {
+	case *cloudwatchlogs.ResourceNotFoundException:
+		err := p.createLogGroupAndStream()
+		if err != nil {
+			p.Log.Errorf("Unable to create log stream %v/%v: %v", p.Group, p.Stream, e.Message())
+			break
+		}
+		p.putRetentionPolicy()
+	case *cloudwatchlogs.InvalidSequenceTokenException:
+		if p.sequenceToken == nil {
+			p.Log.Infof("First time sending logs to %v/%v since startup so sequenceToken is nil, learned new token:(%v): %v", p.Group, p.Stream, e.ExpectedSequenceToken, e.Message())
+		} else {
+			p.Log.Warnf("Invalid SequenceToken used (%v) while sending logs to %v/%v, will use new token and retry: %v", p.sequenceToken, p.Group, p.Stream, e.Message())
+		}
+		if e.ExpectedSequenceToken == nil {
+			p.Log.Errorf("Failed to find sequence token from aws response while sending logs to %v/%v: %v", p.Group, p.Stream, e.Message())
+		}
+		p.sequenceToken = e.ExpectedSequenceToken
+	case *cloudwatchlogs.InvalidParameterException,
+		*cloudwatchlogs.DataAlreadyAcceptedException:
+		p.Log.Errorf("%v, will not retry the request", e)
+		return fmt.Errorf("%w: %v", errUnretryable, err)
+	default:
+		p.Log.Errorf("Aws error received when sending logs to %v/%v: %v", p.Group, p.Stream, awsErr)
+	}
+	return err
+}
+
+// spoolEvents persists the current batch to the spool. Once the batch is on disk it is
+// considered delivered as far as the log source is concerned.
+func (p *pusher) spoolEvents() {
+	defer p.reset()
+	evicted, err := p.spool.Write(p.events)
+	if evicted > 0 {
+		p.Log.Warnf("Log spool for %v/%v is full, evicted %d oldest batches", p.Group, p.Stream, evicted)
+		p.addStats("spoolEvicted", float64(evicted))
+	}
+	if err != nil {
+		p.Log.Errorf("Unable to spool %v log events for %v/%v, request dropped: %v", len(p.events), p.Group, p.Stream, err)
+		return
+	}
+	for i := len(p.doneCallbacks) - 1; i >= 0; i-- {
+		done := p.doneCallbacks[i]
+		done()
+	}
+	p.addStats("spooledSize", float64(p.bufferredSize))
+}
+
+// replaySpool sends spooled batches oldest first and reports whether the spool has been
+// drained. Each batch gets a single attempt; on failure the replay backs off and resumes
+// on a later flush.
+func (p *pusher) replaySpool() bool {
+	if p.spool.Len() == 0 {
+		return true
+	}
+	if time.Now().Before(p.nextReplay) {
+		return false
+	}
+	err := p.spool.Replay(func(events []*cloudwatchlogs.InputLogEvent) error {
+		total := len(events)
+		events = validSpooledEvents(events)
+		if len(events) < total {
+			p.Log.Warnf("Discarding %d spooled log events for %v/%v which are out of accepted time range", total-len(events), p.Group, p.Stream)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		err := p.putLogEvents(p.newPutLogEventsInput(events))
+		if errors.Is(err, errUnretryable) {
+			return nil
+		}
+		if err == nil {
+			p.Log.Debugf("Pusher replayed %v spooled log events to group: %v stream: %v", len(events), p.Group, p.Stream)
+			p.addStats("spoolReplayed", float64(len(events)))
+			p.lastSentTime = time.Now()
+		}
+		return err
+	})
+	if err == nil {
+		p.replayRetryCount = 0
+		return true
+	}
+	var wait time.Duration
+	if chooseRetryWaitStrategy(err) == retryLong {
+		wait = retryWaitLong(p.replayRetryCount)
+	} else {
+		wait = retryWaitShort(p.replayRetryCount)
+	}
+	p.replayRetryCount++
+	p.nextReplay = time.Now().Add(wait)
+	return false
+}
+
+// validSpooledEvents filters out spooled events which CloudWatch Logs no longer accepts.
+func validSpooledEvents(events []*cloudwatchlogs.InputLogEvent) []*cloudwatchlogs.InputLogEvent {
+	valid := events[:0]
+	for _, e := range events {
+		if isValidTime(time.UnixMilli(*e.Timestamp)) {
+			valid = append(valid, e)
+		}
+	}
+	return valid
+}
+
 func (p *pusher) createLogGroupAndStream() error {
 	_, err := p.Service.CreateLogStream(&cloudwatchlogs.CreateLogStreamInput{
 		LogGroupName:  &p.Group,
@@ -0,0 +1,182 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatchlogs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/seqfile"
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatchlogs"
+)
+
+const (
+	spoolBatchSuffix = ".batch"
+
+	defaultSpoolMaxSizeMB = 100
+)
+
+var errSpoolBatchTooLarge = errors.New("batch is larger than the spool size limit")
+
+type spooledEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+// spool is a disk-backed queue of PutLogEvents batches for a single log group and stream.
+// Each batch is persisted in its own file so that it survives agent restarts, and batches
+// are replayed in the order they were written. When the size limit is reached the oldest
+// batches are evicted to make room for new ones.
+type spool struct {
+	sync.Mutex
+	// replayMu serializes the replays of the pushers sharing the spool, so that a batch is
+	// only sent once, without blocking the writes while a batch is sent.
+	replayMu sync.Mutex
+	dir      string
+	files    *seqfile.Dir
+	maxSize  int64
+	log      telegraf.Logger
+	entries  []seqfile.Entry
+	size     int64
+}
+
+// spoolDirectory returns the directory holding the spooled batches for the group/stream
+// underneath the root spool directory.
+func spoolDirectory(root string, group string, stream string) string {
+	return filepath.Join(root, url.QueryEscape(group), url.QueryEscape(stream))
+}
+
+func newSpool(dir string, maxSize int64, log telegraf.Logger) (*spool, error) {
+	files, entries, err := seqfile.Open(dir, spoolBatchSuffix, func(name string) {
+		log.Warnf("Ignoring unexpected file %v in log spool %v", name, dir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s := &spool{dir: dir, files: files, maxSize: maxSize, log: log, entries: entries}
+	for _, entry := range entries {
+		s.size += entry.Size
+	}
+	if len(s.entries) > 0 {
+		log.Infof("Found %d spooled batches (%d bytes) in %v", len(s.entries), s.size, dir)
+	}
+	return s, nil
+}
+
+// Len returns the number of batches currently held in the spool.
+func (s *spool) Len() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.entries)
+}
+
+// Write persists the batch to disk, evicting the oldest batches when the size limit would
+// be exceeded. It returns the number of batches that were evicted.
+func (s *spool) Write(events []*cloudwatchlogs.InputLogEvent) (int, error) {
+	spooled := make([]spooledEvent, 0, len(events))
+	for _, e := range events {
+		spooled = append(spooled, spooledEvent{Timestamp: *e.Timestamp, Message: *e.Message})
+	}
+	data, err := json.Marshal(spooled)
+	if err != nil {
+		return 0, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	size := int64(len(data))
+	if size > s.maxSize {
+		return 0, errSpoolBatchTooLarge
+	}
+	evicted := 0
+	for len(s.entries) > 0 && s.size+size > s.maxSize {
+		if err := s.removeOldest(); err != nil {
+			return evicted, err
+		}
+		evicted++
+	}
+
+	entry, err := s.files.Write(data)
+	if err != nil {
+		return evicted, err
+	}
+	s.entries = append(s.entries, entry)
+	s.size += entry.Size
+	return evicted, nil
+}
+
+// Replay calls fn with each batch spooled when the replay starts, oldest first, and removes
+// the batch once fn returns nil. Replay stops at the first batch fn fails on and returns that
+// error, so the batch and everything after it is kept for the next attempt. fn is called without
+// holding the lock, so batches can be written meanwhile.
+func (s *spool) Replay(fn func(events []*cloudwatchlogs.InputLogEvent) error) error {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	s.Lock()
+	entries := append([]seqfile.Entry(nil), s.entries...)
+	s.Unlock()
+	for _, entry := range entries {
+		events, err := s.read(entry)
+		if os.IsNotExist(err) {
+			// evicted by a write since the replay started
+			continue
+		}
+		if err != nil {
+			s.log.Errorf("Unable to read spooled batch %v, discarding it: %v", s.files.Path(entry.Seq), err)
+		} else if err = fn(events); err != nil {
+			return err
+		}
+		if err = s.remove(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove removes the replayed batch unless it has been evicted meanwhile.
+func (s *spool) remove(entry seqfile.Entry) error {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.entries) == 0 || s.entries[0].Seq != entry.Seq {
+		return nil
+	}
+	return s.removeOldest()
+}
+
+func (s *spool) read(entry seqfile.Entry) ([]*cloudwatchlogs.InputLogEvent, error) {
+	data, err := s.files.Read(entry)
+	if err != nil {
+		return nil, err
+	}
+	var spooled []spooledEvent
+	if err = json.Unmarshal(data, &spooled); err != nil {
+		return nil, err
+	}
+	events := make([]*cloudwatchlogs.InputLogEvent, 0, len(spooled))
+	for i := range spooled {
+		events = append(events, &cloudwatchlogs.InputLogEvent{
+			Message:   &spooled[i].Message,
+			Timestamp: &spooled[i].Timestamp,
+		})
+	}
+	return events, nil
+}
+
+func (s *spool) removeOldest() error {
+	oldest := s.entries[0]
+	if err := s.files.Remove(oldest); err != nil {
+		return fmt.Errorf("unable to remove spooled batch: %w", err)
+	}
+	s.entries = s.entries[1:]
+	s.size -= oldest.Size
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cloudwatchlogs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/influxdata/telegraf/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/sdk/service/cloudwatchlogs"
+)
+
+func testSpoolEvents(msgs ...string) []*cloudwatchlogs.InputLogEvent {
+	var events []*cloudwatchlogs.InputLogEvent
+	for i, msg := range msgs {
+		events = append(events, &cloudwatchlogs.InputLogEvent{
+			Message:   aws.String(msg),
+			Timestamp: aws.Int64(int64(1000 + i)),
+		})
+	}
+	return events
+}
+
+func collectSpool(t *testing.T, s *spool) [][]string {
+	var batches [][]string
+	require.NoError(t, s.Replay(func(events []*cloudwatchlogs.InputLogEvent) error {
+		var msgs []string
+		for _, e := range events {
+			msgs = append(msgs, *e.Message)
+		}
+		batches = append(batches, msgs)
+		return nil
+	}))
+	return batches
+}
+
+func TestSpoolReplayInOrderAfterRestart(t *testing.T) {
+	dir := spoolDirectory(t.TempDir(), "/aws/group", "stream:1")
+	logger := models.NewLogger("cloudwatchlogs", "test", "")
+
+	s, err := newSpool(dir, 1024*1024, logger)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = s.Write(testSpoolEvents(fmt.Sprintf("batch%d-a", i), fmt.Sprintf("batch%d-b", i)))
+		require.NoError(t, err)
+	}
+	// leftover of an interrupted write must be ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000009.batch.tmp"), []byte("["), 0600))
+
+	s, err = newSpool(dir, 1024*1024, logger)
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, [][]string{
+		{"batch0-a", "batch0-b"},
+		{"batch1-a", "batch1-b"},
+		{"batch2-a", "batch2-b"},
+	}, collectSpool(t, s))
+	assert.Equal(t, 0, s.Len())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpoolReplayStopsAtFailure(t *testing.T) {
+	s, err := newSpool(t.TempDir(), 1024*1024, models.NewLogger("cloudwatchlogs", "test", ""))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = s.Write(testSpoolEvents(fmt.Sprintf("msg%d", i)))
+		require.NoError(t, err)
+	}
+
+	calls := 0
+	err = s.Replay(func(events []*cloudwatchlogs.InputLogEvent) error {
+		calls++
+		if calls == 2 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, [][]string{{"msg1"}, {"msg2"}}, collectSpool(t, s))
+}
+
+func TestSpoolWriteDuringReplay(t *testing.T) {
+	s, err := newSpool(t.TempDir(), 1024*1024, models.NewLogger("cloudwatchlogs", "test", ""))
+	require.NoError(t, err)
+	_, err = s.Write(testSpoolEvents("msg0"))
+	require.NoError(t, err)
+
+	calls := 0
+	require.NoError(t, s.Replay(func(events []*cloudwatchlogs.InputLogEvent) error {
+		calls++
+		// the spool is not locked while the batch is sent
+		_, err := s.Write(testSpoolEvents("msg1"))
+		return err
+	}))
+	assert.Equal(t, 1, calls, "the batches written during the replay are left for the next one")
+	assert.Equal(t, [][]string{{"msg1"}}, collectSpool(t, s))
+}
+
+func TestSpoolEvictsOldest(t *testing.T) {
+	s, err := newSpool(t.TempDir(), 100, models.NewLogger("cloudwatchlogs", "test", ""))
+	require.NoError(t, err)
+
+	// each batch is 37 bytes on disk
+	evicted, err := s.Write(testSpoolEvents("msg0"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, evicted)
+	evicted, err = s.Write(testSpoolEvents("msg1"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, evicted)
+	evicted, err = s.Write(testSpoolEvents("msg2"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, evicted)
+	assert.Equal(t, [][]string{{"msg1"}, {"msg2"}}, collectSpool(t, s))
+
+	_, err = s.Write(testSpoolEvents("msg0", "msg1", "msg2"))
+	assert.ErrorIs(t, err, errSpoolBatchTooLarge)
+}
+
+func TestSpoolDiscardsCorruptedBatch(t *testing.T) {
+	dir := t.TempDir()
+	logger := models.NewLogger("cloudwatchlogs", "test", "")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000000.batch"), []byte("not json"), 0600))
+
+	s, err := newSpool(dir, 1024*1024, logger)
+	require.NoError(t, err)
+	_, err = s.Write(testSpoolEvents("msg"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"msg"}}, collectSpool(t, s))
+}
+
+func TestPusherSpoolsAndReplays(t *testing.T) {
+	var s svcMock
+	available := make(chan struct{})
+	var sent []string
+	s.ple = func(in *cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error) {
+		select {
+		case <-available:
+		default:
+			return nil, &cloudwatchlogs.ServiceUnavailableException{}
+		}
+		for _, e := range in.LogEvents {
+			sent = append(sent, *e.Message)
+		}
+		return &cloudwatchlogs.PutLogEventsOutput{}, nil
+	}
+
+	sp, err := newSpool(t.TempDir(), 1024*1024, models.NewLogger("cloudwatchlogs", "test", ""))
+	require.NoError(t, err)
+	stop := make(chan struct{})
+	p := NewPusher("us-east-1", Target{"G", "S", "", -1}, &s, time.Hour, maxRetryTimeout, models.NewLogger("cloudwatchlogs", "test", ""), stop, &wg, nil, sp)
+
+	done := 0
+	p.AddEvent(evtMock{"msg0", time.Now(), func() { done++ }})
+	p.AddEvent(evtMock{"msg1", time.Now(), func() { done++ }})
+	time.Sleep(10 * time.Millisecond)
+	// stopping while the service is unavailable spools the pending batch
+	close(stop)
+	wg.Wait()
+	require.Equal(t, 1, sp.Len())
+	assert.Equal(t, 2, done, "spooled events should be acknowledged")
+
+	close(available)
+	stop = make(chan struct{})
+	p = NewPusher("us-east-1", Target{"G", "S", "", -1}, &s, 10*time.Millisecond, maxRetryTimeout, models.NewLogger("cloudwatchlogs", "test", ""), stop, &wg, nil, sp)
+	p.AddEvent(evtMock{"msg2", time.Now(), nil})
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	assert.Equal(t, 0, sp.Len())
+	assert.Equal(t, []string{"msg0", "msg1", "msg2"}, sent)
+}
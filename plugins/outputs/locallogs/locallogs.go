@@ -0,0 +1,176 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package locallogs
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+const (
+	// StdoutPath is the file path which writes the log events to the standard output.
+	StdoutPath = "stdout"
+
+	defaultMaxSizeMB  = 100
+	defaultMaxBackups = 5
+)
+
+// LocalLogs is a log backend writing the log events as JSON lines to a rotating local file or to
+// the standard output, e.g. to validate log pipelines without AWS or to upload them later.
+type LocalLogs struct {
+	FilePath   string `toml:"file_path"`
+	MaxSizeMB  int    `toml:"max_size_mb"` // rotate the file once it reaches the size
+	MaxBackups int    `toml:"max_backups"` // number of rotated files to keep
+
+	Log telegraf.Logger `toml:"-"`
+
+	mu      sync.Mutex
+	writer  io.Writer
+	closer  io.Closer
+	stopped bool
+}
+
+// Record is the JSON line written for each log event.
+type Record struct {
+	LogGroup  string `json:"log_group"`
+	LogStream string `json:"log_stream"`
+	Timestamp int64  `json:"timestamp"` // in milliseconds since epoch
+	Message   string `json:"message"`
+}
+
+var _ logs.LogBackend = (*LocalLogs)(nil)
+
+func (l *LocalLogs) Connect() error {
+	return nil
+}
+
+func (l *LocalLogs) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopped = true
+	if l.closer != nil {
+		return l.closer.Close()
+	}
+	return nil
+}
+
+// Write drops the metrics, only the log events of the log collections are written.
+func (l *LocalLogs) Write([]telegraf.Metric) error {
+	return nil
+}
+
+func (l *LocalLogs) CreateDest(group, stream string, _ int, _ string, _ logs.LogSrc) logs.LogDest {
+	return &localDest{localLogs: l, group: group, stream: stream}
+}
+
+// write appends the lines to the file, which is opened on the first write since the log agent
+// may use the backend without initializing the output.
+func (l *LocalLogs) write(lines []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return logs.ErrOutputStopped
+	}
+	if l.writer == nil {
+		l.open()
+	}
+	_, err := l.writer.Write(lines)
+	return err
+}
+
+func (l *LocalLogs) open() {
+	if l.FilePath == "" || l.FilePath == StdoutPath {
+		l.writer = os.Stdout
+		return
+	}
+	maxSizeMB := l.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+	maxBackups := l.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = defaultMaxBackups
+	}
+	lj := &lumberjack.Logger{
+		Filename:   l.FilePath,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+	}
+	l.writer = lj
+	l.closer = lj
+}
+
+type localDest struct {
+	localLogs     *LocalLogs
+	group, stream string
+}
+
+// Publish writes the events and acknowledges them once written. Events which cannot be written
+// are logged and dropped: returning the error would stop the log collection, and the offsets of
+// the events acknowledged later move past them, so they are not read again after a restart.
+func (d *localDest) Publish(events []logs.LogEvent) error {
+	var lines []byte
+	for _, e := range events {
+		t := e.Time()
+		if t.IsZero() {
+			t = time.Now()
+		}
+		line, err := json.Marshal(Record{
+			LogGroup:  d.group,
+			LogStream: d.stream,
+			Timestamp: t.UnixMilli(),
+			Message:   e.Message(),
+		})
+		if err != nil {
+			d.localLogs.Log.Errorf("Unable to marshal log event of %v/%v: %v", d.group, d.stream, err)
+			continue
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	if err := d.localLogs.write(lines); err != nil {
+		if err == logs.ErrOutputStopped {
+			return err
+		}
+		d.localLogs.Log.Errorf("Unable to write log events of %v/%v to %v: %v", d.group, d.stream, d.localLogs.FilePath, err)
+		return nil
+	}
+	for _, e := range events {
+		e.Done()
+	}
+	return nil
+}
+
+// Description returns a one-sentence description on the Output
+func (l *LocalLogs) Description() string {
+	return "Configuration for the local file or stdout log output."
+}
+
+var sampleConfig = `
+  ## File the log events are written to as JSON lines with their log group, log stream,
+  ## timestamp and message, "stdout" writes them to the standard output.
+  file_path = "stdout"
+  ## Rotate the file once it reaches the size, the oldest rotated files are removed first.
+  #max_size_mb = 100
+  #max_backups = 5
+`
+
+// SampleConfig returns the default configuration of the Output
+func (l *LocalLogs) SampleConfig() string {
+	return sampleConfig
+}
+
+func init() {
+	outputs.Add("locallogs", func() telegraf.Output {
+		return &LocalLogs{}
+	})
+}
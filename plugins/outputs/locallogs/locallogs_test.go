@@ -0,0 +1,101 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package locallogs
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent/logs"
+)
+
+type evtMock struct {
+	m    string
+	t    time.Time
+	done *int
+}
+
+func (e evtMock) Message() string { return e.m }
+func (e evtMock) Time() time.Time { return e.t }
+func (e evtMock) Done()           { *e.done++ }
+
+func TestPublishToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "events.jsonl")
+	l := &LocalLogs{FilePath: path, Log: models.NewLogger("locallogs", "test", "")}
+	dest := l.CreateDest("group", "stream", -1, "", nil)
+
+	done := 0
+	ts := time.UnixMilli(1700000000123)
+	require.NoError(t, dest.Publish([]logs.LogEvent{
+		evtMock{m: "first", t: ts, done: &done},
+		evtMock{m: "second", t: ts.Add(time.Second), done: &done},
+	}))
+	assert.Equal(t, 2, done)
+	require.NoError(t, l.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	assert.Equal(t, []Record{
+		{LogGroup: "group", LogStream: "stream", Timestamp: 1700000000123, Message: "first"},
+		{LogGroup: "group", LogStream: "stream", Timestamp: 1700000001123, Message: "second"},
+	}, records)
+}
+
+func TestPublishAfterClose(t *testing.T) {
+	l := &LocalLogs{FilePath: filepath.Join(t.TempDir(), "events.jsonl"), Log: models.NewLogger("locallogs", "test", "")}
+	dest := l.CreateDest("group", "stream", -1, "", nil)
+	require.NoError(t, l.Close())
+
+	done := 0
+	err := dest.Publish([]logs.LogEvent{evtMock{m: "late", done: &done}})
+	assert.ErrorIs(t, err, logs.ErrOutputStopped)
+	assert.Equal(t, 0, done)
+}
+
+func TestPublishWriteFailure(t *testing.T) {
+	// the parent of the file path is a file, so the events cannot be written
+	parent := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(parent, nil, 0600))
+	l := &LocalLogs{FilePath: filepath.Join(parent, "events.jsonl"), Log: models.NewLogger("locallogs", "test", "")}
+	defer l.Close()
+	dest := l.CreateDest("group", "stream", -1, "", nil)
+
+	done := 0
+	assert.NoError(t, dest.Publish([]logs.LogEvent{evtMock{m: "dropped", done: &done}}))
+	assert.Equal(t, 0, done)
+}
+
+func TestPublishToStdout(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	l := &LocalLogs{FilePath: StdoutPath, Log: models.NewLogger("locallogs", "test", "")}
+	done := 0
+	require.NoError(t, l.CreateDest("group", "stream", -1, "", nil).Publish([]logs.LogEvent{evtMock{m: "hello", t: time.UnixMilli(1), done: &done}}))
+	require.NoError(t, w.Close())
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"log_group":"group","log_stream":"stream","timestamp":1,"message":"hello"}`, line)
+	assert.Equal(t, 1, done)
+}
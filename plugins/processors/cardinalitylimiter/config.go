@@ -0,0 +1,35 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cardinalitylimiter
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+type Config struct {
+	// MaxDimensionSets is the number of unique dimension sets allowed per metric name. The dimension
+	// values of the datapoints beyond it are replaced with Other.
+	MaxDimensionSets int `mapstructure:"max_dimension_sets"`
+	// ResetInterval is the interval the dimension sets not seen since the previous reset are forgotten at,
+	// which frees their slot for new ones.
+	ResetInterval time.Duration `mapstructure:"reset_interval"`
+	// KeepDimensions are the dimensions whose values are never replaced.
+	KeepDimensions []string `mapstructure:"keep_dimensions,omitempty"`
+}
+
+// Verify Config implements Processor interface.
+var _ component.Config = (*Config)(nil)
+
+func (cfg *Config) Validate() error {
+	if cfg.MaxDimensionSets < 1 {
+		return errors.New("max_dimension_sets must be at least 1")
+	}
+	if cfg.ResetInterval <= 0 {
+		return errors.New("reset_interval must be positive")
+	}
+	return nil
+}
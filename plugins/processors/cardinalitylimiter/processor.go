@@ -0,0 +1,221 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cardinalitylimiter
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/awsentity/entityattributes"
+)
+
+const (
+	// OtherValue replaces the dimension values of the datapoints beyond the limit.
+	OtherValue = "Other"
+	// LimitedMetricName is the counter of the datapoints whose dimension values were replaced.
+	LimitedMetricName = "cardinality_limited_datapoints"
+	// MetricNameAttribute is the dimension of the counter holding the name of the limited metric.
+	MetricNameAttribute = "metric_name"
+
+	// reservedPrefix is the prefix of the special attributes read by the exporters.
+	reservedPrefix = "aws:"
+)
+
+// dimensionSets are the hashes of the dimension sets of a metric seen since the previous reset, and
+// during the interval before it.
+type dimensionSets struct {
+	current  map[uint64]struct{}
+	previous map[uint64]struct{}
+	// carried is the number of dimension sets of the previous interval seen again since the reset.
+	carried int
+}
+
+type cardinalityLimiter struct {
+	*Config
+	logger *zap.Logger
+	keep   map[string]struct{}
+
+	mu        sync.Mutex
+	metrics   map[string]*dimensionSets
+	lastReset time.Time
+}
+
+func newCardinalityLimiter(config *Config, logger *zap.Logger) *cardinalityLimiter {
+	keep := make(map[string]struct{}, len(config.KeepDimensions))
+	for _, k := range config.KeepDimensions {
+		keep[k] = struct{}{}
+	}
+	return &cardinalityLimiter{
+		Config:    config,
+		logger:    logger,
+		keep:      keep,
+		metrics:   map[string]*dimensionSets{},
+		lastReset: time.Now(),
+	}
+}
+
+func (c *cardinalityLimiter) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resetIfDue(time.Now())
+
+	limited := map[string]int64{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				m := metrics.At(k)
+				forEachAttributes(m, func(attributes pcommon.Map) {
+					if !c.admit(m.Name(), attributes) {
+						c.replaceValues(attributes)
+						limited[m.Name()]++
+					}
+				})
+			}
+		}
+	}
+	if len(limited) > 0 {
+		c.logger.Debug("Limited the cardinality of metrics", zap.Any("datapoints", limited))
+		appendLimitedCounter(md, limited)
+	}
+	return md, nil
+}
+
+// resetIfDue forgets the dimension sets not seen during the last interval.
+func (c *cardinalityLimiter) resetIfDue(now time.Time) {
+	if now.Sub(c.lastReset) < c.ResetInterval {
+		return
+	}
+	c.lastReset = now
+	for name, sets := range c.metrics {
+		if len(sets.current) == 0 {
+			delete(c.metrics, name)
+			continue
+		}
+		sets.previous = sets.current
+		sets.current = map[uint64]struct{}{}
+		sets.carried = 0
+	}
+}
+
+// admit records the dimension set of the datapoint and returns false when the metric already has the
+// max number of dimension sets. The slots of the dimension sets of the previous interval are reserved
+// for them until the next reset, so they are not taken over by new ones.
+func (c *cardinalityLimiter) admit(name string, attributes pcommon.Map) bool {
+	key, ok := c.dimensionSetKey(attributes)
+	if !ok {
+		return true
+	}
+	sets, ok := c.metrics[name]
+	if !ok {
+		sets = &dimensionSets{current: map[uint64]struct{}{}}
+		c.metrics[name] = sets
+	}
+	if _, ok = sets.current[key]; ok {
+		return true
+	}
+	if _, ok = sets.previous[key]; ok {
+		sets.carried++
+	} else if len(sets.current)+len(sets.previous)-sets.carried >= c.MaxDimensionSets {
+		return false
+	}
+	sets.current[key] = struct{}{}
+	return true
+}
+
+// dimensionSetKey hashes the limited dimensions of the datapoint, it returns false when there is none.
+func (c *cardinalityLimiter) dimensionSetKey(attributes pcommon.Map) (uint64, bool) {
+	pairs := make([]string, 0, attributes.Len())
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		if c.isLimited(k) {
+			pairs = append(pairs, k+"="+v.AsString())
+		}
+		return true
+	})
+	if len(pairs) == 0 {
+		return 0, false
+	}
+	sort.Strings(pairs)
+	h := fnv.New64a()
+	for _, pair := range pairs {
+		h.Write([]byte(pair))
+		h.Write([]byte{0})
+	}
+	return h.Sum64(), true
+}
+
+func (c *cardinalityLimiter) replaceValues(attributes pcommon.Map) {
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		if c.isLimited(k) {
+			v.SetStr(OtherValue)
+		}
+		return true
+	})
+}
+
+func (c *cardinalityLimiter) isLimited(key string) bool {
+	if strings.HasPrefix(key, reservedPrefix) || strings.HasPrefix(key, entityattributes.AWSEntityPrefix) {
+		return false
+	}
+	_, ok := c.keep[key]
+	return !ok
+}
+
+func forEachAttributes(m pmetric.Metric, fn func(attributes pcommon.Map)) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := m.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := m.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := m.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
+
+// appendLimitedCounter adds the delta counter of the limited datapoints per metric name.
+func appendLimitedCounter(md pmetric.Metrics, limited map[string]int64) {
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(TypeStr.String())
+	m := sm.Metrics().AppendEmpty()
+	m.SetName(LimitedMetricName)
+	sum := m.SetEmptySum()
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	sum.SetIsMonotonic(true)
+	now := pcommon.NewTimestampFromTime(time.Now())
+	for name, count := range limited {
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetIntValue(count)
+		dp.Attributes().PutStr(MetricNameAttribute, name)
+	}
+}
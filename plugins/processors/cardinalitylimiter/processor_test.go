@@ -0,0 +1,132 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cardinalitylimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func newGauge(name string, pids ...int64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName(name)
+	dps := m.SetEmptyGauge().DataPoints()
+	for _, pid := range pids {
+		dp := dps.AppendEmpty()
+		dp.SetDoubleValue(1)
+		dp.Attributes().PutStr("host", "host-a")
+		dp.Attributes().PutInt("pid", pid)
+		dp.Attributes().PutStr("aws:StorageResolution", "true")
+	}
+	return md
+}
+
+func pidsOf(t *testing.T, md pmetric.Metrics) []string {
+	dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	var pids []string
+	for i := 0; i < dps.Len(); i++ {
+		host, _ := dps.At(i).Attributes().Get("host")
+		assert.Equal(t, "host-a", host.AsString(), "kept dimensions should not be replaced")
+		resolution, _ := dps.At(i).Attributes().Get("aws:StorageResolution")
+		assert.Equal(t, "true", resolution.AsString(), "reserved attributes should not be replaced")
+		pid, ok := dps.At(i).Attributes().Get("pid")
+		require.True(t, ok)
+		pids = append(pids, pid.AsString())
+	}
+	return pids
+}
+
+func limitedCounts(md pmetric.Metrics) map[string]int64 {
+	counts := map[string]int64{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				if metrics.At(k).Name() != LimitedMetricName {
+					continue
+				}
+				dps := metrics.At(k).Sum().DataPoints()
+				for l := 0; l < dps.Len(); l++ {
+					name, _ := dps.At(l).Attributes().Get(MetricNameAttribute)
+					counts[name.Str()] += dps.At(l).IntValue()
+				}
+			}
+		}
+	}
+	return counts
+}
+
+func TestProcessMetrics(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxDimensionSets = 2
+	limiter := newCardinalityLimiter(cfg, zap.NewNop())
+	ctx := context.Background()
+
+	md, err := limiter.processMetrics(ctx, newGauge("procstat_cpu", 1, 2, 3, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", OtherValue, "1"}, pidsOf(t, md))
+	assert.Equal(t, map[string]int64{"procstat_cpu": 1}, limitedCounts(md))
+
+	md, err = limiter.processMetrics(ctx, newGauge("procstat_memory", 3, 4))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "4"}, pidsOf(t, md), "the limit should be per metric name")
+	assert.Equal(t, 1, md.ResourceMetrics().Len(), "no counter should be added without limited datapoints")
+}
+
+func TestProcessMetricsReset(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxDimensionSets = 2
+	limiter := newCardinalityLimiter(cfg, zap.NewNop())
+	ctx := context.Background()
+
+	md, err := limiter.processMetrics(ctx, newGauge("procstat_cpu", 1, 2))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, pidsOf(t, md))
+
+	limiter.lastReset = time.Now().Add(-cfg.ResetInterval)
+	md, err = limiter.processMetrics(ctx, newGauge("procstat_cpu", 2, 3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", OtherValue}, pidsOf(t, md), "the slot of the pids seen before the reset should be reserved")
+
+	md, err = limiter.processMetrics(ctx, newGauge("procstat_cpu", 1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, pidsOf(t, md), "the pids seen before the reset should still be admitted")
+
+	// pid 1 is not seen during the next interval, so its slot is freed at the reset after it
+	limiter.lastReset = time.Now().Add(-cfg.ResetInterval)
+	md, err = limiter.processMetrics(ctx, newGauge("procstat_cpu", 2, 3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", OtherValue}, pidsOf(t, md))
+	limiter.lastReset = time.Now().Add(-cfg.ResetInterval)
+	md, err = limiter.processMetrics(ctx, newGauge("procstat_cpu", 3, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", OtherValue}, pidsOf(t, md))
+}
+
+func TestProcessMetricsResetLimit(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxDimensionSets = 2
+	limiter := newCardinalityLimiter(cfg, zap.NewNop())
+	ctx := context.Background()
+
+	md, err := limiter.processMetrics(ctx, newGauge("procstat_cpu", 1, 2))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, pidsOf(t, md))
+
+	// the pids carried over from the previous interval count toward the limit
+	limiter.lastReset = time.Now().Add(-cfg.ResetInterval)
+	md, err = limiter.processMetrics(ctx, newGauge("procstat_cpu", 3, 4, 1, 2))
+	require.NoError(t, err)
+	assert.Equal(t, []string{OtherValue, OtherValue, "1", "2"}, pidsOf(t, md))
+	assert.LessOrEqual(t, len(limiter.metrics["procstat_cpu"].current), cfg.MaxDimensionSets)
+}
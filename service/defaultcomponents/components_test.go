@@ -44,6 +44,7 @@ func TestComponents(t *testing.T) {
 		"awsentity",
 		"attributes",
 		"batch",
+		"cardinalitylimiter",
 		"cumulativetodelta",
 		"deltatorate",
 		"ec2tagger",
@@ -301,6 +301,14 @@ func TestOtlpMetricsEmfConfig(t *testing.T) {
 	checkTranslation(t, "otlp_metrics_cloudwatchlogs_config", "windows", nil, "")
 }
 
+func TestSummaryQuantilesConfig(t *testing.T) {
+	resetContext(t)
+	context.CurrentContext().SetMode(config.ModeEC2)
+	checkTranslation(t, "summary_quantiles_config", "linux", nil, "")
+	checkTranslation(t, "summary_quantiles_config", "darwin", nil, "")
+	checkTranslation(t, "summary_quantiles_config", "windows", nil, "")
+}
+
 func TestProcstatMemorySwapConfig(t *testing.T) {
 	resetContext(t)
 	context.CurrentContext().SetRunInContainer(false)
@@ -755,6 +763,19 @@ func TestLogFilterConfig(t *testing.T) {
 	checkTranslation(t, "log_filter", "darwin", nil, "")
 }
 
+func TestLogDestinationsConfig(t *testing.T) {
+	resetContext(t)
+	checkTranslation(t, "log_destinations", "linux", nil, "")
+	checkTranslation(t, "log_destinations", "darwin", nil, "")
+}
+
+func TestLogMetricsConfig(t *testing.T) {
+	resetContext(t)
+	context.CurrentContext().SetMode(config.ModeEC2)
+	checkTranslation(t, "log_metrics", "linux", nil, "")
+	checkTranslation(t, "log_metrics", "darwin", nil, "")
+}
+
 func TestIgnoreInvalidAppendDimensions(t *testing.T) {
 	resetContext(t)
 	context.CurrentContext().SetMode(config.ModeEC2)
@@ -54,6 +54,7 @@ type (
 	outputConfig struct {
 		CloudWatch     []cloudWatchOutputConfig
 		CloudWatchLogs []cloudWatchLogsConfig
+		LocalLogs      []localLogsConfig
 	}
 
 	processorsConfig struct {
@@ -114,11 +115,14 @@ type (
 	logFileConfig struct {
 		Destination     string
 		FileStateFolder string       `toml:"file_state_folder"`
+		WatchMode       string       `toml:"watch_mode"`
 		FileConfig      []fileConfig `toml:"file_config"`
 	}
 
 	fileConfig struct {
-		AutoRemoval     bool   `toml:"auto_removal"`
+		AutoRemoval     bool `toml:"auto_removal"`
+		Destination     string
+		Destinations    []string
 		FilePath        string `toml:"file_path"`
 		FromBeginning   bool   `toml:"from_beginning"`
 		LogGroupName    string `toml:"log_group_name"`
@@ -132,6 +136,7 @@ type (
 		DeploymentEnvironment string `toml:"deployment_environment"`
 		Tags                  map[string]string
 		Filters               []fileConfigFilter
+		LogMetrics            []fileConfigLogMetric `toml:"log_metrics"`
 	}
 
 	k8sApiServerConfig struct {
@@ -174,14 +179,27 @@ type (
 		ClusterName          string                              `toml:"cluster_name"`
 		PrometheusConfigPath string                              `toml:"prometheus_config_path"`
 		EcsServiceDiscovery  prometheusEcsServiceDiscoveryConfig `toml:"ecs_service_discovery"`
+		RemoteWriteReceiver  prometheusRemoteWriteReceiverConfig `toml:"remote_write_receiver"`
 		Tags                 map[string]string
 	}
 
+	prometheusRemoteWriteReceiverConfig struct {
+		ServiceAddress    string   `toml:"service_address"`
+		Path              string   `toml:"path"`
+		MaxRequestSize    int64    `toml:"max_request_size"`
+		TLSCert           string   `toml:"tls_cert"`
+		TLSKey            string   `toml:"tls_key"`
+		TLSAllowedCACerts []string `toml:"tls_allowed_cacerts"`
+	}
+
 	prometheusEcsServiceDiscoveryConfig struct {
 		SdClusterRegion         string                    `toml:"sd_cluster_region"`
 		SdFrequency             string                    `toml:"sd_frequency"`
 		SdResultFile            string                    `toml:"sd_result_file"`
 		SdTargetCluster         string                    `toml:"sd_target_cluster"`
+		SdLocalMode             bool                      `toml:"sd_local_mode"`
+		SdEcsAgentEndpoint      string                    `toml:"sd_ecs_agent_endpoint"`
+		SdDockerEndpoint        string                    `toml:"sd_docker_endpoint"`
 		DockerLabel             map[string]string         `toml:"docker_label"`
 		ServiceNameListForTasks []serviceNameListForTasks `toml:"service_name_list_for_tasks"`
 		TaskDefinitionList      []taskDefinitionList      `toml:"task_definition_list"`
@@ -222,10 +240,15 @@ type (
 	}
 
 	statsdConfig struct {
-		AllowedPendingMessages int `toml:"allowed_pending_messages"`
+		AllowedPendingMessages int    `toml:"allowed_pending_messages"`
+		EventsLogGroupName     string `toml:"events_log_group_name"`
+		EventsLogStreamName    string `toml:"events_log_stream_name"`
 		Interval               string
+		MaxTCPConnections      int    `toml:"max_tcp_connections"`
 		MetricSeparator        string `toml:"metric_separator"`
 		ParseDataDogTags       bool   `toml:"parse_data_dog_tags"`
+		ParserWorkers          int    `toml:"parser_workers"`
+		SelfMetrics            bool   `toml:"self_metrics"`
 		ServiceAddress         string `toml:"service_address"`
 		Tags                   map[string]string
 	}
@@ -267,6 +290,7 @@ type (
 	}
 
 	cloudWatchLogsConfig struct {
+		Alias              string
 		EndpointOverride   string `toml:"endpoint_override"`
 		ForceFlushInterval string `toml:"force_flush_interval"`
 		LogStreamName      string `toml:"log_stream_name"`
@@ -276,9 +300,36 @@ type (
 		TagPass            map[string][]string
 	}
 
+	fileConfigLogMetric struct {
+		Dimensions []string
+		Expression string
+		Name       string
+		Value      string
+	}
+
+	localLogsConfig struct {
+		Alias      string
+		FilePath   string `toml:"file_path"`
+		MaxBackups int    `toml:"max_backups"`
+		MaxSizeMB  int    `toml:"max_size_mb"`
+	}
+
 	fileConfigFilter struct {
+		Conditions []fileConfigFilterCondition
 		Expression string
+		Field      string
+		Match      string
+		Operator   string
+		SampleRate int `toml:"sample_rate"`
 		Type       string
+		Value      float64
+	}
+
+	fileConfigFilterCondition struct {
+		Expression string
+		Field      string
+		Operator   string
+		Value      float64
 	}
 
 	// Processors
@@ -18,6 +18,8 @@ var ChildRule = map[string]Rule{}
 const (
 	SectionKey             = "logs"
 	Output_Cloudwatch_Logs = "cloudwatchlogs"
+
+	metricsSectionKey = "metrics"
 )
 
 func GetCurPath() string {
@@ -34,12 +36,22 @@ type Logs struct {
 	MetadataInfo          map[string]string
 	ServiceName           string
 	DeploymentEnvironment string
+	// Overrides of the additional outputs by destination name
+	Destinations map[string]map[string]interface{}
+	// Output plugin of each destination, cloudwatchlogs or locallogs
+	DestinationOutputs map[string]string
+	// Whether the metrics section is set, the log metrics are published through its pipeline
+	MetricsSectionSet bool
+	// Whether the logs section is set, the cloudwatchlogs output is only added with it. The logs
+	// section is translated before the metrics one.
+	SectionSet bool
 }
 
 var (
 	GlobalLogConfig       = Logs{}
 	serviceName           ServiceName
 	deploymentEnvironment DeploymentEnvironment
+	destinations          Destinations
 )
 
 func (l *Logs) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
@@ -49,10 +61,14 @@ func (l *Logs) ApplyRule(input interface{}) (returnKey string, returnVal interfa
 	processors := map[string]interface{}{}
 	cloudwatchConfig := map[string]interface{}{}
 	GlobalLogConfig.MetadataInfo = util.GetMetadataInfo(util.Ec2MetadataInfoProvider)
+	_, GlobalLogConfig.MetricsSectionSet = im[metricsSectionKey]
+	_, GlobalLogConfig.SectionSet = im[SectionKey]
 
 	//Apply Environment and ServiceName rules
 	serviceName.ApplyRule(im[SectionKey])
 	deploymentEnvironment.ApplyRule(im[SectionKey])
+	//Apply destinations rule, collect_list entries are validated against the destinations
+	destinations.ApplyRule(im[SectionKey])
 
 	//Check if this plugin exist in the input instance
 	//If not, not process
@@ -88,9 +104,7 @@ func (l *Logs) ApplyRule(input interface{}) (returnKey string, returnVal interfa
 			}
 		}
 
-		cloudwatchInfo := map[string]interface{}{}
-		cloudwatchInfo["cloudwatchlogs"] = []interface{}{cloudwatchConfig}
-		result["outputs"] = cloudwatchInfo
+		result["outputs"] = destinationOutputs(cloudwatchConfig)
 
 		if len(inputs) > 0 {
 			result["inputs"] = inputs
@@ -630,6 +630,153 @@ func TestAutoRemoval(t *testing.T) {
 	assert.Equal(t, expectVal, val)
 }
 
+func TestReadCompressedFiles(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"collect_list":[
+			{
+				"file_path":"path1",
+				"read_compressed_files": true
+			}
+		]
+	}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":              "path1",
+		"from_beginning":         true,
+		"pipe":                   false,
+		"retention_in_days":      -1,
+		"log_group_class":        "",
+		"read_compressed_files":  true,
+		"service_name":           "",
+		"deployment_environment": "",
+	}}
+	assert.Equal(t, expectVal, val)
+}
+
+func TestParsedFields(t *testing.T) {
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"collect_list":[
+			{
+				"file_path":"path1",
+				"parsers": [{"type": "json"}],
+				"timestamp_field": "time",
+				"level_field": "severity",
+				"render_json": true
+			}
+		]
+	}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":              "path1",
+		"from_beginning":         true,
+		"pipe":                   false,
+		"retention_in_days":      -1,
+		"log_group_class":        "",
+		"parsers":                []interface{}{map[string]interface{}{"type": "json"}},
+		"timestamp_field":        "time",
+		"level_field":            "severity",
+		"render_json":            true,
+		"service_name":           "",
+		"deployment_environment": "",
+	}}
+	assert.Equal(t, expectVal, val)
+}
+
+func TestRedaction(t *testing.T) {
+	translator.ResetMessages()
+	f := new(FileConfig)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"collect_list":[
+			{
+				"file_path":"path1",
+				"redaction": {
+					"hash_key": "key",
+					"rules": [{"name": "email", "action": "hash"}, {"action": "drop"}]
+				}
+			}
+		]
+	}`), &input)
+	if e != nil {
+		assert.Fail(t, e.Error())
+	}
+	_, val := f.ApplyRule(input)
+	expectVal := []interface{}{map[string]interface{}{
+		"file_path":              "path1",
+		"from_beginning":         true,
+		"pipe":                   false,
+		"retention_in_days":      -1,
+		"log_group_class":        "",
+		"redactions":             []interface{}{map[string]interface{}{"name": "email", "action": "hash"}},
+		"redaction_hash_key":     "key",
+		"service_name":           "",
+		"deployment_environment": "",
+	}}
+	assert.Equal(t, expectVal, val)
+	assert.Len(t, translator.ErrorMessages, 1)
+}
+
+func TestDestination(t *testing.T) {
+	translator.ResetMessages()
+	logs.GlobalLogConfig.Destinations = map[string]map[string]interface{}{"security": {}}
+	defer func() { logs.GlobalLogConfig.Destinations = nil }()
+
+	r := new(Destination)
+	var input interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"file_path":"path1","destination":"security"}`), &input))
+	key, val := r.ApplyRule(input)
+	assert.Equal(t, "destination", key)
+	assert.Equal(t, "security", val)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"file_path":"path1","destination":"cloudwatchlogs"}`), &input))
+	key, val = r.ApplyRule(input)
+	assert.Equal(t, "destination", key)
+	assert.Equal(t, "cloudwatchlogs", val)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"file_path":"path1"}`), &input))
+	key, _ = r.ApplyRule(input)
+	assert.Equal(t, "", key)
+	assert.Len(t, translator.ErrorMessages, 0)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"file_path":"path1","destination":"unknown"}`), &input))
+	key, _ = r.ApplyRule(input)
+	assert.Equal(t, "", key)
+	assert.Len(t, translator.ErrorMessages, 1)
+}
+
+func TestDestinations(t *testing.T) {
+	translator.ResetMessages()
+	logs.GlobalLogConfig.Destinations = map[string]map[string]interface{}{"security": {}}
+	defer func() { logs.GlobalLogConfig.Destinations = nil }()
+
+	r := new(Destinations)
+	var input interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"file_path":"path1","destinations":["cloudwatchlogs","security"]}`), &input))
+	key, val := r.ApplyRule(input)
+	assert.Equal(t, "destinations", key)
+	assert.Equal(t, []string{"cloudwatchlogs", "security"}, val)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"file_path":"path1"}`), &input))
+	key, _ = r.ApplyRule(input)
+	assert.Equal(t, "", key)
+	assert.Len(t, translator.ErrorMessages, 0)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"file_path":"path1","destinations":["security","unknown"]}`), &input))
+	key, _ = r.ApplyRule(input)
+	assert.Equal(t, "", key)
+	assert.Len(t, translator.ErrorMessages, 1)
+}
+
 func TestFileConfigOutputFile(t *testing.T) {
 	dir, err := os.MkdirTemp("", "")
 	assert.NoError(t, err)
@@ -1132,3 +1279,30 @@ func TestServiceAndEnvironment(t *testing.T) {
 	}
 	assert.Equal(t, expectVal, val)
 }
+
+func TestLogMetrics(t *testing.T) {
+	translator.ResetMessages()
+	logs.GlobalLogConfig.MetricsSectionSet = true
+	defer func() { logs.GlobalLogConfig.MetricsSectionSet = false }()
+
+	r := new(LogMetrics)
+	var input interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"file_path":"path1","log_metrics":[
+		{"name":"http_errors","expression":"status=(?P<status>5\\d\\d)","dimensions":["status"]},
+		{"name":"request_latency","value":"latency_ms"},
+		{"name":"invalid"},
+		{"name":"invalid_regex","expression":"abc)"}]}`), &input))
+	key, val := r.ApplyRule(input)
+	assert.Equal(t, "log_metrics", key)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "http_errors", "expression": "status=(?P<status>5\\d\\d)", "dimensions": []string{"status"}},
+		map[string]interface{}{"name": "request_latency", "value": "latency_ms"},
+	}, val)
+	assert.Len(t, translator.ErrorMessages, 2)
+
+	translator.ResetMessages()
+	logs.GlobalLogConfig.MetricsSectionSet = false
+	key, _ = r.ApplyRule(input)
+	assert.Equal(t, "", key)
+	assert.Len(t, translator.ErrorMessages, 1)
+}
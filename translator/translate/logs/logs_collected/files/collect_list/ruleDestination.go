@@ -0,0 +1,70 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs"
+)
+
+const (
+	DestinationSectionKey  = "destination"
+	DestinationsSectionKey = "destinations"
+)
+
+type Destination struct {
+}
+
+// ApplyRule routes the file to one of the destinations declared in the logs section, the
+// file is published to the default destination when it is not set.
+func (d *Destination) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(DestinationSectionKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	if !isDeclaredDestination(returnVal) {
+		translator.AddErrorMessages(GetCurPath()+DestinationSectionKey, fmt.Sprintf("Destination %v is not declared in the logs destinations", returnVal))
+		return "", nil
+	}
+	returnKey = DestinationSectionKey
+	return
+}
+
+type Destinations struct {
+}
+
+// ApplyRule fans the file out to several of the destinations declared in the logs section, the
+// file offset only advances once all of them have accepted the log events.
+func (d *Destinations) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, val := translator.DefaultCase(DestinationsSectionKey, "", input)
+	names, ok := val.([]interface{})
+	if !ok || len(names) == 0 {
+		return
+	}
+	var res []string
+	for _, name := range names {
+		if !isDeclaredDestination(name) {
+			translator.AddErrorMessages(GetCurPath()+DestinationsSectionKey, fmt.Sprintf("Destination %v is not declared in the logs destinations", name))
+			return "", nil
+		}
+		res = append(res, name.(string))
+	}
+	return DestinationsSectionKey, res
+}
+
+func isDeclaredDestination(val interface{}) bool {
+	name, ok := val.(string)
+	if !ok {
+		return false
+	}
+	_, declared := logs.GlobalLogConfig.Destinations[name]
+	return declared || name == logs.Output_Cloudwatch_Logs
+}
+
+func init() {
+	RegisterRule(DestinationSectionKey, []Rule{new(Destination)})
+	RegisterRule(DestinationsSectionKey, []Rule{new(Destinations)})
+}
@@ -14,6 +14,14 @@ const (
 	FiltersSectionKey           = "filters"
 	FiltersTypeSectionKey       = "type"
 	FiltersExpressionSectionKey = "expression"
+	FiltersFieldSectionKey      = "field"
+	FiltersOperatorSectionKey   = "operator"
+	FiltersValueSectionKey      = "value"
+	FiltersMatchSectionKey      = "match"
+	FiltersConditionsSectionKey = "conditions"
+	FiltersSampleRateSectionKey = "sample_rate"
+
+	sampleFilterType = "sample"
 )
 
 type LogFilter struct {
@@ -33,16 +41,48 @@ func (lf *LogFilter) ApplyRule(input interface{}) (returnKey string, returnVal i
 				continue
 			}
 			filterMap[FiltersTypeSectionKey] = filterVal
-			_, filterVal = translator.DefaultCase(FiltersExpressionSectionKey, "", filter)
-			if filterVal == "" {
-				translator.AddErrorMessages(GetCurPath()+FiltersSectionKey, fmt.Sprintf("Filter %s is invalid", filter))
-				continue
+
+			if filterVal == sampleFilterType {
+				_, sampleRate := translator.DefaultIntegralCase(FiltersSampleRateSectionKey, float64(0), filter)
+				if rate, ok := sampleRate.(int); !ok || rate < 1 {
+					translator.AddErrorMessages(GetCurPath()+FiltersSectionKey, fmt.Sprintf("Filter %s is invalid", filter))
+					continue
+				}
+				filterMap[FiltersSampleRateSectionKey] = sampleRate
 			}
-			if _, err := regexp.Compile(filterVal.(string)); err != nil {
-				translator.AddErrorMessages(GetCurPath()+FiltersSectionKey, fmt.Sprintf("Filter expression %s is invalid", filter))
-				continue
+
+			_, match := translator.DefaultCase(FiltersMatchSectionKey, "", filter)
+			if match != "" {
+				filterMap[FiltersMatchSectionKey] = match
+			}
+
+			_, conditions := translator.DefaultCase(FiltersConditionsSectionKey, "", filter)
+			if conditionArr, ok := conditions.([]interface{}); ok && len(conditionArr) > 0 {
+				var conditionRes []interface{}
+				valid := true
+				for _, condition := range conditionArr {
+					conditionMap, ok := applyFilterCondition(condition)
+					if !ok {
+						translator.AddErrorMessages(GetCurPath()+FiltersSectionKey, fmt.Sprintf("Filter condition %s is invalid", condition))
+						valid = false
+						break
+					}
+					conditionRes = append(conditionRes, conditionMap)
+				}
+				if !valid {
+					continue
+				}
+				filterMap[FiltersConditionsSectionKey] = conditionRes
+			} else {
+				conditionMap, ok := applyFilterCondition(filter)
+				if !ok {
+					translator.AddErrorMessages(GetCurPath()+FiltersSectionKey, fmt.Sprintf("Filter %s is invalid", filter))
+					continue
+				}
+				for k, v := range conditionMap {
+					filterMap[k] = v
+				}
 			}
-			filterMap[FiltersExpressionSectionKey] = filterVal
 			res = append(res, filterMap)
 		}
 		returnKey = FiltersSectionKey
@@ -53,6 +93,34 @@ func (lf *LogFilter) ApplyRule(input interface{}) (returnKey string, returnVal i
 	return
 }
 
+// applyFilterCondition translates the expression and/or the numeric comparison a filter or a
+// filter condition matches with. A condition needs an expression unless it compares a field.
+func applyFilterCondition(condition interface{}) (map[string]interface{}, bool) {
+	conditionMap := map[string]interface{}{}
+	_, field := translator.DefaultCase(FiltersFieldSectionKey, "", condition)
+	if field != "" {
+		conditionMap[FiltersFieldSectionKey] = field
+	}
+	_, operator := translator.DefaultCase(FiltersOperatorSectionKey, "", condition)
+	if operator != "" {
+		_, value := translator.DefaultCase(FiltersValueSectionKey, "", condition)
+		if field == "" || value == "" {
+			return nil, false
+		}
+		conditionMap[FiltersOperatorSectionKey] = operator
+		conditionMap[FiltersValueSectionKey] = value
+	}
+	_, expression := translator.DefaultCase(FiltersExpressionSectionKey, "", condition)
+	if expression == "" {
+		return conditionMap, operator != ""
+	}
+	if _, err := regexp.Compile(expression.(string)); err != nil {
+		return nil, false
+	}
+	conditionMap[FiltersExpressionSectionKey] = expression
+	return conditionMap, true
+}
+
 func init() {
 	lf := new(LogFilter)
 	r := []Rule{lf}
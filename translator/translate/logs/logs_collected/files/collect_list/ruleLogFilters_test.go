@@ -78,3 +78,49 @@ func TestApplyLogFiltersRuleInvalidRegex(t *testing.T) {
 	assert.Nil(t, retVal)
 	assert.Len(t, translator.ErrorMessages, 1)
 }
+
+func TestApplyLogFiltersRuleConditions(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogFilter)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"filters": [
+			{"type": "include", "field": "latency_ms", "operator": ">", "value": 500},
+			{"type": "exclude", "match": "any", "conditions": [
+				{"field": "level", "expression": "DEBUG"},
+				{"field": "status", "operator": "<", "value": 400}
+			]},
+			{"type": "sample", "expression": "DEBUG", "sample_rate": 10}
+		]
+	}`), &input)
+	assert.Nil(t, e)
+
+	_, retVal := r.ApplyRule(input)
+	assert.Len(t, translator.ErrorMessages, 0)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "include", "field": "latency_ms", "operator": ">", "value": float64(500)},
+		map[string]interface{}{"type": "exclude", "match": "any", "conditions": []interface{}{
+			map[string]interface{}{"field": "level", "expression": "DEBUG"},
+			map[string]interface{}{"field": "status", "operator": "<", "value": float64(400)},
+		}},
+		map[string]interface{}{"type": "sample", "expression": "DEBUG", "sample_rate": 10},
+	}, retVal)
+}
+
+func TestApplyLogFiltersRuleInvalidConditions(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogFilter)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"filters": [
+			{"type": "include", "operator": ">", "value": 500},
+			{"type": "include", "field": "latency_ms", "operator": ">"},
+			{"type": "exclude", "conditions": [{"field": "level"}]},
+			{"type": "sample", "expression": "DEBUG"}
+		]
+	}`), &input)
+	assert.Nil(t, e)
+	_, retVal := r.ApplyRule(input)
+	assert.Nil(t, retVal)
+	assert.Len(t, translator.ErrorMessages, 4)
+}
@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs"
+)
+
+const (
+	LogMetricsSectionKey           = "log_metrics"
+	LogMetricsNameSectionKey       = "name"
+	LogMetricsExpressionSectionKey = "expression"
+	LogMetricsValueSectionKey      = "value"
+	LogMetricsDimensionsSectionKey = "dimensions"
+)
+
+type LogMetrics struct {
+}
+
+// ApplyRule translates the metrics extracted from the log events of the file. They are published
+// through the metrics pipeline, so the metrics section must be configured.
+func (lm *LogMetrics) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	val, ok := im[LogMetricsSectionKey]
+	if !ok {
+		return
+	}
+	if !logs.GlobalLogConfig.MetricsSectionSet {
+		translator.AddErrorMessages(GetCurPath()+LogMetricsSectionKey, "log_metrics are published through the metrics pipeline which requires the metrics section")
+		return
+	}
+	var res []interface{}
+	for _, metric := range val.([]interface{}) {
+		metricMap, ok := applyLogMetric(metric)
+		if !ok {
+			translator.AddErrorMessages(GetCurPath()+LogMetricsSectionKey, fmt.Sprintf("Log metric %v is invalid", metric))
+			continue
+		}
+		res = append(res, metricMap)
+	}
+	if len(res) == 0 {
+		return
+	}
+	return LogMetricsSectionKey, res
+}
+
+// applyLogMetric translates a log metric, which needs a name and an expression or a value.
+func applyLogMetric(metric interface{}) (map[string]interface{}, bool) {
+	metricMap := map[string]interface{}{}
+	_, name := translator.DefaultCase(LogMetricsNameSectionKey, "", metric)
+	if name == "" {
+		return nil, false
+	}
+	metricMap[LogMetricsNameSectionKey] = name
+	_, expression := translator.DefaultCase(LogMetricsExpressionSectionKey, "", metric)
+	if expression != "" {
+		if _, err := regexp.Compile(expression.(string)); err != nil {
+			return nil, false
+		}
+		metricMap[LogMetricsExpressionSectionKey] = expression
+	}
+	_, value := translator.DefaultCase(LogMetricsValueSectionKey, "", metric)
+	if value != "" {
+		metricMap[LogMetricsValueSectionKey] = value
+	} else if expression == "" {
+		return nil, false
+	}
+	if _, ok := metric.(map[string]interface{})[LogMetricsDimensionsSectionKey]; ok {
+		_, dimensions := translator.DefaultStringArrayCase(LogMetricsDimensionsSectionKey, []interface{}{}, metric)
+		if dims, ok := dimensions.([]string); ok && len(dims) > 0 {
+			metricMap[LogMetricsDimensionsSectionKey] = dims
+		}
+	}
+	return metricMap, true
+}
+
+func init() {
+	RegisterRule(LogMetricsSectionKey, []Rule{new(LogMetrics)})
+}
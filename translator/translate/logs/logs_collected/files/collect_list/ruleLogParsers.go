@@ -0,0 +1,68 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	ParsersSectionKey           = "parsers"
+	ParsersTypeSectionKey       = "type"
+	ParsersExpressionSectionKey = "expression"
+	ParsersSourceSectionKey     = "source"
+
+	regexParserType = "regex"
+)
+
+type LogParser struct {
+}
+
+func (lp *LogParser) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	var res []interface{}
+	if val, ok := im[ParsersSectionKey]; ok {
+		parserArr := val.([]interface{})
+		for _, parser := range parserArr {
+			parserMap := map[string]interface{}{}
+
+			_, parserType := translator.DefaultCase(ParsersTypeSectionKey, "", parser)
+			if parserType == "" {
+				translator.AddErrorMessages(GetCurPath()+ParsersSectionKey, fmt.Sprintf("Parser %s is invalid", parser))
+				continue
+			}
+			parserMap[ParsersTypeSectionKey] = parserType
+			if parserType == regexParserType {
+				_, expression := translator.DefaultCase(ParsersExpressionSectionKey, "", parser)
+				if expression == "" {
+					translator.AddErrorMessages(GetCurPath()+ParsersSectionKey, fmt.Sprintf("Parser %s is invalid", parser))
+					continue
+				}
+				if _, err := regexp.Compile(expression.(string)); err != nil {
+					translator.AddErrorMessages(GetCurPath()+ParsersSectionKey, fmt.Sprintf("Parser expression %s is invalid", parser))
+					continue
+				}
+				parserMap[ParsersExpressionSectionKey] = expression
+			}
+			if _, source := translator.DefaultCase(ParsersSourceSectionKey, "", parser); source != "" {
+				parserMap[ParsersSourceSectionKey] = source
+			}
+			res = append(res, parserMap)
+		}
+		returnKey = ParsersSectionKey
+	} else {
+		returnKey = ""
+	}
+	returnVal = res
+	return
+}
+
+func init() {
+	lp := new(LogParser)
+	r := []Rule{lp}
+	RegisterRule(ParsersSectionKey, r)
+}
@@ -0,0 +1,52 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+func TestApplyLogParsersRule(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogParser)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"parsers": [
+			{"type": "regex", "expression": "^(?P<level>\\w+) (?P<payload>.*)$"},
+			{"type": "json", "source": "payload"}
+		]
+	}`), &input)
+	assert.Nil(t, e)
+
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "parsers", retKey)
+	assert.Len(t, translator.ErrorMessages, 0)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "regex", "expression": "^(?P<level>\\w+) (?P<payload>.*)$"},
+		map[string]interface{}{"type": "json", "source": "payload"},
+	}, retVal)
+}
+
+func TestApplyLogParsersRuleInvalid(t *testing.T) {
+	translator.ResetMessages()
+	r := new(LogParser)
+	var input interface{}
+	e := json.Unmarshal([]byte(`{
+		"parsers": [
+			{"expression": "foo"},
+			{"type": "regex"},
+			{"type": "regex", "expression": "(?!re)"}
+		]
+	}`), &input)
+	assert.Nil(t, e)
+	retKey, retVal := r.ApplyRule(input)
+	assert.Equal(t, "parsers", retKey)
+	assert.Nil(t, retVal)
+	assert.Len(t, translator.ErrorMessages, 3)
+}
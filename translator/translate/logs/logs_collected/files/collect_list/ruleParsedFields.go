@@ -0,0 +1,60 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	TimestampFieldSectionKey = "timestamp_field"
+	LevelFieldSectionKey     = "level_field"
+	RenderJSONSectionKey     = "render_json"
+)
+
+type TimestampField struct {
+}
+
+func (f *TimestampField) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(TimestampFieldSectionKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	returnKey = TimestampFieldSectionKey
+	return
+}
+
+type LevelField struct {
+}
+
+func (f *LevelField) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(LevelFieldSectionKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	returnKey = LevelFieldSectionKey
+	return
+}
+
+type RenderJSON struct {
+}
+
+func (r *RenderJSON) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	_, returnVal = translator.DefaultCase(RenderJSONSectionKey, "", input)
+	if returnVal == "" {
+		return
+	}
+	returnKey = RenderJSONSectionKey
+	var ok bool
+	if returnVal, ok = returnVal.(bool); !ok {
+		returnVal = false
+	}
+	return
+}
+
+func init() {
+	RegisterRule(TimestampFieldSectionKey, []Rule{new(TimestampField)})
+	RegisterRule(LevelFieldSectionKey, []Rule{new(LevelField)})
+	RegisterRule(RenderJSONSectionKey, []Rule{new(RenderJSON)})
+}
@@ -0,0 +1,45 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package collect_list
+
+import (
+	logUtil "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/util"
+)
+
+type RedactionRules struct {
+}
+
+func (r *RedactionRules) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	redaction, ok := im[logUtil.RedactionSectionKey]
+	if !ok {
+		return
+	}
+	if rules := logUtil.TranslateRedactionRules(redaction, GetCurPath()); len(rules) > 0 {
+		returnKey = logUtil.RedactionsKey
+		returnVal = rules
+	}
+	return
+}
+
+type RedactionHashKey struct {
+}
+
+func (r *RedactionHashKey) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	redaction, ok := im[logUtil.RedactionSectionKey]
+	if !ok {
+		return
+	}
+	if hashKey := logUtil.TranslateRedactionHashKey(redaction); hashKey != "" {
+		returnKey = logUtil.RedactionHashKey
+		returnVal = hashKey
+	}
+	return
+}
+
+func init() {
+	r := []Rule{new(RedactionRules), new(RedactionHashKey)}
+	RegisterRule(logUtil.RedactionSectionKey, r)
+}
@@ -0,0 +1,23 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package files
+
+const (
+	watchModeKey = "watch_mode"
+)
+
+type WatchMode struct {
+}
+
+// WatchMode is only set when configured, the plugin polls the files by default
+func (w *WatchMode) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	if mode, ok := input.(map[string]interface{})[watchModeKey]; ok {
+		return watchModeKey, mode
+	}
+	return "", nil
+}
+
+func init() {
+	RegisterRule(watchModeKey, new(WatchMode))
+}
@@ -0,0 +1,25 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package files
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyWatchModeRule(t *testing.T) {
+	r := new(WatchMode)
+	var input interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"watch_mode": "inotify"}`), &input))
+	actualReturnKey, actualReturnVal := r.ApplyRule(input)
+	assert.Equal(t, "watch_mode", actualReturnKey)
+	assert.Equal(t, "inotify", actualReturnVal)
+
+	require.NoError(t, json.Unmarshal([]byte(`{}`), &input))
+	actualReturnKey, _ = r.ApplyRule(input)
+	assert.Equal(t, "", actualReturnKey, "the plugin polls the files by default")
+}
@@ -201,6 +201,156 @@ func TestLogs_EndpointOverride(t *testing.T) {
 	ctx.SetMode(config.ModeEC2) //reset back to default mode
 }
 
+func TestLogs_Spool(t *testing.T) {
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{"log_stream_name":"LOG_STREAM_NAME","spool":{"max_size_mb":512}}}`), &input)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+
+	ctx := context.CurrentContext()
+	ctx.SetMode(config.ModeEC2)
+
+	_, actual := l.ApplyRule(input)
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{
+				map[string]interface{}{
+					"region":               "us-east-1",
+					"region_type":          "any",
+					"mode":                 "EC2",
+					"log_stream_name":      "LOG_STREAM_NAME",
+					"force_flush_interval": "5s",
+					"spool_directory":      "/opt/aws/amazon-cloudwatch-agent/logs/spool",
+					"spool_max_size_mb":    512,
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual, "Expected to be equal")
+}
+
+func TestLogs_LocalDestinations(t *testing.T) {
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{"log_stream_name":"LOG_STREAM_NAME","destinations":{
+		"archive":{"type":"file","file_path":"/tmp/archive.jsonl","max_size_mb":10},
+		"console":{"type":"stdout"}}}}`), &input)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+
+	ctx := context.CurrentContext()
+	ctx.SetMode(config.ModeEC2)
+
+	_, actual := l.ApplyRule(input)
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{
+				map[string]interface{}{
+					"region":               "us-east-1",
+					"region_type":          "any",
+					"mode":                 "EC2",
+					"log_stream_name":      "LOG_STREAM_NAME",
+					"force_flush_interval": "5s",
+				},
+			},
+			"locallogs": []interface{}{
+				map[string]interface{}{
+					"alias":       "archive",
+					"file_path":   "/tmp/archive.jsonl",
+					"max_size_mb": 10,
+				},
+				map[string]interface{}{
+					"alias":     "console",
+					"file_path": "stdout",
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual, "Expected to be equal")
+	GlobalLogConfig.Destinations = nil
+	GlobalLogConfig.DestinationOutputs = nil
+}
+
+func TestLogs_Redaction(t *testing.T) {
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{"log_stream_name":"LOG_STREAM_NAME","redaction":{"hash_key":"key","rules":[
+		{"name":"credit_card"},
+		{"name":"session","expression":"session=(?P<secret>\\w+)","action":"hash"}
+	]}}}`), &input)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+
+	ctx := context.CurrentContext()
+	ctx.SetMode(config.ModeEC2)
+
+	_, actual := l.ApplyRule(input)
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{
+				map[string]interface{}{
+					"region":               "us-east-1",
+					"region_type":          "any",
+					"mode":                 "EC2",
+					"log_stream_name":      "LOG_STREAM_NAME",
+					"force_flush_interval": "5s",
+					"redaction_hash_key":   "key",
+					"redactions": []interface{}{
+						map[string]interface{}{"name": "credit_card"},
+						map[string]interface{}{"name": "session", "expression": "session=(?P<secret>\\w+)", "action": "hash"},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual, "Expected to be equal")
+}
+
+func TestLogs_DryRunFile(t *testing.T) {
+	l := new(Logs)
+	agent.Global_Config.Region = "us-east-1"
+	agent.Global_Config.RegionType = "any"
+
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"logs":{"log_stream_name":"LOG_STREAM_NAME","dry_run_file":"/tmp/capture.jsonl"}}`), &input)
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+
+	ctx := context.CurrentContext()
+	ctx.SetMode(config.ModeEC2)
+
+	_, actual := l.ApplyRule(input)
+	expected := map[string]interface{}{
+		"outputs": map[string]interface{}{
+			"cloudwatchlogs": []interface{}{
+				map[string]interface{}{
+					"region":               "us-east-1",
+					"region_type":          "any",
+					"mode":                 "EC2",
+					"log_stream_name":      "LOG_STREAM_NAME",
+					"force_flush_interval": "5s",
+					"dry_run_file":         "/tmp/capture.jsonl",
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual, "Expected to be equal")
+}
+
 func TestLogs_ServiceAndEnvironment(t *testing.T) {
 	l := new(Logs)
 	agent.Global_Config.Region = "us-east-1"
@@ -20,7 +20,7 @@ func (d *SDClusterRegion) ApplyRule(input interface{}) (returnKey string, return
 	if returnVal == "" {
 		returnVal = ecsutil.GetECSUtilSingleton().Region
 	}
-	if returnVal == "" {
+	if returnVal == "" && !isLocalMode(input) {
 		translator.AddErrorMessages(GetCurPath(), "ECS Cluster Region is not defined")
 	}
 	return
@@ -0,0 +1,22 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+const (
+	SectionKeySDDockerEndpoint = "sd_docker_endpoint"
+)
+
+type SDDockerEndpoint struct {
+}
+
+func (d *SDDockerEndpoint) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	if endpoint, ok := input.(map[string]interface{})[SectionKeySDDockerEndpoint]; ok {
+		return SectionKeySDDockerEndpoint, endpoint
+	}
+	return "", nil
+}
+
+func init() {
+	RegisterRule(SectionKeySDDockerEndpoint, new(SDDockerEndpoint))
+}
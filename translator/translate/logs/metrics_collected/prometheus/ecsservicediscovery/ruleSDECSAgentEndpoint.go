@@ -0,0 +1,22 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+const (
+	SectionKeySDECSAgentEndpoint = "sd_ecs_agent_endpoint"
+)
+
+type SDECSAgentEndpoint struct {
+}
+
+func (d *SDECSAgentEndpoint) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	if endpoint, ok := input.(map[string]interface{})[SectionKeySDECSAgentEndpoint]; ok {
+		return SectionKeySDECSAgentEndpoint, endpoint
+	}
+	return "", nil
+}
+
+func init() {
+	RegisterRule(SectionKeySDECSAgentEndpoint, new(SDECSAgentEndpoint))
+}
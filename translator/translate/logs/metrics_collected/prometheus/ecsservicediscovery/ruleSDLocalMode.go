@@ -0,0 +1,30 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+const (
+	SectionKeySDLocalMode = "sd_local_mode"
+)
+
+type SDLocalMode struct {
+}
+
+// The local mode is only set when enabled, so the existing configurations are unchanged
+func (d *SDLocalMode) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	if isLocalMode(input) {
+		return SectionKeySDLocalMode, true
+	}
+	return "", nil
+}
+
+// isLocalMode returns true when the tasks are discovered from the ECS agent and Docker engine of the host,
+// which makes the target cluster and its region optional.
+func isLocalMode(input interface{}) bool {
+	localMode, _ := input.(map[string]interface{})[SectionKeySDLocalMode].(bool)
+	return localMode
+}
+
+func init() {
+	RegisterRule(SectionKeySDLocalMode, new(SDLocalMode))
+}
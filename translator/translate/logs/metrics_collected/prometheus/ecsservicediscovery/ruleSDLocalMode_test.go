@@ -0,0 +1,50 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package ecsservicediscovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LocalModeRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  map[string]interface{}
+	}{
+		{name: "local_mode_not_set",
+			input: map[string]interface{}{},
+			want:  map[string]interface{}{}},
+		{name: "local_mode_disabled",
+			input: map[string]interface{}{"sd_local_mode": false},
+			want:  map[string]interface{}{}},
+		{name: "local_mode_default_endpoints",
+			input: map[string]interface{}{"sd_local_mode": true},
+			want:  map[string]interface{}{"sd_local_mode": true}},
+		{name: "local_mode_custom_endpoints",
+			input: map[string]interface{}{
+				"sd_local_mode":         true,
+				"sd_ecs_agent_endpoint": "http://127.0.0.1:51678",
+				"sd_docker_endpoint":    "unix:///run/docker.sock",
+			},
+			want: map[string]interface{}{
+				"sd_local_mode":         true,
+				"sd_ecs_agent_endpoint": "http://127.0.0.1:51678",
+				"sd_docker_endpoint":    "unix:///run/docker.sock",
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]interface{}{}
+			for _, rule := range []Rule{new(SDLocalMode), new(SDECSAgentEndpoint), new(SDDockerEndpoint)} {
+				if key, val := rule.ApplyRule(tt.input); key != "" {
+					got[key] = val
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -17,7 +17,7 @@ type SDTargetCluster struct {
 
 func (d *SDTargetCluster) ApplyRule(input interface{}) (string, interface{}) {
 	clusterName := util.GetECSClusterName(SectionKeySDTargetCluster, input.(map[string]interface{}))
-	if clusterName == "" {
+	if clusterName == "" && !isLocalMode(input) {
 		translator.AddErrorMessages(GetCurPath(), "ECS Target Cluster Name is not defined")
 	}
 	return SectionKeySDTargetCluster, clusterName
@@ -0,0 +1,51 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package prometheus
+
+const (
+	SectionKeyRemoteWriteReceiver = "remote_write_receiver"
+	serviceAddressKey             = "service_address"
+	pathKey                       = "path"
+	maxRequestSizeKey             = "max_request_size"
+	tlsKey                        = "tls"
+)
+
+// RemoteWriteReceiver translates the endpoint accepting the metrics pushed with the Prometheus
+// remote write protocol. The tls section uses the keys of the otlp receivers.
+type RemoteWriteReceiver struct {
+}
+
+func (r *RemoteWriteReceiver) ApplyRule(input interface{}) (string, interface{}) {
+	im := input.(map[string]interface{})
+	rw, ok := im[SectionKeyRemoteWriteReceiver].(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+	result := map[string]interface{}{}
+	for _, key := range []string{serviceAddressKey, pathKey} {
+		if val, ok := rw[key]; ok {
+			result[key] = val
+		}
+	}
+	// the numbers of the JSON config are decoded as floats
+	if maxRequestSize, ok := rw[maxRequestSizeKey].(float64); ok {
+		result[maxRequestSizeKey] = int64(maxRequestSize)
+	}
+	if tls, ok := rw[tlsKey].(map[string]interface{}); ok {
+		if certFile, ok := tls["cert_file"]; ok {
+			result["tls_cert"] = certFile
+		}
+		if keyFile, ok := tls["key_file"]; ok {
+			result["tls_key"] = keyFile
+		}
+		if caFile, ok := tls["ca_file"]; ok {
+			result["tls_allowed_cacerts"] = []interface{}{caFile}
+		}
+	}
+	return SectionKeyRemoteWriteReceiver, result
+}
+
+func init() {
+	RegisterRule(SectionKeyRemoteWriteReceiver, new(RemoteWriteReceiver))
+}
@@ -0,0 +1,130 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/util"
+)
+
+const (
+	DestinationsSectionKey = "destinations"
+	DestinationTypeKey     = "type"
+	Output_Local_Logs      = "locallogs"
+
+	fileDestinationType   = "file"
+	stdoutDestinationType = "stdout"
+	stdoutFilePath        = "stdout"
+)
+
+type Destinations struct {
+}
+
+// ApplyRule records the additional outputs declared in the destinations section, keyed by the
+// name collect_list entries route their files to with "destination". A cloudwatchlogs destination
+// overrides the region, endpoint or credentials of the default cloudwatchlogs output, a file or
+// stdout destination writes the log events locally with the locallogs output.
+func (d *Destinations) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	GlobalLogConfig.Destinations = map[string]map[string]interface{}{}
+	GlobalLogConfig.DestinationOutputs = map[string]string{}
+	im, ok := input.(map[string]interface{})
+	if !ok {
+		return
+	}
+	destinations, ok := im[DestinationsSectionKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for name, destination := range destinations {
+		if name == Output_Cloudwatch_Logs {
+			translator.AddErrorMessages(GetCurPath()+DestinationsSectionKey, fmt.Sprintf("Destination name %s is reserved for the default destination", name))
+			continue
+		}
+		output := Output_Cloudwatch_Logs
+		overrides := map[string]interface{}{}
+		_, destinationType := translator.DefaultCase(DestinationTypeKey, Output_Cloudwatch_Logs, destination)
+		switch destinationType {
+		case Output_Cloudwatch_Logs:
+			for _, key := range []string{"region", "endpoint_override"} {
+				if _, val := translator.DefaultCase(key, "", destination); val != "" {
+					overrides[key] = val
+				}
+			}
+			if creds, ok := destination.(map[string]interface{})[CredentialsSectionKey]; ok {
+				util.SetWithSameKeyIfFound(creds, credsTargetList, overrides)
+			}
+		case fileDestinationType:
+			output = Output_Local_Logs
+			_, filePath := translator.DefaultCase("file_path", "", destination)
+			if filePath == "" {
+				translator.AddErrorMessages(GetCurPath()+DestinationsSectionKey, fmt.Sprintf("Destination %s of type file requires a file_path", name))
+				continue
+			}
+			overrides["file_path"] = filePath
+			for _, key := range []string{"max_size_mb", "max_backups"} {
+				if _, ok := destination.(map[string]interface{})[key]; ok {
+					_, overrides[key] = translator.DefaultIntegralCase(key, float64(0), destination)
+				}
+			}
+		case stdoutDestinationType:
+			output = Output_Local_Logs
+			overrides["file_path"] = stdoutFilePath
+		default:
+			translator.AddErrorMessages(GetCurPath()+DestinationsSectionKey, fmt.Sprintf("Destination %s type %v is invalid", name, destinationType))
+			continue
+		}
+		GlobalLogConfig.Destinations[name] = overrides
+		GlobalLogConfig.DestinationOutputs[name] = output
+	}
+	returnKey = DestinationsSectionKey
+	returnVal = GlobalLogConfig.Destinations
+	return
+}
+
+// destinationOutputs returns the outputs by plugin name, the default cloudwatchlogs output first
+// followed by one output per destination. The cloudwatchlogs destinations are based on the default
+// output with the overrides of the destination applied.
+func destinationOutputs(defaultOutput map[string]interface{}) map[string]interface{} {
+	names := make([]string, 0, len(GlobalLogConfig.Destinations))
+	for name := range GlobalLogConfig.Destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cloudwatchOutputs := []interface{}{defaultOutput}
+	var localOutputs []interface{}
+	for _, name := range names {
+		output := map[string]interface{}{}
+		if GlobalLogConfig.DestinationOutputs[name] == Output_Local_Logs {
+			for k, v := range GlobalLogConfig.Destinations[name] {
+				output[k] = v
+			}
+			output["alias"] = name
+			localOutputs = append(localOutputs, output)
+			continue
+		}
+		for k, v := range defaultOutput {
+			output[k] = v
+		}
+		for k, v := range GlobalLogConfig.Destinations[name] {
+			output[k] = v
+		}
+		output["alias"] = name
+		// Destinations may push to the same log group and stream in another account or region.
+		if dir, ok := output["spool_directory"].(string); ok {
+			output["spool_directory"] = filepath.Join(dir, name)
+		}
+		cloudwatchOutputs = append(cloudwatchOutputs, output)
+	}
+
+	outputs := map[string]interface{}{Output_Cloudwatch_Logs: cloudwatchOutputs}
+	if len(localOutputs) > 0 {
+		outputs[Output_Local_Logs] = localOutputs
+	}
+	return outputs
+}
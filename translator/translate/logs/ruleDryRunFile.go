@@ -0,0 +1,29 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const DryRunFileKey = "dry_run_file"
+
+type DryRunFile struct {
+}
+
+// ApplyRule makes the cloudwatchlogs output write its requests to the file instead of sending them.
+func (r *DryRunFile) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	res := map[string]interface{}{}
+	key, val := translator.DefaultCase(DryRunFileKey, "", input)
+	res[key] = val
+	if val != "" {
+		returnKey = Output_Cloudwatch_Logs
+		returnVal = res
+	}
+	return
+}
+
+func init() {
+	RegisterRule(DryRunFileKey, new(DryRunFile))
+}
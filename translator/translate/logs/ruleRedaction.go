@@ -0,0 +1,28 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	logUtil "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/util"
+)
+
+type Redaction struct {
+}
+
+// ApplyRule enables the redaction of every log event pushed by the cloudwatchlogs output,
+// including structured logs and EMF, when the redaction section is present.
+func (r *Redaction) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	redaction, ok := im[logUtil.RedactionSectionKey]
+	if !ok {
+		return
+	}
+	returnKey = Output_Cloudwatch_Logs
+	returnVal = logUtil.TranslateRedaction(redaction, GetCurPath())
+	return
+}
+
+func init() {
+	RegisterRule(logUtil.RedactionSectionKey, new(Redaction))
+}
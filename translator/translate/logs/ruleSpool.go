@@ -0,0 +1,37 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logs
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	logUtil "github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/util"
+)
+
+const (
+	SpoolSectionKey = "spool"
+
+	defaultSpoolMaxSizeMB = 100
+)
+
+type Spool struct {
+}
+
+// ApplyRule enables the disk spool of the cloudwatchlogs output when the spool section is present.
+func (s *Spool) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	im := input.(map[string]interface{})
+	spool, ok := im[SpoolSectionKey]
+	if !ok {
+		return
+	}
+	res := map[string]interface{}{}
+	_, res["spool_directory"] = translator.DefaultCase("directory", logUtil.GetSpoolFolder(), spool)
+	_, res["spool_max_size_mb"] = translator.DefaultIntegralCase("max_size_mb", float64(defaultSpoolMaxSizeMB), spool)
+	returnKey = Output_Cloudwatch_Logs
+	returnVal = res
+	return
+}
+
+func init() {
+	RegisterRule(SpoolSectionKey, new(Spool))
+}
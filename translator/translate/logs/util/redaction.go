@@ -0,0 +1,57 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+const (
+	RedactionSectionKey = "redaction"
+	RedactionsKey       = "redactions"
+	RedactionHashKey    = "redaction_hash_key"
+)
+
+// TranslateRedaction translates the redaction section into the redactions and redaction_hash_key
+// options shared by the logfile input and the cloudwatchlogs output.
+func TranslateRedaction(redaction interface{}, path string) map[string]interface{} {
+	res := map[string]interface{}{}
+	if rules := TranslateRedactionRules(redaction, path); len(rules) > 0 {
+		res[RedactionsKey] = rules
+	}
+	if hashKey := TranslateRedactionHashKey(redaction); hashKey != "" {
+		res[RedactionHashKey] = hashKey
+	}
+	return res
+}
+
+// TranslateRedactionRules returns the redaction rules, rules without a name are reported under path and skipped.
+func TranslateRedactionRules(redaction interface{}, path string) []interface{} {
+	var rules []interface{}
+	_, val := translator.DefaultCase("rules", []interface{}{}, redaction)
+	ruleArr, _ := val.([]interface{})
+	for _, rule := range ruleArr {
+		ruleMap := map[string]interface{}{}
+		for _, key := range []string{"name", "expression", "action"} {
+			if _, v := translator.DefaultCase(key, "", rule); v != "" {
+				ruleMap[key] = v
+			}
+		}
+		if _, ok := ruleMap["name"]; !ok {
+			translator.AddErrorMessages(path+RedactionSectionKey, fmt.Sprintf("Redaction rule %s is invalid", rule))
+			continue
+		}
+		rules = append(rules, ruleMap)
+	}
+	return rules
+}
+
+// TranslateRedactionHashKey returns the HMAC key of the rules with the hash action.
+func TranslateRedactionHashKey(redaction interface{}) string {
+	_, hashKey := translator.DefaultCase("hash_key", "", redaction)
+	s, _ := hashKey.(string)
+	return s
+}
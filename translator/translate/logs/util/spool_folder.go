@@ -0,0 +1,21 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/config"
+	"github.com/aws/amazon-cloudwatch-agent/translator/util"
+)
+
+const Spool_Folder_Linux = "/opt/aws/amazon-cloudwatch-agent/logs/spool"
+
+func GetSpoolFolder() (spoolFolder string) {
+	if translator.GetTargetPlatform() == config.OS_TYPE_WINDOWS {
+		spoolFolder = util.GetWindowsProgramDataPath() + "\\Amazon\\AmazonCloudWatchAgent\\Logs\\spool"
+	} else {
+		spoolFolder = Spool_Folder_Linux
+	}
+	return
+}
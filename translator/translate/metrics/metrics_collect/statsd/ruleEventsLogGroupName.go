@@ -0,0 +1,33 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+type EventsLogGroupName struct {
+}
+
+const SectionKey_EventsLogGroupName = "events_log_group_name"
+
+func (obj *EventsLogGroupName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	key, val := translator.DefaultCase(SectionKey_EventsLogGroupName, "", input)
+	if val == "" {
+		return
+	}
+	// the events are published by the cloudwatchlogs output of the logs section
+	if !logs.GlobalLogConfig.SectionSet {
+		translator.AddErrorMessages(GetCurPath()+SectionKey_EventsLogGroupName, "events_log_group_name requires the logs section, the events are published by the cloudwatchlogs output")
+		return "", nil
+	}
+	return key, util.ResolvePlaceholder(val.(string), logs.GlobalLogConfig.MetadataInfo)
+}
+
+func init() {
+	obj := new(EventsLogGroupName)
+	RegisterRule(SectionKey_EventsLogGroupName, obj)
+}
@@ -0,0 +1,28 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/util"
+)
+
+type EventsLogStreamName struct {
+}
+
+const SectionKey_EventsLogStreamName = "events_log_stream_name"
+
+func (obj *EventsLogStreamName) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	key, val := translator.DefaultCase(SectionKey_EventsLogStreamName, "", input)
+	if val == "" {
+		return
+	}
+	return key, util.ResolvePlaceholder(val.(string), logs.GlobalLogConfig.MetadataInfo)
+}
+
+func init() {
+	obj := new(EventsLogStreamName)
+	RegisterRule(SectionKey_EventsLogStreamName, obj)
+}
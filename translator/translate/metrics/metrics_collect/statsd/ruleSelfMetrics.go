@@ -0,0 +1,26 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package statsd
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+)
+
+type SelfMetrics struct {
+}
+
+const SectionKey_SelfMetrics = "self_metrics"
+
+func (obj *SelfMetrics) ApplyRule(input interface{}) (returnKey string, returnVal interface{}) {
+	key, val := translator.DefaultCase(SectionKey_SelfMetrics, false, input)
+	if val == true {
+		return key, val
+	}
+	return
+}
+
+func init() {
+	obj := new(SelfMetrics)
+	RegisterRule(SectionKey_SelfMetrics, obj)
+}
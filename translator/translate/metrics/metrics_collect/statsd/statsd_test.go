@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs"
 )
 
 func TestStatsD_HappyCase(t *testing.T) {
@@ -100,3 +103,93 @@ func TestStatsD_MetricSeparator(t *testing.T) {
 
 	assert.Equal(t, expect, actual)
 }
+
+func TestStatsD_TCP(t *testing.T) {
+	obj := new(StatsD)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"statsd": {
+					"service_address": "tcp://:8125",
+					"max_tcp_connections": 50
+					}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	expect := []interface{}{
+		map[string]interface{}{
+			"service_address":     "tcp://:8125",
+			"max_tcp_connections": 50,
+			"interval":            "10s",
+			"parse_data_dog_tags": true,
+			"tags":                map[string]interface{}{"aws:AggregationInterval": "60s"},
+		},
+	}
+
+	assert.Equal(t, expect, actual)
+}
+
+func TestStatsD_Events(t *testing.T) {
+	logs.GlobalLogConfig.SectionSet = true
+	defer func() { logs.GlobalLogConfig.SectionSet = false }()
+	obj := new(StatsD)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"statsd": {
+					"events_log_group_name": "statsd-events",
+					"events_log_stream_name": "web"
+					}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	expect := []interface{}{
+		map[string]interface{}{
+			"service_address":        ":8125",
+			"events_log_group_name":  "statsd-events",
+			"events_log_stream_name": "web",
+			"interval":               "10s",
+			"parse_data_dog_tags":    true,
+			"tags":                   map[string]interface{}{"aws:AggregationInterval": "60s"},
+		},
+	}
+
+	assert.Equal(t, expect, actual)
+}
+
+func TestStatsD_EventsWithoutLogs(t *testing.T) {
+	translator.ResetMessages()
+	defer translator.ResetMessages()
+	obj := new(StatsD)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"statsd": {"events_log_group_name": "statsd-events"}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	assert.NotContains(t, actual.([]interface{})[0], "events_log_group_name")
+	assert.False(t, translator.IsTranslateSuccess(), "events should require the logs section")
+}
+
+func TestStatsD_Throughput(t *testing.T) {
+	obj := new(StatsD)
+	var input interface{}
+	err := json.Unmarshal([]byte(`{"statsd": {
+					"parser_workers": 4,
+					"self_metrics": true
+					}}`), &input)
+	assert.NoError(t, err)
+
+	_, actual := obj.ApplyRule(input)
+
+	expect := []interface{}{
+		map[string]interface{}{
+			"service_address":     ":8125",
+			"parser_workers":      4,
+			"self_metrics":        true,
+			"interval":            "10s",
+			"parse_data_dog_tags": true,
+			"tags":                map[string]interface{}{"aws:AggregationInterval": "60s"},
+		},
+	}
+
+	assert.Equal(t, expect, actual)
+}
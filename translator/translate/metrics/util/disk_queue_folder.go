@@ -0,0 +1,21 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package util
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent/translator"
+	"github.com/aws/amazon-cloudwatch-agent/translator/config"
+	"github.com/aws/amazon-cloudwatch-agent/translator/util"
+)
+
+const Disk_Queue_Folder_Linux = "/opt/aws/amazon-cloudwatch-agent/metrics/queue"
+
+func GetDiskQueueFolder() (diskQueueFolder string) {
+	if translator.GetTargetPlatform() == config.OS_TYPE_WINDOWS {
+		diskQueueFolder = util.GetWindowsProgramDataPath() + "\\Amazon\\AmazonCloudWatchAgent\\Metrics\\queue"
+	} else {
+		diskQueueFolder = Disk_Queue_Folder_Linux
+	}
+	return
+}
@@ -4,12 +4,15 @@
 package awscloudwatch
 
 import (
+	"fmt"
+
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/exporter"
 
 	"github.com/aws/amazon-cloudwatch-agent/plugins/outputs/cloudwatch"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/agent"
+	metricsutil "github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/util"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/extension/agenthealth"
 )
@@ -17,6 +20,16 @@ import (
 const (
 	namespaceKey          = "namespace"
 	forceFlushIntervalKey = "force_flush_interval"
+	namespaceRulesKey     = "namespace_rules"
+	statisticsRulesKey    = "statistics_rules"
+	diskQueueKey          = "disk_queue"
+	directoryKey          = "directory"
+	maxSizeMBKey          = "max_size_mb"
+	backfillKey           = "backfill"
+	maxRequestsPerSecKey  = "max_requests_per_second"
+	bucketIntervalKey     = "bucket_interval"
+	dryRunFileKey         = "dry_run_file"
+	summaryQuantilesKey   = "publish_summary_quantiles"
 	dropOriginalWildcard  = "*"
 
 	internalMaxValuesPerDatum = 5000
@@ -56,6 +69,16 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	if namespace, ok := common.GetString(conf, common.ConfigKey(common.MetricsKey, namespaceKey)); ok {
 		cfg.Namespace = namespace
 	}
+	if namespaceRules, err := getRules[cloudwatch.NamespaceRule](conf, namespaceRulesKey); err != nil {
+		return nil, err
+	} else if len(namespaceRules) > 0 {
+		cfg.NamespaceRules = namespaceRules
+	}
+	if statisticsRules, err := getRules[cloudwatch.StatisticsRule](conf, statisticsRulesKey); err != nil {
+		return nil, err
+	} else if len(statisticsRules) > 0 {
+		cfg.StatisticsRules = statisticsRules
+	}
 	if endpointOverride, ok := common.GetString(conf, common.ConfigKey(common.MetricsKey, common.EndpointOverrideKey)); ok {
 		cfg.EndpointOverride = endpointOverride
 	}
@@ -71,10 +94,49 @@ func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	if dropOriginalMetrics := common.GetDropOriginalMetrics(conf); len(dropOriginalMetrics) != 0 {
 		cfg.DropOriginalConfigs = dropOriginalMetrics
 	}
+	if conf.IsSet(common.ConfigKey(common.MetricsKey, diskQueueKey)) {
+		cfg.DiskQueueDirectory = metricsutil.GetDiskQueueFolder()
+		if directory, ok := common.GetString(conf, common.ConfigKey(common.MetricsKey, diskQueueKey, directoryKey)); ok {
+			cfg.DiskQueueDirectory = directory
+		}
+		if maxSizeMB, ok := common.GetNumber(conf, common.ConfigKey(common.MetricsKey, diskQueueKey, maxSizeMBKey)); ok {
+			cfg.DiskQueueMaxSizeMB = int(maxSizeMB)
+		}
+	}
+	if conf.IsSet(common.ConfigKey(common.MetricsKey, backfillKey)) {
+		cfg.Backfill = true
+		if maxRequestsPerSecond, ok := common.GetNumber(conf, common.ConfigKey(common.MetricsKey, backfillKey, maxRequestsPerSecKey)); ok {
+			cfg.BackfillMaxRequestsPerSecond = maxRequestsPerSecond
+		}
+		if bucketInterval, ok := common.GetDuration(conf, common.ConfigKey(common.MetricsKey, backfillKey, bucketIntervalKey)); ok {
+			cfg.BackfillBucket = bucketInterval
+		}
+	}
+	if dryRunFile, ok := common.GetString(conf, common.ConfigKey(common.MetricsKey, dryRunFileKey)); ok {
+		cfg.DryRunFile = dryRunFile
+	}
+	if publishSummaryQuantiles, ok := common.GetBool(conf, common.ConfigKey(common.MetricsKey, summaryQuantilesKey)); ok {
+		cfg.PublishSummaryQuantiles = publishSummaryQuantiles
+	}
 	cfg.MiddlewareID = &agenthealth.MetricsID
 	return cfg, nil
 }
 
+// getRules decodes the list of rules under the rulesKey of the metrics section.
+func getRules[R any](conf *confmap.Conf, rulesKey string) ([]R, error) {
+	key := common.ConfigKey(common.MetricsKey, rulesKey)
+	if !conf.IsSet(key) {
+		return nil, nil
+	}
+	var rules struct {
+		Rules []R `mapstructure:"rules"`
+	}
+	if err := confmap.NewFromStringMap(map[string]any{"rules": conf.Get(key)}).Unmarshal(&rules); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return rules.Rules, nil
+}
+
 func getRoleARN(conf *confmap.Conf) string {
 	key := common.ConfigKey(common.MetricsKey, common.CredentialsKey, common.RoleARNKey)
 	roleARN, ok := common.GetString(conf, key)
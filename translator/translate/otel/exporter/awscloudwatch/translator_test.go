@@ -62,6 +62,130 @@ func TestTranslator(t *testing.T) {
 				RoleARN:            "global_arn",
 			},
 		},
+		"WithNamespaceRules": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"namespace_rules": []interface{}{
+					map[string]interface{}{"namespace": "TeamA", "metric_name": "team_a_*"},
+					map[string]interface{}{"namespace": "TeamB", "dimensions": map[string]interface{}{"service": "checkout-*"}},
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				NamespaceRules: []cloudwatch.NamespaceRule{
+					{Namespace: "TeamA", MetricName: "team_a_*"},
+					{Namespace: "TeamB", Dimensions: map[string]string{"service": "checkout-*"}},
+				},
+			},
+		},
+		"WithStatisticsRules": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"statistics_rules": []interface{}{
+					map[string]interface{}{"metric_name": "latency_*", "statistics": []interface{}{"p50", "p99", "max"}},
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				StatisticsRules: []cloudwatch.StatisticsRule{
+					{MetricName: "latency_*", Statistics: []string{"p50", "p99", "max"}},
+				},
+			},
+		},
+		"WithDiskQueue": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"disk_queue": map[string]interface{}{
+					"max_size_mb": 512,
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				DiskQueueDirectory: "/opt/aws/amazon-cloudwatch-agent/metrics/queue",
+				DiskQueueMaxSizeMB: 512,
+			},
+		},
+		"WithDiskQueueDirectory": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"disk_queue": map[string]interface{}{
+					"directory": "/tmp/queue",
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				DiskQueueDirectory: "/tmp/queue",
+			},
+		},
+		"WithBackfill": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"backfill": map[string]interface{}{
+					"max_requests_per_second": 5,
+					"bucket_interval":         300,
+				},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:                    "CWAgent",
+				Region:                       "us-east-1",
+				ForceFlushInterval:           time.Minute,
+				MaxValuesPerDatum:            150,
+				RoleARN:                      "global_arn",
+				Backfill:                     true,
+				BackfillMaxRequestsPerSecond: 5,
+				BackfillBucket:               5 * time.Minute,
+			},
+		},
+		"WithBackfillDefaults": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"backfill": map[string]interface{}{},
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				Backfill:           true,
+			},
+		},
+		"WithDryRunFile": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"dry_run_file": "/tmp/capture.jsonl",
+			}},
+			want: &cloudwatch.Config{
+				Namespace:          "CWAgent",
+				Region:             "us-east-1",
+				ForceFlushInterval: time.Minute,
+				MaxValuesPerDatum:  150,
+				RoleARN:            "global_arn",
+				DryRunFile:         "/tmp/capture.jsonl",
+			},
+		},
+		"WithPublishSummaryQuantiles": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{
+				"publish_summary_quantiles": true,
+			}},
+			want: &cloudwatch.Config{
+				Namespace:               "CWAgent",
+				Region:                  "us-east-1",
+				ForceFlushInterval:      time.Minute,
+				MaxValuesPerDatum:       150,
+				RoleARN:                 "global_arn",
+				PublishSummaryQuantiles: true,
+			},
+		},
 		"WithInvalidCredentialFields": {
 			input: map[string]interface{}{"metrics": map[string]interface{}{}},
 			credentials: map[string]interface{}{
@@ -164,6 +288,14 @@ func TestTranslator(t *testing.T) {
 				assert.Equal(t, testCase.want.SharedCredentialFilename, gotCfg.SharedCredentialFilename)
 				assert.Equal(t, testCase.want.MaxValuesPerDatum, gotCfg.MaxValuesPerDatum)
 				assert.Equal(t, testCase.want.RollupDimensions, gotCfg.RollupDimensions)
+				assert.Equal(t, testCase.want.NamespaceRules, gotCfg.NamespaceRules)
+				assert.Equal(t, testCase.want.StatisticsRules, gotCfg.StatisticsRules)
+				assert.Equal(t, testCase.want.DiskQueueDirectory, gotCfg.DiskQueueDirectory)
+				assert.Equal(t, testCase.want.DiskQueueMaxSizeMB, gotCfg.DiskQueueMaxSizeMB)
+				assert.Equal(t, testCase.want.Backfill, gotCfg.Backfill)
+				assert.Equal(t, testCase.want.BackfillMaxRequestsPerSecond, gotCfg.BackfillMaxRequestsPerSecond)
+				assert.Equal(t, testCase.want.BackfillBucket, gotCfg.BackfillBucket)
+				assert.Equal(t, testCase.want.DryRunFile, gotCfg.DryRunFile)
 				assert.NotNil(t, gotCfg.MiddlewareID)
 				assert.Equal(t, "agenthealth/metrics", gotCfg.MiddlewareID.String())
 				if testCase.wantWindows != nil && runtime.GOOS == "windows" {
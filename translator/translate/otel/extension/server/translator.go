@@ -9,11 +9,13 @@ import (
 	"go.opentelemetry.io/collector/extension"
 
 	"github.com/aws/amazon-cloudwatch-agent/extension/server"
+	"github.com/aws/amazon-cloudwatch-agent/translator/context"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
 )
 
 const (
 	defaultListenAddr     = ":4311"
+	localListenAddr       = "127.0.0.1:4311"
 	tlsServerCertFilePath = "/etc/amazon-cloudwatch-observability-agent-server-cert/server.crt"
 	tlsServerKeyFilePath  = "/etc/amazon-cloudwatch-observability-agent-server-cert/server.key"
 	caFilePath            = "/etc/amazon-cloudwatch-observability-agent-client-cert/tls-ca.crt"
@@ -36,9 +38,14 @@ func (t *translator) ID() component.ID {
 	return component.NewIDWithName(t.factory.Type(), t.name)
 }
 
-// Translate creates an extension configuration.
+// Translate creates an extension configuration. The certificates are only mounted in Kubernetes,
+// elsewhere the endpoints are served over plain HTTP on the loopback address.
 func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
 	cfg := t.factory.CreateDefaultConfig().(*server.Config)
+	if context.CurrentContext().KubernetesMode() == "" {
+		cfg.ListenAddress = localListenAddr
+		return cfg, nil
+	}
 	cfg.ListenAddress = defaultListenAddr
 	cfg.TLSCAPath = caFilePath
 	cfg.TLSCertPath = tlsServerCertFilePath
@@ -10,20 +10,30 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 
 	"github.com/aws/amazon-cloudwatch-agent/extension/server"
+	"github.com/aws/amazon-cloudwatch-agent/translator/config"
+	"github.com/aws/amazon-cloudwatch-agent/translator/context"
 )
 
 func TestTranslate(t *testing.T) {
 	testCases := map[string]struct {
-		input map[string]interface{}
-		want  *server.Config
+		input          map[string]interface{}
+		kubernetesMode string
+		want           *server.Config
 	}{
 		"DefaultConfig": {
+			input:          map[string]interface{}{},
+			kubernetesMode: config.ModeEKS,
+			want:           &server.Config{ListenAddress: defaultListenAddr, TLSCAPath: caFilePath, TLSCertPath: tlsServerCertFilePath, TLSKeyPath: tlsServerKeyFilePath},
+		},
+		"WithoutKubernetes": {
 			input: map[string]interface{}{},
-			want:  &server.Config{ListenAddress: defaultListenAddr, TLSCAPath: caFilePath, TLSCertPath: tlsServerCertFilePath, TLSKeyPath: tlsServerKeyFilePath},
+			want:  &server.Config{ListenAddress: localListenAddr},
 		},
 	}
 	for name, testCase := range testCases {
 		t.Run(name, func(t *testing.T) {
+			context.CurrentContext().SetKubernetesMode(testCase.kubernetesMode)
+			t.Cleanup(func() { context.CurrentContext().SetKubernetesMode("") })
 			tt := NewTranslator().(*translator)
 			assert.Equal(t, "server", tt.ID().String())
 			conf := confmap.NewFromStringMap(testCase.input)
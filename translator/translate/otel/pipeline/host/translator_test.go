@@ -180,6 +180,23 @@ func TestTranslator(t *testing.T) {
 				extensions: []string{"agenthealth/metrics", "agenthealth/statuscode"},
 			},
 		},
+		"WithCardinalityLimiter": {
+			input: map[string]interface{}{
+				"metrics": map[string]interface{}{
+					"append_dimensions":   map[string]interface{}{},
+					"cardinality_limiter": map[string]interface{}{},
+				},
+			},
+			pipelineName: common.PipelineNameHostDeltaMetrics,
+			mode:         config.ModeEC2,
+			want: &want{
+				pipelineID: "metrics/hostDeltaMetrics",
+				receivers:  []string{"nop", "other"},
+				processors: []string{"awsentity/resource", "cumulativetodelta/hostDeltaMetrics", "cardinalitylimiter", "ec2tagger"},
+				exporters:  []string{"awscloudwatch"},
+				extensions: []string{"agenthealth/metrics", "agenthealth/statuscode"},
+			},
+		},
 		"WithPRWExporter/Aggregation": {
 			input: map[string]interface{}{
 				"metrics": map[string]interface{}{
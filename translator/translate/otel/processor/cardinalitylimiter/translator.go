@@ -0,0 +1,59 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cardinalitylimiter
+
+import (
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/processor"
+
+	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/cardinalitylimiter"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
+)
+
+const (
+	maxDimensionSetsKey = "max_dimension_sets"
+	resetIntervalKey    = "reset_interval"
+	keepDimensionsKey   = "keep_dimensions"
+)
+
+// ConfigKey is the section of the metrics enabling the cardinality limiter.
+var ConfigKey = common.ConfigKey(common.MetricsKey, "cardinality_limiter")
+
+type translator struct {
+	name    string
+	factory processor.Factory
+}
+
+var _ common.Translator[component.Config] = (*translator)(nil)
+
+func NewTranslator() common.Translator[component.Config] {
+	return NewTranslatorWithName("")
+}
+
+func NewTranslatorWithName(name string) common.Translator[component.Config] {
+	return &translator{name, cardinalitylimiter.NewFactory()}
+}
+
+func (t *translator) ID() component.ID {
+	return component.NewIDWithName(t.factory.Type(), t.name)
+}
+
+// Translate creates a processor config based on the cardinality_limiter section of the metrics.
+func (t *translator) Translate(conf *confmap.Conf) (component.Config, error) {
+	if conf == nil || !conf.IsSet(ConfigKey) {
+		return nil, &common.MissingKeyError{ID: t.ID(), JsonKey: ConfigKey}
+	}
+	cfg := t.factory.CreateDefaultConfig().(*cardinalitylimiter.Config)
+	if maxDimensionSets, ok := common.GetNumber(conf, common.ConfigKey(ConfigKey, maxDimensionSetsKey)); ok {
+		cfg.MaxDimensionSets = int(maxDimensionSets)
+	}
+	if resetInterval, ok := common.GetDuration(conf, common.ConfigKey(ConfigKey, resetIntervalKey)); ok {
+		cfg.ResetInterval = resetInterval
+	}
+	if conf.IsSet(common.ConfigKey(ConfigKey, keepDimensionsKey)) {
+		cfg.KeepDimensions = common.GetArray[string](conf, common.ConfigKey(ConfigKey, keepDimensionsKey))
+	}
+	return cfg, nil
+}
@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cardinalitylimiter
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/aws/amazon-cloudwatch-agent/internal/util/testutil"
+	"github.com/aws/amazon-cloudwatch-agent/plugins/processors/cardinalitylimiter"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/otel/common"
+)
+
+func TestTranslator(t *testing.T) {
+	clt := NewTranslator()
+	require.EqualValues(t, "cardinalitylimiter", clt.ID().String())
+	testCases := map[string]struct {
+		input   map[string]interface{}
+		want    *cardinalitylimiter.Config
+		wantErr error
+	}{
+		"WithMissingKey": {
+			input: map[string]interface{}{"metrics": map[string]interface{}{}},
+			wantErr: &common.MissingKeyError{
+				ID:      clt.ID(),
+				JsonKey: ConfigKey,
+			},
+		},
+		"WithDefault": {
+			input: map[string]interface{}{
+				"metrics": map[string]interface{}{
+					"cardinality_limiter": map[string]interface{}{},
+				},
+			},
+			want: &cardinalitylimiter.Config{
+				MaxDimensionSets: 1000,
+				ResetInterval:    time.Hour,
+				KeepDimensions:   []string{"host"},
+			},
+		},
+		"WithFull": {
+			input: testutil.GetJson(t, filepath.Join("testdata", "config.json")),
+			want: &cardinalitylimiter.Config{
+				MaxDimensionSets: 50,
+				ResetInterval:    30 * time.Minute,
+				KeepDimensions:   []string{"host", "service"},
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			conf := confmap.NewFromStringMap(testCase.input)
+			got, err := clt.Translate(conf)
+			require.Equal(t, testCase.wantErr, err)
+			if testCase.want != nil {
+				assert.Equal(t, testCase.want, got)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent/internal/util/collections"
 	translatorconfig "github.com/aws/amazon-cloudwatch-agent/translator/config"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/logs_collected/files"
+	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/logs_collected/files/collect_list"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/logs/logs_collected/windows_events"
 	"github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/metrics_collect"
 	collectd "github.com/aws/amazon-cloudwatch-agent/translator/translate/metrics/metrics_collect/collectd"
@@ -147,8 +148,8 @@ func fromInputs(conf *confmap.Conf, validInputs map[string]bool, baseKey string)
 	translators := common.NewTranslatorMap[component.Config]()
 	if inputs, ok := conf.Get(baseKey).(map[string]interface{}); ok {
 		for inputName := range inputs {
-			if skipInputSet.Contains(inputName) {
-				// logs agent is separate from otel agent
+			if skipInputSet.Contains(inputName) && !hasLogMetrics(conf, inputName) {
+				// logs agent is separate from otel agent, except for the metrics extracted from the log files
 				continue
 			}
 			if validInputs != nil {
@@ -177,6 +178,22 @@ func fromInputs(conf *confmap.Conf, validInputs map[string]bool, baseKey string)
 	return translators
 }
 
+// hasLogMetrics returns true when a file of the collect list extracts metrics from its log events,
+// which are gathered from the logfile input through the metrics pipeline.
+func hasLogMetrics(conf *confmap.Conf, inputName string) bool {
+	if inputName != files.SectionKey {
+		return false
+	}
+	for _, file := range common.GetArray[any](conf, common.ConfigKey(logKey, files.SectionKey, collect_list.SectionKey)) {
+		if fileMap, ok := file.(map[string]any); ok {
+			if metrics, ok := fileMap[collect_list.LogMetricsSectionKey].([]any); ok && len(metrics) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // fromMultipleInput generates multiple receivers with unique ID depends on the number of inputs.
 // Since there plugins from Telegraf that allows multiple inputs such as procstat, window_perf_counter;
 // therefore, generate a hash of the monitored process (e.g exe: hash(amazon-cloudwatch-agent))
@@ -88,7 +88,8 @@ func Translate(jsonConfig interface{}, os string) (*otelcol.Config, error) {
 	if !ecsutil.GetECSUtilSingleton().IsECS() {
 		pipelines.Translators.Extensions.Set(entitystore.NewTranslator())
 	}
-	if context.CurrentContext().KubernetesMode() != "" {
+	// the server also serves the status of the scrape targets of the prometheus input
+	if context.CurrentContext().KubernetesMode() != "" || conf.IsSet(prometheus.LogsKey) {
 		pipelines.Translators.Extensions.Set(server.NewTranslator())
 	}
 	cfg := &otelcol.Config{
@@ -4,6 +4,7 @@
 package otel
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -228,6 +229,48 @@ func TestTranslator(t *testing.T) {
 	}
 }
 
+func TestTranslatorServerExtension(t *testing.T) {
+	agent.Global_Config.Region = "us-east-1"
+	serverID := component.NewID(component.MustNewType("server"))
+	testCases := map[string]struct {
+		input      map[string]interface{}
+		wantServer bool
+	}{
+		"WithPrometheusInput": {
+			input: map[string]interface{}{
+				"logs": map[string]interface{}{
+					"metrics_collected": map[string]interface{}{
+						"prometheus": map[string]interface{}{
+							"prometheus_config_path": "../../tocwconfig/sampleConfig/prometheus_config.yaml",
+							"log_group_name":         "/aws/prometheus",
+						},
+					},
+				},
+			},
+			wantServer: true,
+		},
+		"WithoutPrometheusInput": {
+			input: map[string]interface{}{
+				"metrics": map[string]interface{}{
+					"metrics_collected": map[string]interface{}{
+						"cpu": map[string]interface{}{},
+					},
+				},
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			translator.SetTargetPlatform("linux")
+			got, err := Translate(testCase.input, "linux")
+			require.NoError(t, err)
+			assert.Equal(t, testCase.wantServer, slices.Contains(got.Service.Extensions, serverID))
+			_, ok := got.Extensions[serverID]
+			assert.Equal(t, testCase.wantServer, ok)
+		})
+	}
+}
+
 type testTranslator struct {
 	id      component.ID
 	version int